@@ -1,10 +1,14 @@
 package main
 
 import (
+	"events"
 	"log"
+	"login-api/internal/config"
 	"login-api/internal/handlers"
 	"login-api/internal/interfaces"
+	"login-api/internal/repositories"
 	"login-api/internal/usecase"
+	"login-api/models"
 	"os"
 
 	"github.com/joho/godotenv"
@@ -44,8 +48,43 @@ func main() {
 	defer conn.Close()
 	defer ch.Close()
 
+	db := config.Connect()
+	if err := db.AutoMigrate(&models.EmailSuppression{}); err != nil {
+		log.Fatalf("Failed to migrate email suppressions: %v", err)
+	}
+	// O índice antigo impediria bloquear de novo um endereço reaproveitado por outra conta.
+	if db.Migrator().HasIndex(&models.EmailSuppression{}, models.LegacySuppressionIndex) {
+		if err := db.Migrator().DropIndex(&models.EmailSuppression{}, models.LegacySuppressionIndex); err != nil {
+			log.Fatalf("Failed to migrate email suppressions: %v", err)
+		}
+	}
+	suppressions := repositories.NewGormSuppressionRepository(db)
+
+	emailService := interfaces.NewSMTPEmailService()
+	useCase := &usecase.SendWelcomeEmailUseCase{EmailService: emailService, Suppressions: suppressions}
+	eventHandler := handlers.NewEventHandler(useCase)
+	erasedHandler := handlers.NewUserErasedHandler(&usecase.SuppressErasedUserUseCase{Suppressions: suppressions})
+	invitedHandler := handlers.NewUserInvitedHandler(&usecase.SendInvitationEmailUseCase{EmailService: emailService, Suppressions: suppressions})
+
+	if err := consume(ch, "welcome_email_queue", events.UserCreatedType, eventHandler.HandleMessage); err != nil {
+		log.Fatalf("Failed to consume user.created: %v", err)
+	}
+	if err := consume(ch, "email_user_erased_queue", events.UserErasedType, erasedHandler.HandleMessage); err != nil {
+		log.Fatalf("Failed to consume user.erased: %v", err)
+	}
+	if err := consume(ch, "invitation_email_queue", events.UserInvitedType, invitedHandler.HandleMessage); err != nil {
+		log.Fatalf("Failed to consume user.invited: %v", err)
+	}
+
+	forever := make(chan bool)
+	log.Printf(" [*] Waiting for messages. To exit press CTRL+C")
+	<-forever
+}
+
+// consume declara a fila, liga à exchange user_events pela routing key e processa as mensagens.
+func consume(ch *amqp091.Channel, queue, routingKey string, handle func(amqp091.Delivery)) error {
 	q, err := ch.QueueDeclare(
-		"welcome_email_queue",
+		queue,
 		true,
 		false,
 		false,
@@ -53,24 +92,20 @@ func main() {
 		nil,
 	)
 	if err != nil {
-		log.Fatalf("Failed to declare queue: %v", err)
+		return err
 	}
 
 	err = ch.QueueBind(
 		q.Name,
-		"user.created",
+		routingKey,
 		"user_events",
 		false,
 		nil,
 	)
 	if err != nil {
-		log.Fatalf("Failed to bind queue: %v", err)
+		return err
 	}
 
-	emailService := interfaces.NewSMTPEmailService()
-	useCase := &usecase.SendWelcomeEmailUseCase{EmailService: emailService}
-	eventHandler := handlers.NewEventHandler(useCase)
-
 	msgs, err := ch.Consume(
 		q.Name,
 		"",
@@ -81,16 +116,13 @@ func main() {
 		nil,
 	)
 	if err != nil {
-		log.Fatalf("Failed to register a consumer: %v", err)
+		return err
 	}
 
-	forever := make(chan bool)
 	go func() {
 		for msg := range msgs {
-			eventHandler.HandleMessage(msg)
+			handle(msg)
 		}
 	}()
-
-	log.Printf(" [*] Waiting for messages. To exit press CTRL+C")
-	<-forever
+	return nil
 }
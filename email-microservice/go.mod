@@ -6,6 +6,7 @@ go 1.22.0
 toolchain go1.22.4
 
 require (
+	events v0.0.0-00010101000000-000000000000
 	github.com/KyleBanks/depth v1.2.1 // indirect
 	github.com/PuerkitoBio/purell v1.2.1 // indirect
 	github.com/PuerkitoBio/urlesc v0.0.0-20170810143723-de5bf2ad4578 // indirect
@@ -82,3 +83,5 @@ require (
 	gorm.io/gorm v1.22.2 // indirect
 	mellium.im/sasl v0.2.1 // indirect
 )
+
+replace events => ../events
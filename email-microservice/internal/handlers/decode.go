@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"events"
+	"log"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// decode lê o envelope CloudEvents (ou a mensagem sem envelope, publicada antes dele)
+// e converte os dados para a versão atual do evento.
+func decode(msg amqp091.Delivery, v interface{}) error {
+	envelope, err := events.Decode(msg.RoutingKey, msg.Body)
+	if err != nil {
+		return err
+	}
+	if envelope.CorrelationID != "" {
+		log.Printf("Received %s %s (correlation %s)", envelope.Type, envelope.ID, envelope.CorrelationID)
+	}
+	return envelope.DataAs(v)
+}
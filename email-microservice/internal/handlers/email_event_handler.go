@@ -1,10 +1,9 @@
 package handlers
 
 import (
-	"encoding/json"
+	"events"
 	"log"
 	"login-api/internal/usecase"
-	"login-api/models"
 
 	"github.com/rabbitmq/amqp091-go"
 )
@@ -18,8 +17,8 @@ func NewEventHandler(useCase *usecase.SendWelcomeEmailUseCase) *EventHandler {
 }
 
 func (h *EventHandler) HandleMessage(msg amqp091.Delivery) {
-	var event models.UserCreatedEvent
-	err := json.Unmarshal(msg.Body, &event)
+	var event events.UserCreated
+	err := decode(msg, &event)
 	if err != nil {
 		log.Printf("Error deserializing message: %v", err)
 		msg.Nack(false, false)
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"events"
+	"log"
+	"login-api/internal/usecase"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+type UserErasedHandler struct {
+	useCase *usecase.SuppressErasedUserUseCase
+}
+
+func NewUserErasedHandler(useCase *usecase.SuppressErasedUserUseCase) *UserErasedHandler {
+	return &UserErasedHandler{useCase: useCase}
+}
+
+func (h *UserErasedHandler) HandleMessage(msg amqp091.Delivery) {
+	var event events.UserErased
+	err := decode(msg, &event)
+	if err != nil {
+		log.Printf("Error deserializing message: %v", err)
+		msg.Nack(false, false)
+		return
+	}
+
+	err = h.useCase.Execute(event)
+	if err != nil {
+		log.Printf("Error processing event: %v", err)
+		msg.Nack(false, true)
+		return
+	}
+
+	msg.Ack(false)
+	log.Printf("Suppressed emails for erased user: %d", event.ID)
+}
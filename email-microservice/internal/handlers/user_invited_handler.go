@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"events"
+	"log"
+	"login-api/internal/usecase"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+type UserInvitedHandler struct {
+	useCase *usecase.SendInvitationEmailUseCase
+}
+
+func NewUserInvitedHandler(useCase *usecase.SendInvitationEmailUseCase) *UserInvitedHandler {
+	return &UserInvitedHandler{useCase: useCase}
+}
+
+func (h *UserInvitedHandler) HandleMessage(msg amqp091.Delivery) {
+	var event events.UserInvited
+	err := decode(msg, &event)
+	if err != nil {
+		log.Printf("Error deserializing message: %v", err)
+		msg.Nack(false, false)
+		return
+	}
+
+	err = h.useCase.Execute(event)
+	if err != nil {
+		log.Printf("Error processing event: %v", err)
+		msg.Nack(false, true)
+		return
+	}
+
+	msg.Ack(false)
+	log.Printf("Sent invitation %d to: %s", event.InvitationID, event.Email)
+}
@@ -1,7 +1,8 @@
 package interfaces
 
 import (
-	"login-api/models"
+	"events"
+	"html"
 	"os"
 
 	"gopkg.in/gomail.v2"
@@ -21,7 +22,7 @@ func NewSMTPEmailService() *SMTPEmailService {
 	return &SMTPEmailService{dialer: dialer}
 }
 
-func (s *SMTPEmailService) SendWelcomeEmail(user models.UserCreatedEvent) error {
+func (s *SMTPEmailService) SendWelcomeEmail(user events.UserCreated) error {
 	m := gomail.NewMessage()
 	m.SetHeader("From", "your-app@example.com")
 	m.SetHeader("To", user.Email)
@@ -35,3 +36,19 @@ func (s *SMTPEmailService) SendWelcomeEmail(user models.UserCreatedEvent) error
     `)
 	return s.dialer.DialAndSend(m)
 }
+
+func (s *SMTPEmailService) SendInvitationEmail(invitation events.UserInvited) error {
+	m := gomail.NewMessage()
+	m.SetHeader("From", "your-app@example.com")
+	m.SetHeader("To", invitation.Email)
+	m.SetHeader("Subject", "You have been invited to Our Platform")
+	m.SetBody("text/html", `
+        <h1>You have been invited!</h1>
+        <p>Dear `+html.EscapeString(invitation.Name)+`,</p>
+        <p>`+html.EscapeString(invitation.InvitedBy)+` invited you to join our platform.</p>
+        <p><a href="`+html.EscapeString(invitation.AcceptURL)+`">Set your password and activate your account</a></p>
+        <p>This link expires on `+invitation.ExpiresAt.Format("2006-01-02 15:04 MST")+`.</p>
+        <p>Best regards,<br>Your App Team</p>
+    `)
+	return s.dialer.DialAndSend(m)
+}
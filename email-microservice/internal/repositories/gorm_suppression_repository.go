@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"login-api/models"
+
+	"gorm.io/gorm"
+)
+
+type GormSuppressionRepository struct {
+	db *gorm.DB
+}
+
+func NewGormSuppressionRepository(db *gorm.DB) *GormSuppressionRepository {
+	return &GormSuppressionRepository{db: db}
+}
+
+func (r *GormSuppressionRepository) Suppress(userID uint64, emailHash, reason string) error {
+	suppression := models.EmailSuppression{UserID: userID, EmailHash: emailHash, Reason: reason}
+	return r.db.Where("user_id = ? AND email_hash = ?", userID, emailHash).FirstOrCreate(&suppression).Error
+}
+
+func (r *GormSuppressionRepository) IsSuppressed(userID uint64, email string) (bool, error) {
+	var count int64
+	result := r.db.Model(&models.EmailSuppression{}).
+		Where("user_id = ? AND email_hash = ?", userID, models.HashEmail(email)).
+		Count(&count)
+	return count > 0, result.Error
+}
@@ -0,0 +1,29 @@
+package usecase
+
+import (
+	"events"
+	"log"
+)
+
+type InvitationEmailService interface {
+	SendInvitationEmail(invitation events.UserInvited) error
+}
+
+type SendInvitationEmailUseCase struct {
+	EmailService InvitationEmailService
+	Suppressions SuppressionList
+}
+
+func (u *SendInvitationEmailUseCase) Execute(event events.UserInvited) error {
+	if u.Suppressions != nil {
+		suppressed, err := u.Suppressions.IsSuppressed(event.UserID, event.Email)
+		if err != nil {
+			return err
+		}
+		if suppressed {
+			log.Printf("Skipping invitation email for suppressed user %d", event.UserID)
+			return nil
+		}
+	}
+	return u.EmailService.SendInvitationEmail(event)
+}
@@ -1,15 +1,29 @@
 package usecase
 
-import "login-api/models"
+import (
+	"events"
+	"log"
+)
 
 type EmailService interface {
-	SendWelcomeEmail(user models.UserCreatedEvent) error
+	SendWelcomeEmail(user events.UserCreated) error
 }
 
 type SendWelcomeEmailUseCase struct {
 	EmailService EmailService
+	Suppressions SuppressionList
 }
 
-func (u *SendWelcomeEmailUseCase) Execute(event models.UserCreatedEvent) error {
+func (u *SendWelcomeEmailUseCase) Execute(event events.UserCreated) error {
+	if u.Suppressions != nil {
+		suppressed, err := u.Suppressions.IsSuppressed(event.ID, event.Email)
+		if err != nil {
+			return err
+		}
+		if suppressed {
+			log.Printf("Skipping welcome email for suppressed user %d", event.ID)
+			return nil
+		}
+	}
 	return u.EmailService.SendWelcomeEmail(event)
 }
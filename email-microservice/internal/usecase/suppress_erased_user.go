@@ -0,0 +1,21 @@
+package usecase
+
+import "events"
+
+// SuppressionList bloqueia o endereço só para a conta informada; um novo cadastro com o
+// mesmo email não é afetado.
+type SuppressionList interface {
+	// Suppress recebe o hash do endereço (models.HashEmail), como vem no user.erased.
+	Suppress(userID uint64, emailHash, reason string) error
+	IsSuppressed(userID uint64, email string) (bool, error)
+}
+
+// SuppressErasedUserUseCase garante que nenhum email (inclusive os que ainda estão na fila)
+// seja enviado para um usuário eliminado. O serviço não guarda outros dados pessoais.
+type SuppressErasedUserUseCase struct {
+	Suppressions SuppressionList
+}
+
+func (u *SuppressErasedUserUseCase) Execute(event events.UserErased) error {
+	return u.Suppressions.Suppress(event.ID, event.EmailHash, "erased")
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"events"
+	"time"
+)
+
+// EmailSuppression bloqueia o envio para um endereço enquanto ele pertencer à conta eliminada
+// (UserID). Uma conta nova com o mesmo endereço tem outro id e volta a receber emails. Só o
+// hash é guardado, para não manter o email de quem pediu a eliminação dos dados.
+type EmailSuppression struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement;type:integer"`
+	UserID    uint64    `json:"user_id" gorm:"uniqueIndex:idx_email_suppressions_account;not null;default:0"`
+	EmailHash string    `json:"email_hash" gorm:"uniqueIndex:idx_email_suppressions_account;not null"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LegacySuppressionIndex é o índice único só por hash, de quando o bloqueio valia para o endereço.
+const LegacySuppressionIndex = "idx_email_suppressions_email_hash"
+
+// HashEmail usa o mesmo hash do email_hash do user.erased.
+func HashEmail(email string) string {
+	return events.HashEmail(email)
+}
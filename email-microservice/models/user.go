@@ -1,7 +0,0 @@
-package models
-
-type UserCreatedEvent struct {
-	ID    uint64 `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
-}
@@ -0,0 +1,106 @@
+// Package events define os eventos publicados na exchange user_events e o envelope
+// no formato estruturado do CloudEvents 1.0 usado para transportá-los.
+package events
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	SpecVersion = "1.0"
+	ContentType = "application/cloudevents+json"
+)
+
+// Envelope é a mensagem publicada no broker. SchemaVersion e CorrelationID são atributos de
+// extensão: a versão do formato de Data e o id compartilhado pelos eventos de uma mesma operação.
+type Envelope struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	SchemaVersion   int             `json:"schemaversion"`
+	CorrelationID   string          `json:"correlationid,omitempty"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// New monta o envelope de `data` na versão atual do tipo informado.
+func New(source, eventType, correlationID string, data interface{}) (*Envelope, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Envelope{
+		SpecVersion:     SpecVersion,
+		ID:              NewID(),
+		Source:          source,
+		Type:            eventType,
+		Time:            time.Now().UTC(),
+		DataContentType: "application/json",
+		SchemaVersion:   SchemaVersion(eventType),
+		CorrelationID:   correlationID,
+		Data:            payload,
+	}, nil
+}
+
+// Decode lê uma mensagem do broker. Mensagens sem envelope, publicadas antes dele existir,
+// são tratadas como a versão 1 do tipo indicado pela routing key.
+func Decode(routingKey string, body []byte) (*Envelope, error) {
+	// O `id` das mensagens antigas é numérico, então só o specversion é lido antes.
+	var probe struct {
+		SpecVersion string `json:"specversion"`
+	}
+	if err := json.Unmarshal(body, &probe); err != nil {
+		return nil, err
+	}
+	if probe.SpecVersion == "" {
+		return &Envelope{
+			Type:          routingKey,
+			SchemaVersion: 1,
+			Data:          json.RawMessage(bytes.TrimSpace(body)),
+		}, nil
+	}
+
+	var envelope Envelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, err
+	}
+	if envelope.SchemaVersion == 0 {
+		envelope.SchemaVersion = 1
+	}
+	return &envelope, nil
+}
+
+// DataAs converte Data para a versão atual do tipo, aplicando os upcasters em sequência,
+// e decodifica em `v`. Versões mais novas que a conhecida são lidas como estão: uma versão
+// nova só acrescenta campos, que são ignorados.
+func (e *Envelope) DataAs(v interface{}) error {
+	data := e.Data
+	for version := e.SchemaVersion; version < SchemaVersion(e.Type); version++ {
+		upcast, ok := upcasters[e.Type][version]
+		if !ok {
+			continue
+		}
+		var err error
+		if data, err = upcast(data); err != nil {
+			return fmt.Errorf("upcasting %s from version %d: %w", e.Type, version, err)
+		}
+	}
+	return json.Unmarshal(data, v)
+}
+
+// NewID gera um UUID v4, usado como id dos eventos e das correlações.
+func NewID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,27 @@
+package events
+
+import "time"
+
+// Tipos dos eventos do role-microservice, publicados na mesma exchange user_events.
+const (
+	RoleDeletedType = "role.deleted"
+)
+
+// O que foi feito com as atribuições do papel excluído.
+const (
+	RoleDeleteUnassigned = "unassigned"
+	RoleDeleteReassign   = "reassign"
+	RoleDeleteForce      = "force"
+)
+
+// RoleDeleted lista os usuários que tinham o papel, direto ou por grupo, para que os
+// consumidores atualizem permissões e invalidem os tokens emitidos com ele.
+type RoleDeleted struct {
+	ID           uint64    `json:"id"`
+	Name         string    `json:"name"`
+	Strategy     string    `json:"strategy"`
+	ReassignedTo *Role     `json:"reassigned_to,omitempty"`
+	UserIDs      []uint64  `json:"user_ids"`
+	DeletedBy    string    `json:"deleted_by"`
+	DeletedAt    time.Time `json:"deleted_at"`
+}
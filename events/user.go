@@ -0,0 +1,154 @@
+package events
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// Tipos dos eventos do user-microservice; o tipo também é a routing key na exchange user_events.
+const (
+	UserCreatedType         = "user.created"
+	UserUpdatedType         = "user.updated"
+	UserDeletedType         = "user.deleted"
+	UserRestoredType        = "user.restored"
+	UserPurgedType          = "user.purged"
+	UserErasedType          = "user.erased"
+	UserStatusChangedType   = "user.status_changed"
+	UserPasswordChangedType = "user.password_changed"
+	UserRoleAddedType       = "user.role_added"
+	UserRoleRemovedType     = "user.role_removed"
+	UserRoleExpiredType     = "user.role_expired"
+	UserRolesChangedType    = "user.roles_changed"
+	UserInvitedType         = "user.invited"
+)
+
+// Origens do cadastro informadas no user.created.
+const (
+	OriginAdmin      = "admin"
+	OriginImport     = "import"
+	OriginInvitation = "invitation"
+	OriginUnknown    = "unknown"
+)
+
+type Role struct {
+	ID   uint64 `json:"id"`
+	Name string `json:"name"`
+}
+
+type UserCreated struct {
+	ID     uint64 `json:"id"`
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+	Origin string `json:"origin"`
+}
+
+// UserSnapshot são os campos editáveis do usuário enviados em before/after, nunca a senha.
+type UserSnapshot struct {
+	Name           string `json:"name"`
+	Email          string `json:"email"`
+	ServiceAccount bool   `json:"service_account"`
+}
+
+type UserUpdated struct {
+	ID      uint64       `json:"id"`
+	Changed []string     `json:"changed"`
+	Before  UserSnapshot `json:"before"`
+	After   UserSnapshot `json:"after"`
+	Version uint64       `json:"version"`
+}
+
+type UserDeleted struct {
+	ID        uint64    `json:"id"`
+	Email     string    `json:"email"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+type UserRestored struct {
+	ID    uint64 `json:"id"`
+	Email string `json:"email"`
+}
+
+// Após o purge o usuário não existe mais, então o evento não carrega dados pessoais.
+type UserPurged struct {
+	ID       uint64    `json:"id"`
+	PurgedAt time.Time `json:"purged_at"`
+}
+
+// UserErased é publicado quando um usuário é eliminado (LGPD/GDPR). O evento continua na outbox
+// depois de entregue, então em vez do email original leva só o HashEmail dele, com o qual os
+// outros serviços localizam os próprios dados.
+type UserErased struct {
+	ID        uint64    `json:"id"`
+	EmailHash string    `json:"email_hash"`
+	ErasedBy  string    `json:"erased_by"`
+	ErasedAt  time.Time `json:"erased_at"`
+}
+
+// HashEmail é o SHA-256, em hexadecimal, do email em minúsculas e sem espaços nas pontas.
+func HashEmail(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return hex.EncodeToString(sum[:])
+}
+
+type UserStatusChanged struct {
+	ID        uint64    `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Reason    string    `json:"reason"`
+	ChangedBy string    `json:"changed_by"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// A senha não é enviada: o evento só avisa que ela mudou (ex.: para notificar o usuário).
+type UserPasswordChanged struct {
+	ID        uint64    `json:"id"`
+	Email     string    `json:"email"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+type UserRoleAdded struct {
+	ID         uint64     `json:"id"`
+	Email      string     `json:"email"`
+	RoleID     uint64     `json:"role_id"`
+	RoleName   string     `json:"role_name"`
+	ValidFrom  *time.Time `json:"valid_from,omitempty"`
+	ValidUntil *time.Time `json:"valid_until,omitempty"`
+	GrantedBy  string     `json:"granted_by"`
+}
+
+type UserRoleRemoved struct {
+	ID       uint64 `json:"id"`
+	Email    string `json:"email"`
+	RoleID   uint64 `json:"role_id"`
+	RoleName string `json:"role_name"`
+}
+
+type UserRoleExpired struct {
+	ID         uint64    `json:"id"`
+	Email      string    `json:"email"`
+	RoleID     uint64    `json:"role_id"`
+	RoleName   string    `json:"role_name"`
+	ValidUntil time.Time `json:"valid_until"`
+}
+
+type UserRolesChanged struct {
+	ID     uint64 `json:"id"`
+	Email  string `json:"email"`
+	Before []Role `json:"before"`
+	After  []Role `json:"after"`
+}
+
+// UserInvited é publicado quando um admin convida (ou reenvia o convite para) um usuário.
+type UserInvited struct {
+	InvitationID uint64    `json:"invitation_id"`
+	UserID       uint64    `json:"user_id"`
+	Name         string    `json:"name"`
+	Email        string    `json:"email"`
+	InvitedBy    string    `json:"invited_by"`
+	AcceptURL    string    `json:"accept_url"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
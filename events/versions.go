@@ -0,0 +1,53 @@
+package events
+
+import "encoding/json"
+
+// schemaVersions guarda a versão atual de cada tipo; tipos ausentes estão na versão 1.
+// Ao mudar o formato de um evento, incremente a versão aqui e registre em upcasters
+// a conversão da versão anterior, para que os consumidores leiam mensagens antigas.
+var schemaVersions = map[string]int{
+	UserCreatedType: 2,
+	UserErasedType:  2,
+}
+
+// upcasters[tipo][v] converte o data da versão v para a v+1.
+var upcasters = map[string]map[int]func(data json.RawMessage) (json.RawMessage, error){
+	UserCreatedType: {
+		1: upcastUserCreatedV1,
+	},
+	UserErasedType: {
+		1: upcastUserErasedV1,
+	},
+}
+
+func SchemaVersion(eventType string) int {
+	if version, ok := schemaVersions[eventType]; ok {
+		return version
+	}
+	return 1
+}
+
+// Na versão 2 o user.created passou a informar a origem do cadastro.
+func upcastUserCreatedV1(data json.RawMessage) (json.RawMessage, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	if _, ok := fields["origin"]; !ok {
+		fields["origin"] = OriginUnknown
+	}
+	return json.Marshal(fields)
+}
+
+// Na versão 2 o user.erased deixou de levar o email, substituído por email_hash.
+func upcastUserErasedV1(data json.RawMessage) (json.RawMessage, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	if email, ok := fields["email"].(string); ok {
+		fields["email_hash"] = HashEmail(email)
+	}
+	delete(fields, "email")
+	return json.Marshal(fields)
+}
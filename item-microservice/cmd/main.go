@@ -16,9 +16,11 @@
 package main
 
 import (
+	"events"
 	"log"
 	config "login-api/internal/config"
 	controllers "login-api/internal/controllers"
+	"login-api/internal/handlers"
 	"login-api/internal/repositories"
 	routers "login-api/internal/routers"
 	"login-api/internal/usecases"
@@ -26,9 +28,11 @@ import (
 	"login-api/models"
 	"os"
 	"reflect"
+	"shared/gatewayauth"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"github.com/rabbitmq/amqp091-go"
 	"gorm.io/gorm"
 
 	_ "login-api/docs"
@@ -62,6 +66,14 @@ func main() {
 	var secretKey string = os.Getenv("SECRET_KEY")
 	jwtAuth, _ := middleware.NewJWTTokenMaker(secretKey)
 	controllers.Initialize(config.Connect(), jwtAuth)
+	controllers.UseTokenChecker(repositories.NewGormTokenRepository(db))
+	if os.Getenv("AUTH_MODE") == "trusted-gateway" {
+		trustedGateway, err := gatewayauth.NewTrustedGatewayFromEnv()
+		if err != nil {
+			log.Fatalf("Invalid trusted gateway configuration: %v", err)
+		}
+		controllers.UseTrustedGateway(trustedGateway)
+	}
 	router := gin.Default()
 
 	// Repository
@@ -70,6 +82,21 @@ func main() {
 	// Use Case
 	itemUseCase := usecases.NewItemUseCase(itemRepo)
 
+	// Eventos do user-microservice (opcional): só consome quando RABBITMQ_URL está definido
+	if os.Getenv("RABBITMQ_URL") != "" {
+		rabbitmqConn, rabbitmqChan, err := config.SetupRabbitMQ()
+		if err != nil {
+			log.Fatalf("Failed to setup RabbitMQ: %v", err)
+		}
+		defer rabbitmqConn.Close()
+		defer rabbitmqChan.Close()
+
+		userEventHandler := handlers.NewUserEventHandler(itemUseCase, os.Getenv("ERASED_USER_ITEMS_OWNER"))
+		if err := consumeUserErased(rabbitmqChan, userEventHandler); err != nil {
+			log.Fatalf("Failed to consume user events: %v", err)
+		}
+	}
+
 	// Controller
 	itemController := controllers.NewItemController(itemUseCase)
 
@@ -79,3 +106,33 @@ func main() {
 	port := os.Getenv("PORT")
 	router.Run(":" + port)
 }
+
+func consumeUserErased(ch *amqp091.Channel, handler *handlers.UserEventHandler) error {
+	q, err := ch.QueueDeclare(
+		"item_user_erased_queue",
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := ch.QueueBind(q.Name, events.UserErasedType, "user_events", false, nil); err != nil {
+		return err
+	}
+
+	msgs, err := ch.Consume(q.Name, "", false, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for msg := range msgs {
+			handler.HandleMessage(msg)
+		}
+	}()
+	return nil
+}
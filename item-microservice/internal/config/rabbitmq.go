@@ -0,0 +1,39 @@
+package config
+
+import (
+	"os"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// SetupRabbitMQ conecta ao broker e declara a exchange user_events.
+func SetupRabbitMQ() (*amqp.Connection, *amqp.Channel, error) {
+	// Adicionar RabbitMq go get github.com/rabbitmq/amqp091-go
+	conn, err := amqp.Dial(os.Getenv("RABBITMQ_URL"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	err = ch.ExchangeDeclare(
+		"user_events",
+		"topic",
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		ch.Close()
+		conn.Close()
+		return nil, nil, err
+	}
+
+	return conn, ch, nil
+}
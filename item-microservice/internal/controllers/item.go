@@ -7,8 +7,13 @@
 package controllers
 
 import (
+	"errors"
+	"login-api/internal/dto"
+	"login-api/internal/repositories"
 	"login-api/internal/usecases"
 	"login-api/models"
+	"shared/etag"
+	"shared/representation"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -27,10 +32,16 @@ func NewItemController(itemUseCase *usecases.ItemUseCase) *ItemController {
 // @Tags items
 // @Accept json
 // @Produce json
-// @Success 200 {object} Response{data=[]domains.Item} "Success"
+// @Param fields query string false "Comma-separated fields to return, e.g. id,descricao,valor"
+// @Success 200 {object} Response{data=[]dto.Item} "Success"
 // @Failure 500 {object} ErrorResponse{error=string} "Internal Server Error"
 // @Router /items [get]
 func (ctrl *ItemController) GetItems(c *gin.Context) {
+	options, ok := itemOptions(c)
+	if !ok {
+		return
+	}
+
 	items, err := ctrl.itemUseCase.GetAll()
 	if err != nil {
 		c.JSON(500, ErrorResponse{
@@ -40,7 +51,7 @@ func (ctrl *ItemController) GetItems(c *gin.Context) {
 	}
 
 	c.JSON(200, Response{
-		Data: items,
+		Data: options.Sparse(dto.NewItems(items)),
 	})
 }
 
@@ -50,11 +61,19 @@ func (ctrl *ItemController) GetItems(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Item ID"
-// @Success 200 {object} Response{data=domains.Item} "Success"
+// @Param fields query string false "Comma-separated fields to return, e.g. id,descricao,valor"
+// @Param If-None-Match header string false "ETag from a previous response"
+// @Success 200 {object} Response{data=dto.Item} "Success"
+// @Success 304 "Not Modified"
 // @Failure 404 {object} ErrorResponse{error=string} "Item not found"
 // @Router /items/{id} [get]
 func (ctrl *ItemController) GetItem(c *gin.Context) {
 	id := c.Param("id")
+	options, ok := itemOptions(c)
+	if !ok {
+		return
+	}
+
 	item, err := ctrl.itemUseCase.GetByID(id)
 	if err != nil {
 		c.JSON(404, ErrorResponse{
@@ -62,9 +81,12 @@ func (ctrl *ItemController) GetItem(c *gin.Context) {
 		})
 		return
 	}
+	if etag.NotModified(c, item.Version, options.Key()) {
+		return
+	}
 
 	c.JSON(200, Response{
-		Data: item,
+		Data: options.Sparse(dto.NewItem(item)),
 	})
 }
 
@@ -74,7 +96,7 @@ func (ctrl *ItemController) GetItem(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param item body domains.Item true "Item information"
-// @Success 201 {object} Response{data=domains.Item} "Item created successfully"
+// @Success 201 {object} Response{data=dto.Item} "Item created successfully"
 // @Failure 400 {object} ErrorResponse{errors=map[string]string} "Validation error"
 // @Failure 500 {object} ErrorResponse{error=string} "Failed to create item"
 // @Router /items [post]
@@ -103,6 +125,7 @@ func (ctrl *ItemController) CreateItem(c *gin.Context) {
 		return
 	}
 
+	item.CriadoPor = c.GetString("Email")
 	err := ctrl.itemUseCase.Create(&item)
 	if err != nil {
 		if err.Error() == "valor deve ser maior que zero" {
@@ -118,8 +141,9 @@ func (ctrl *ItemController) CreateItem(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", etag.Tag(item.Version))
 	c.JSON(201, Response{
-		Data: item,
+		Data: dto.NewItem(&item),
 	})
 }
 
@@ -129,14 +153,22 @@ func (ctrl *ItemController) CreateItem(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Item ID"
+// @Param If-Match header string true "ETag of the version being replaced"
 // @Param item body domains.Item true "Updated item information"
-// @Success 200 {object} Response{data=domains.Item} "Item updated successfully"
+// @Success 200 {object} Response{data=dto.Item} "Item updated successfully"
 // @Failure 400 {object} ErrorResponse{error=string} "Invalid data provided"
 // @Failure 404 {object} ErrorResponse{error=string} "Item not found"
+// @Failure 412 {object} ErrorResponse{error=string} "Version mismatch"
+// @Failure 428 {object} ErrorResponse{error=string} "If-Match required"
 // @Failure 500 {object} ErrorResponse{error=string} "Failed to update item"
 // @Router /items/{id} [put]
 func (ctrl *ItemController) UpdateItem(c *gin.Context) {
 	id := c.Param("id")
+	version, ok := etag.IfMatch(c)
+	if !ok {
+		return
+	}
+
 	var item models.Item
 
 	if err := c.ShouldBindJSON(&item); err != nil {
@@ -146,7 +178,11 @@ func (ctrl *ItemController) UpdateItem(c *gin.Context) {
 		return
 	}
 
-	err := ctrl.itemUseCase.Update(id, &item)
+	err := ctrl.itemUseCase.Update(id, &item, version)
+	if errors.Is(err, repositories.ErrVersionConflict) {
+		etag.PreconditionFailed(c)
+		return
+	}
 	if err != nil {
 		switch err.Error() {
 		case "item not found":
@@ -165,8 +201,9 @@ func (ctrl *ItemController) UpdateItem(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", etag.Tag(item.Version))
 	c.JSON(200, Response{
-		Data: item,
+		Data: dto.NewItem(&item),
 	})
 }
 
@@ -176,14 +213,25 @@ func (ctrl *ItemController) UpdateItem(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Item ID"
+// @Param If-Match header string true "ETag of the version being deleted"
 // @Success 200 {object} Response{message=string} "Item deleted successfully"
 // @Failure 404 {object} ErrorResponse{error=string} "Item not found"
+// @Failure 412 {object} ErrorResponse{error=string} "Version mismatch"
+// @Failure 428 {object} ErrorResponse{error=string} "If-Match required"
 // @Failure 500 {object} ErrorResponse{error=string} "Failed to delete item"
 // @Router /items/{id} [delete]
 func (ctrl *ItemController) DeleteItem(c *gin.Context) {
 	id := c.Param("id")
+	version, ok := etag.IfMatch(c)
+	if !ok {
+		return
+	}
 
-	err := ctrl.itemUseCase.Delete(id)
+	err := ctrl.itemUseCase.Delete(id, version)
+	if errors.Is(err, repositories.ErrVersionConflict) {
+		etag.PreconditionFailed(c)
+		return
+	}
 	if err != nil {
 		if err.Error() == "item not found" {
 			c.JSON(404, ErrorResponse{
@@ -202,3 +250,15 @@ func (ctrl *ItemController) DeleteItem(c *gin.Context) {
 		Message: "Item deletado com sucesso",
 	})
 }
+
+// itemOptions lê ?fields= e responde 400 quando pedem um campo que o item não tem.
+func itemOptions(c *gin.Context) (representation.Options, bool) {
+	options, errorMessages := representation.ParseOptions(c.Query("fields"), "", dto.ItemFields, nil)
+	if len(errorMessages) > 0 {
+		c.JSON(400, ErrorResponse{
+			Errors: errorMessages,
+		})
+		return options, false
+	}
+	return options, true
+}
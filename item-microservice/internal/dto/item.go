@@ -0,0 +1,41 @@
+// Package dto define as representações devolvidas pela API, separadas dos modelos do
+// GORM para que campos internos, como a senha, nunca sejam serializados.
+package dto
+
+import (
+	"login-api/models"
+	"time"
+)
+
+// ItemFields são os campos aceitos em ?fields=.
+var ItemFields = []string{"id", "descricao", "valor", "criado_por", "criado_em", "atualizado_em", "version"}
+
+type Item struct {
+	ID           uint64    `json:"id"`
+	Descricao    string    `json:"descricao"`
+	Valor        float64   `json:"valor"`
+	CriadoPor    string    `json:"criado_por"`
+	CriadoEm     time.Time `json:"criado_em"`
+	AtualizadoEm time.Time `json:"atualizado_em"`
+	Version      uint64    `json:"version"`
+}
+
+func NewItem(item *models.Item) Item {
+	return Item{
+		ID:           item.ID,
+		Descricao:    item.Descricao,
+		Valor:        item.Valor,
+		CriadoPor:    item.CriadoPor,
+		CriadoEm:     item.CriadoEm,
+		AtualizadoEm: item.AtualizadoEm,
+		Version:      item.Version,
+	}
+}
+
+func NewItems(items []models.Item) []Item {
+	response := make([]Item, len(items))
+	for i := range items {
+		response[i] = NewItem(&items[i])
+	}
+	return response
+}
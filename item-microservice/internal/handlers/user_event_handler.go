@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"events"
+	"log"
+	"login-api/internal/usecases"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// UserEventHandler reage aos eventos do user-microservice. No user.erased os itens do
+// usuário passam para `erasedItemsOwner` (ou ficam sem autor, se vazio).
+type UserEventHandler struct {
+	itemUseCase      *usecases.ItemUseCase
+	erasedItemsOwner string
+}
+
+func NewUserEventHandler(itemUseCase *usecases.ItemUseCase, erasedItemsOwner string) *UserEventHandler {
+	return &UserEventHandler{itemUseCase: itemUseCase, erasedItemsOwner: erasedItemsOwner}
+}
+
+func (h *UserEventHandler) HandleMessage(msg amqp091.Delivery) {
+	var event events.UserErased
+	if err := decode(msg, &event); err != nil {
+		log.Printf("Error deserializing message: %v", err)
+		msg.Nack(false, false)
+		return
+	}
+
+	reassigned, err := h.itemUseCase.ReassignOwner(event.EmailHash, h.erasedItemsOwner)
+	if err != nil {
+		log.Printf("Error reassigning items of erased user %d: %v", event.ID, err)
+		msg.Nack(false, true)
+		return
+	}
+
+	msg.Ack(false)
+	log.Printf("Reassigned %d items of erased user %d", reassigned, event.ID)
+}
@@ -1,17 +1,22 @@
 package repositories
 
 import (
+	"errors"
 	"login-api/models"
 
 	"gorm.io/gorm"
 )
 
+// ErrVersionConflict indica que o registro mudou desde a versão lida pelo cliente.
+var ErrVersionConflict = errors.New("version conflict")
+
 type ItemRepository interface {
 	FindAll() ([]models.Item, error)
 	FindByID(id string) (*models.Item, error)
 	Create(item *models.Item) error
 	Update(item *models.Item) error
-	Delete(id string) error
+	Delete(id string, version uint64) error
+	ReassignOwner(fromEmailHash, to string) (int64, error)
 }
 
 type GormItemRepository struct {
@@ -41,10 +46,36 @@ func (r *GormItemRepository) Create(item *models.Item) error {
 	return r.db.Create(item).Error
 }
 
+// Update grava o registro se ele ainda estiver em item.Version e incrementa a versão;
+// caso contrário devolve ErrVersionConflict.
 func (r *GormItemRepository) Update(item *models.Item) error {
-	return r.db.Save(item).Error
+	version := item.Version
+	item.Version++
+	result := r.db.Model(item).Where("version = ?", version).Select("*").Updates(item)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = ErrVersionConflict
+	}
+	if result.Error != nil {
+		item.Version = version
+	}
+	return result.Error
+}
+
+func (r *GormItemRepository) Delete(id string, version uint64) error {
+	result := r.db.Where("version = ?", version).Delete(&models.Item{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	return nil
 }
 
-func (r *GormItemRepository) Delete(id string) error {
-	return r.db.Delete(&models.Item{}, "id = ?", id).Error
+// ReassignOwner localiza o autor pelo hash do email (events.HashEmail), calculado no próprio banco.
+func (r *GormItemRepository) ReassignOwner(fromEmailHash, to string) (int64, error) {
+	result := r.db.Model(&models.Item{}).
+		Where("encode(sha256(convert_to(lower(trim(criado_por)), 'UTF8')), 'hex') = ?", fromEmailHash).
+		Update("criado_por", to)
+	return result.RowsAffected, result.Error
 }
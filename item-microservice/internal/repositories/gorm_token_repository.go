@@ -0,0 +1,23 @@
+package repositories
+
+import "gorm.io/gorm"
+
+// TokenRepository consulta a lista de revogação do user-microservice (tabela revoked_tokens,
+// no mesmo banco), alimentada pelo logout.
+type TokenRepository interface {
+	IsRevoked(tokenID string) (bool, error)
+}
+
+type GormTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewGormTokenRepository(db *gorm.DB) *GormTokenRepository {
+	return &GormTokenRepository{db: db}
+}
+
+func (r *GormTokenRepository) IsRevoked(tokenID string) (bool, error) {
+	var count int64
+	result := r.db.Table("revoked_tokens").Where("token_id = ?", tokenID).Count(&count)
+	return count > 0, result.Error
+}
@@ -20,7 +20,7 @@ func Routers(router *gin.Engine, itemController *controllers.ItemController) {
 		AllowOrigins:     []string{"*"},
 		AllowHeaders:     []string{"*"},
 		AllowMethods:     []string{"*"},
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    []string{"Content-Length", "ETag"},
 		AllowCredentials: true,
 	}))
 
@@ -34,23 +34,35 @@ func (uc *ItemUseCase) Create(item *models.Item) error {
 	return uc.repo.Create(item)
 }
 
-func (uc *ItemUseCase) Update(id string, item *models.Item) error {
+// Update substitui o item se ele ainda estiver em `version`.
+func (uc *ItemUseCase) Update(id string, item *models.Item, version uint64) error {
 	if item.Valor <= 0 {
 		return errors.New("valor deve ser maior que zero")
 	}
 
 	existingItem, err := uc.repo.FindByID(id)
 	if err != nil {
-		return err
+		return errors.New("item not found")
 	}
 
 	item.ID = existingItem.ID
+	item.Version = version
 	item.CriadoEm = existingItem.CriadoEm
+	item.CriadoPor = existingItem.CriadoPor
 	item.AtualizadoEm = time.Now()
 
 	return uc.repo.Update(item)
 }
 
-func (uc *ItemUseCase) Delete(id string) error {
-	return uc.repo.Delete(id)
+func (uc *ItemUseCase) Delete(id string, version uint64) error {
+	if _, err := uc.repo.FindByID(id); err != nil {
+		return errors.New("item not found")
+	}
+	return uc.repo.Delete(id, version)
+}
+
+// ReassignOwner transfere os itens criados pelo usuário cujo email tem o hash `fromEmailHash`;
+// com `to` vazio o autor é apenas apagado.
+func (uc *ItemUseCase) ReassignOwner(fromEmailHash, to string) (int64, error) {
+	return uc.repo.ReassignOwner(fromEmailHash, to)
 }
@@ -10,8 +10,10 @@ type Item struct {
 	ID           uint64    `gorm:"primaryKey;autoIncrement;type:integer"`
 	Descricao    string    `json:"descricao" gorm:"type:varchar(255);not null"`
 	Valor        float64   `json:"valor" gorm:"type:decimal(10,2);not null"`
+	CriadoPor    string    `json:"criado_por" gorm:"index"`
 	CriadoEm     time.Time `json:"criado_em" gorm:"default:CURRENT_TIMESTAMP"`
 	AtualizadoEm time.Time `json:"atualizado_em" gorm:"default:CURRENT_TIMESTAMP;autoUpdateTime"`
+	Version      uint64    `json:"version" gorm:"not null;default:1"`
 }
 
 func (Item) TableName() string {
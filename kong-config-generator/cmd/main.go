@@ -0,0 +1,107 @@
+// Gera o kong.yaml declarativo a partir das tabelas de rotas do Gin dos microserviços.
+//
+//	go run ./cmd                                  escreve ../docker-kong/compose/config/kong.yaml
+//	go run ./cmd -out -                           imprime no stdout
+//	go run ./cmd -diff http://localhost:8001      compara com um gateway rodando
+//
+// O arquivo gerado pode ser aplicado com `deck sync -s kong.yaml` ou carregado pelo Kong em modo db-less.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"kong-config-generator/internal/generator"
+
+	"gopkg.in/yaml.v3"
+)
+
+var services = []struct {
+	name string
+	dir  string
+	port int
+}{
+	{name: "usermanager", dir: "user-microservice", port: 8081},
+	{name: "rolemanager", dir: "role-microservice", port: 8082},
+	{name: "itemmanager", dir: "item-microservice", port: 8083},
+}
+
+func main() {
+	root := flag.String("root", "..", "repository root containing the microservices")
+	out := flag.String("out", "../docker-kong/compose/config/kong.yaml", "output file, - for stdout")
+	upstreamHost := flag.String("upstream-host", "host.docker.internal", "host where the microservices are reachable from Kong")
+	rateLimit := flag.Int("rate-limit", 600, "requests per minute per consumer (or IP for anonymous requests)")
+	corsOrigins := flag.String("cors-origins", "*", "comma separated list of allowed CORS origins")
+	aclGroups := flag.String("acl-groups", "", "comma separated roles allowed on private routes; enables the acl plugin (needed by AUTH_MODE=trusted-gateway)")
+	diffAdminURL := flag.String("diff", "", "Kong Admin API URL to diff the generated config against, instead of writing it")
+	flag.Parse()
+
+	var specs []generator.ServiceSpec
+	for _, service := range services {
+		routes, err := generator.LoadRoutes(filepath.Join(*root, service.dir))
+		if err != nil {
+			log.Fatalf("Failed to load routes of %s: %v", service.dir, err)
+		}
+		specs = append(specs, generator.ServiceSpec{
+			Name:    service.name,
+			Targets: []string{fmt.Sprintf("%s:%d", *upstreamHost, service.port)},
+			Routes:  routes,
+		})
+	}
+
+	config, err := generator.Build(specs, generator.Options{
+		RateLimitPerMinute: *rateLimit,
+		CorsOrigins:        strings.Split(*corsOrigins, ","),
+		ACLGroups:          splitList(*aclGroups),
+	})
+	if err != nil {
+		log.Fatalf("Failed to build Kong config: %v", err)
+	}
+
+	if *diffAdminURL != "" {
+		changes, err := generator.NewAdminAPI(*diffAdminURL).Diff(config)
+		if err != nil {
+			log.Fatalf("Failed to diff against %s: %v", *diffAdminURL, err)
+		}
+		for _, change := range changes {
+			fmt.Println(change)
+		}
+		if len(changes) > 0 {
+			os.Exit(1)
+		}
+		fmt.Println("No differences")
+		return
+	}
+
+	var content bytes.Buffer
+	content.WriteString("# Gerado por kong-config-generator, não editar manualmente.\n")
+	encoder := yaml.NewEncoder(&content)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(config); err != nil {
+		log.Fatalf("Failed to encode Kong config: %v", err)
+	}
+
+	if *out == "-" {
+		os.Stdout.Write(content.Bytes())
+		return
+	}
+	if err := os.WriteFile(*out, content.Bytes(), 0644); err != nil {
+		log.Fatalf("Failed to write %s: %v", *out, err)
+	}
+	log.Printf("Kong config written to %s", *out)
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
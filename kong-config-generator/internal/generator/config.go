@@ -0,0 +1,248 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Config segue o formato declarativo do Kong 2.x, que também é aceito pelo decK.
+type Config struct {
+	FormatVersion string     `yaml:"_format_version"`
+	Services      []Service  `yaml:"services"`
+	Upstreams     []Upstream `yaml:"upstreams"`
+	Plugins       []Plugin   `yaml:"plugins,omitempty"`
+}
+
+type Service struct {
+	Name     string  `yaml:"name"`
+	Protocol string  `yaml:"protocol"`
+	Host     string  `yaml:"host"`
+	Path     string  `yaml:"path,omitempty"`
+	Routes   []Route `yaml:"routes"`
+}
+
+type Route struct {
+	Name          string   `yaml:"name"`
+	Methods       []string `yaml:"methods,omitempty"`
+	Paths         []string `yaml:"paths"`
+	StripPath     bool     `yaml:"strip_path"`
+	RegexPriority int      `yaml:"regex_priority,omitempty"`
+	Plugins       []Plugin `yaml:"plugins,omitempty"`
+}
+
+type Plugin struct {
+	Name   string                 `yaml:"name"`
+	Config map[string]interface{} `yaml:"config,omitempty"`
+}
+
+type Upstream struct {
+	Name    string   `yaml:"name"`
+	Targets []Target `yaml:"targets"`
+}
+
+type Target struct {
+	Target string `yaml:"target"`
+	Weight int    `yaml:"weight"`
+}
+
+// ServiceSpec descreve um microserviço a ser exposto pelo gateway.
+type ServiceSpec struct {
+	Name    string
+	Targets []string
+	Routes  []RouteInfo
+}
+
+type Options struct {
+	RateLimitPerMinute int
+	CorsOrigins        []string
+	// ACLGroups, quando definido, adiciona o plugin acl às rotas privadas. Ele repassa
+	// os grupos do consumer (os papéis do usuário) em X-Consumer-Groups, lido pelos
+	// serviços no modo trusted-gateway.
+	ACLGroups []string
+}
+
+// Build monta a configuração declarativa.
+//
+// As rotas da API são expostas no gateway com o mesmo caminho que têm no serviço
+// (regex ancorada, sem strip_path), pois os caminhos não se repetem entre os serviços.
+// Rotas compartilhadas por todos os serviços ("/" e catch-alls como /swagger/*any)
+// ficam sob o prefixo do serviço, ex.: /usermanager/swagger/index.html.
+// Rotas privadas recebem o plugin jwt; cors e rate-limiting são globais.
+func Build(specs []ServiceSpec, options Options) (*Config, error) {
+	config := &Config{FormatVersion: "1.1"}
+	owners := make(map[string]string)
+
+	for _, spec := range specs {
+		upstream := Upstream{Name: spec.Name + ".upstream"}
+		for _, target := range spec.Targets {
+			upstream.Targets = append(upstream.Targets, Target{Target: target, Weight: 100})
+		}
+		config.Upstreams = append(config.Upstreams, upstream)
+
+		service := Service{Name: spec.Name, Protocol: "http", Host: upstream.Name}
+		grouped := make(map[string]*Route)
+		var order []string
+
+		for _, info := range spec.Routes {
+			if info.Path == "/" {
+				service.Routes = append(service.Routes, Route{
+					Name:      spec.Name + "-root",
+					Methods:   []string{info.Method},
+					Paths:     []string{"/" + spec.Name + "$"},
+					StripPath: true,
+				})
+				continue
+			}
+
+			if prefix, ok := catchAllPrefix(info.Path); ok {
+				// Um serviço à parte, com path igual ao prefixo, preserva o caminho no upstream.
+				config.Services = append(config.Services, Service{
+					Name:     spec.Name + "-" + slug(prefix),
+					Protocol: "http",
+					Host:     upstream.Name,
+					Path:     prefix,
+					Routes: []Route{{
+						Name:      spec.Name + "-" + slug(prefix),
+						Methods:   []string{info.Method},
+						Paths:     []string{"/" + spec.Name + prefix},
+						StripPath: true,
+					}},
+				})
+				continue
+			}
+
+			key := fmt.Sprintf("%s %v", info.Path, info.Public)
+			owner := info.Method + " " + info.Path
+			if other, taken := owners[owner]; taken && other != spec.Name {
+				return nil, fmt.Errorf("route %s is declared by both %s and %s", owner, other, spec.Name)
+			}
+			owners[owner] = spec.Name
+
+			route, exists := grouped[key]
+			if !exists {
+				route = &Route{
+					Name:          spec.Name + "-" + slug(info.Path),
+					Paths:         []string{pathRegex(info.Path)},
+					StripPath:     false,
+					RegexPriority: staticSegments(info.Path),
+				}
+				if !info.Public {
+					route.Plugins = []Plugin{jwtPlugin()}
+					if len(options.ACLGroups) > 0 {
+						route.Plugins = append(route.Plugins, aclPlugin(options.ACLGroups))
+					}
+				} else {
+					route.Name += "-public"
+				}
+				grouped[key] = route
+				order = append(order, key)
+			}
+			route.Methods = append(route.Methods, info.Method)
+		}
+
+		sort.Strings(order)
+		for _, key := range order {
+			route := grouped[key]
+			sort.Strings(route.Methods)
+			service.Routes = append(service.Routes, *route)
+		}
+		config.Services = append(config.Services, service)
+	}
+
+	sort.Slice(config.Services, func(i, j int) bool { return config.Services[i].Name < config.Services[j].Name })
+
+	config.Plugins = []Plugin{
+		{
+			Name: "cors",
+			Config: map[string]interface{}{
+				"origins":         options.CorsOrigins,
+				"methods":         []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+				"headers":         []string{"Authorization", "Content-Type", "If-Match", "If-None-Match"},
+				"exposed_headers": []string{"Content-Length", "ETag"},
+				"credentials":     true,
+				"max_age":         3600,
+			},
+		},
+		{
+			Name: "rate-limiting",
+			Config: map[string]interface{}{
+				"minute":   options.RateLimitPerMinute,
+				"policy":   "local",
+				"limit_by": "consumer",
+			},
+		},
+	}
+
+	return config, nil
+}
+
+// O token emitido pelo user-microservice não tem "iss"; a credencial JWT de cada
+// consumer usa o email como key, então o plugin lê a claim "username". Sem
+// claims_to_verify o Kong aceitaria o token depois de expirado.
+func jwtPlugin() Plugin {
+	return Plugin{
+		Name: "jwt",
+		Config: map[string]interface{}{
+			"key_claim_name":   "username",
+			"claims_to_verify": []string{"exp"},
+			"header_names":     []string{"authorization"},
+			"run_on_preflight": false,
+		},
+	}
+}
+
+func aclPlugin(groups []string) Plugin {
+	return Plugin{
+		Name: "acl",
+		Config: map[string]interface{}{
+			"allow":              groups,
+			"hide_groups_header": false,
+		},
+	}
+}
+
+func catchAllPrefix(path string) (string, bool) {
+	index := strings.Index(path, "/*")
+	if index < 0 {
+		return "", false
+	}
+	return path[:index], true
+}
+
+var paramPattern = regexp.MustCompile(`^:([A-Za-z0-9_]+)$`)
+
+func pathRegex(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		if match := paramPattern.FindStringSubmatch(segment); match != nil {
+			segments[i] = "(?<" + match[1] + ">[^/]+)"
+		} else {
+			segments[i] = regexp.QuoteMeta(segment)
+		}
+	}
+	return "/" + strings.Join(segments, "/") + "$"
+}
+
+// Rotas com mais segmentos fixos têm prioridade, ex.: /user/export antes de /user/:id.
+func staticSegments(path string) int {
+	count := 0
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		if !strings.HasPrefix(segment, ":") {
+			count++
+		}
+	}
+	return count
+}
+
+func slug(path string) string {
+	var parts []string
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		segment = strings.TrimLeft(segment, ":*")
+		if segment != "" {
+			parts = append(parts, strings.ToLower(segment))
+		}
+	}
+	return strings.Join(parts, "-")
+}
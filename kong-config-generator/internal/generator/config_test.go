@@ -0,0 +1,77 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuildRoutes(t *testing.T) {
+	options := Options{RateLimitPerMinute: 60, CorsOrigins: []string{"*"}, ACLGroups: []string{"Admin"}}
+	private := []Plugin{jwtPlugin(), aclPlugin(options.ACLGroups)}
+
+	for _, tc := range []struct {
+		name   string
+		routes []RouteInfo
+		want   []Route
+	}{
+		{
+			name:   "public",
+			routes: []RouteInfo{{Method: "POST", Path: "/login", Public: true}},
+			want: []Route{{
+				Name:          "usermanager-login-public",
+				Methods:       []string{"POST"},
+				Paths:         []string{"/login$"},
+				RegexPriority: 1,
+			}},
+		},
+		{
+			name: "regex",
+			routes: []RouteInfo{
+				{Method: "GET", Path: "/user/:id"},
+				{Method: "GET", Path: "/user/export"},
+			},
+			want: []Route{
+				{
+					Name:          "usermanager-user-id",
+					Methods:       []string{"GET"},
+					Paths:         []string{"/user/(?<id>[^/]+)$"},
+					RegexPriority: 1,
+					Plugins:       private,
+				},
+				{
+					Name:          "usermanager-user-export",
+					Methods:       []string{"GET"},
+					Paths:         []string{"/user/export$"},
+					RegexPriority: 2,
+					Plugins:       private,
+				},
+			},
+		},
+		{
+			name: "multi-method",
+			routes: []RouteInfo{
+				{Method: "PUT", Path: "/user/:id"},
+				{Method: "GET", Path: "/user/:id"},
+				{Method: "DELETE", Path: "/user/:id"},
+			},
+			want: []Route{{
+				Name:          "usermanager-user-id",
+				Methods:       []string{"DELETE", "GET", "PUT"},
+				Paths:         []string{"/user/(?<id>[^/]+)$"},
+				RegexPriority: 1,
+				Plugins:       private,
+			}},
+		},
+	} {
+		config, err := Build([]ServiceSpec{{Name: "usermanager", Targets: []string{"host:8081"}, Routes: tc.routes}}, options)
+		if err != nil {
+			t.Fatalf("%s: Build: %v", tc.name, err)
+		}
+		if len(config.Services) != 1 {
+			t.Fatalf("%s: services = %+v", tc.name, config.Services)
+		}
+		if got := config.Services[0].Routes; !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: routes = %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+}
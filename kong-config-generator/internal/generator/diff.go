@@ -0,0 +1,323 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AdminAPI lê o estado atual de um gateway rodando, para comparar com a configuração gerada.
+type AdminAPI struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewAdminAPI(baseURL string) *AdminAPI {
+	return &AdminAPI{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type entityRef struct {
+	ID string `json:"id"`
+}
+
+type adminService struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Protocol string `json:"protocol"`
+	Host     string `json:"host"`
+	Path     string `json:"path"`
+}
+
+type adminRoute struct {
+	Name          string     `json:"name"`
+	Methods       []string   `json:"methods"`
+	Paths         []string   `json:"paths"`
+	StripPath     bool       `json:"strip_path"`
+	RegexPriority int        `json:"regex_priority"`
+	Service       *entityRef `json:"service"`
+	ID            string     `json:"id"`
+}
+
+type adminPlugin struct {
+	Name     string                 `json:"name"`
+	Config   map[string]interface{} `json:"config"`
+	Service  *entityRef             `json:"service"`
+	Route    *entityRef             `json:"route"`
+	Consumer *entityRef             `json:"consumer"`
+}
+
+type adminConsumer struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+type adminUpstream struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type adminTarget struct {
+	Target string `json:"target"`
+	Weight int    `json:"weight"`
+}
+
+// Diff compara a configuração gerada com o gateway e retorna uma linha por divergência:
+// "+" existe só na configuração, "-" existe só no gateway, "~" existe nos dois mas difere.
+func (api *AdminAPI) Diff(desired *Config) ([]string, error) {
+	var services []adminService
+	var routes []adminRoute
+	var plugins []adminPlugin
+	var upstreams []adminUpstream
+	for path, out := range map[string]interface{}{
+		"/services":  &services,
+		"/routes":    &routes,
+		"/plugins":   &plugins,
+		"/upstreams": &upstreams,
+	} {
+		if err := api.list(path, out); err != nil {
+			return nil, err
+		}
+	}
+
+	var changes []string
+	serviceNames := make(map[string]string)
+	routeNames := make(map[string]string)
+
+	actualServices := make(map[string]adminService)
+	for _, service := range services {
+		actualServices[service.Name] = service
+		serviceNames[service.ID] = service.Name
+	}
+	actualRoutes := make(map[string]adminRoute)
+	for _, route := range routes {
+		actualRoutes[route.Name] = route
+		routeNames[route.ID] = route.Name
+	}
+	consumerNames, err := api.consumerNames(plugins)
+	if err != nil {
+		return nil, err
+	}
+	actualPlugins := make(map[string]adminPlugin)
+	for _, plugin := range plugins {
+		key := pluginKey(plugin.Name, scopeName(plugin.Service, serviceNames), scopeName(plugin.Route, routeNames))
+		actualPlugins[consumerScoped(key, scopeName(plugin.Consumer, consumerNames))] = plugin
+	}
+
+	desiredPlugins := make(map[string]Plugin)
+	for _, plugin := range desired.Plugins {
+		desiredPlugins[pluginKey(plugin.Name, "", "")] = plugin
+	}
+
+	for _, service := range desired.Services {
+		actual, ok := actualServices[service.Name]
+		if !ok {
+			changes = append(changes, "+ service "+service.Name)
+		} else {
+			changes = append(changes, compareFields("service "+service.Name, map[string][2]interface{}{
+				"protocol": {service.Protocol, actual.Protocol},
+				"host":     {service.Host, actual.Host},
+				"path":     {service.Path, actual.Path},
+			})...)
+			delete(actualServices, service.Name)
+		}
+
+		for _, route := range service.Routes {
+			actual, ok := actualRoutes[route.Name]
+			if !ok {
+				changes = append(changes, "+ route "+route.Name)
+			} else {
+				changes = append(changes, compareFields("route "+route.Name, map[string][2]interface{}{
+					"service":        {service.Name, scopeName(actual.Service, serviceNames)},
+					"methods":        {sorted(route.Methods), sorted(actual.Methods)},
+					"paths":          {sorted(route.Paths), sorted(actual.Paths)},
+					"strip_path":     {route.StripPath, actual.StripPath},
+					"regex_priority": {route.RegexPriority, actual.RegexPriority},
+				})...)
+				delete(actualRoutes, route.Name)
+			}
+
+			for _, plugin := range route.Plugins {
+				desiredPlugins[pluginKey(plugin.Name, "", route.Name)] = plugin
+			}
+		}
+	}
+
+	for key, plugin := range desiredPlugins {
+		actual, ok := actualPlugins[key]
+		if !ok {
+			changes = append(changes, "+ plugin "+key)
+			continue
+		}
+		for field, value := range plugin.Config {
+			if !sameJSON(value, actual.Config[field]) {
+				changes = append(changes, fmt.Sprintf("~ plugin %s: config.%s %v != %v", key, field, asJSON(value), asJSON(actual.Config[field])))
+			}
+		}
+		delete(actualPlugins, key)
+	}
+
+	actualUpstreams := make(map[string]adminUpstream)
+	for _, upstream := range upstreams {
+		actualUpstreams[upstream.Name] = upstream
+	}
+	for _, upstream := range desired.Upstreams {
+		actual, ok := actualUpstreams[upstream.Name]
+		if !ok {
+			changes = append(changes, "+ upstream "+upstream.Name)
+			continue
+		}
+		delete(actualUpstreams, upstream.Name)
+
+		var targets []adminTarget
+		if err := api.list("/upstreams/"+url.PathEscape(actual.ID)+"/targets", &targets); err != nil {
+			return nil, err
+		}
+		var want, got []string
+		for _, target := range upstream.Targets {
+			want = append(want, fmt.Sprintf("%s(%d)", target.Target, target.Weight))
+		}
+		for _, target := range targets {
+			got = append(got, fmt.Sprintf("%s(%d)", target.Target, target.Weight))
+		}
+		changes = append(changes, compareFields("upstream "+upstream.Name, map[string][2]interface{}{
+			"targets": {sorted(want), sorted(got)},
+		})...)
+	}
+
+	for name := range actualServices {
+		changes = append(changes, "- service "+name)
+	}
+	for name := range actualRoutes {
+		changes = append(changes, "- route "+name)
+	}
+	for key := range actualPlugins {
+		changes = append(changes, "- plugin "+key)
+	}
+	for name := range actualUpstreams {
+		changes = append(changes, "- upstream "+name)
+	}
+
+	sort.Strings(changes)
+	return changes, nil
+}
+
+// consumerNames só lista os consumers quando algum plugin é de consumer, já que o
+// user-microservice cria um consumer por usuário.
+func (api *AdminAPI) consumerNames(plugins []adminPlugin) (map[string]string, error) {
+	names := make(map[string]string)
+	for _, plugin := range plugins {
+		if plugin.Consumer == nil {
+			continue
+		}
+		var consumers []adminConsumer
+		if err := api.list("/consumers", &consumers); err != nil {
+			return nil, err
+		}
+		for _, consumer := range consumers {
+			names[consumer.ID] = consumer.Username
+		}
+		break
+	}
+	return names, nil
+}
+
+func (api *AdminAPI) list(path string, out interface{}) error {
+	var all []json.RawMessage
+	for path != "" {
+		resp, err := api.httpClient.Get(api.baseURL + path)
+		if err != nil {
+			return err
+		}
+
+		var page struct {
+			Data []json.RawMessage `json:"data"`
+			Next *string           `json:"next"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("kong admin GET %s: %s", path, resp.Status)
+		}
+		if err != nil {
+			return err
+		}
+
+		all = append(all, page.Data...)
+		path = ""
+		if page.Next != nil {
+			path = *page.Next
+		}
+	}
+
+	raw, err := json.Marshal(all)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, out)
+}
+
+func pluginKey(name, service, route string) string {
+	switch {
+	case route != "":
+		return name + "@route:" + route
+	case service != "":
+		return name + "@service:" + service
+	default:
+		return name + "@global"
+	}
+}
+
+// A configuração gerada não tem plugins de consumer, então eles aparecem como "-" com
+// uma chave própria, em vez de sobrescrever o plugin global ou da rota de mesmo nome.
+func consumerScoped(key, consumer string) string {
+	if consumer == "" {
+		return key
+	}
+	return key + "@consumer:" + consumer
+}
+
+func scopeName(ref *entityRef, names map[string]string) string {
+	if ref == nil {
+		return ""
+	}
+	if name, ok := names[ref.ID]; ok {
+		return name
+	}
+	return ref.ID
+}
+
+func compareFields(entity string, fields map[string][2]interface{}) []string {
+	var changes []string
+	for field, values := range fields {
+		if !sameJSON(values[0], values[1]) {
+			changes = append(changes, fmt.Sprintf("~ %s: %s %s != %s", entity, field, asJSON(values[0]), asJSON(values[1])))
+		}
+	}
+	return changes
+}
+
+func sameJSON(a, b interface{}) bool {
+	var left, right interface{}
+	json.Unmarshal([]byte(asJSON(a)), &left)
+	json.Unmarshal([]byte(asJSON(b)), &right)
+	return reflect.DeepEqual(left, right)
+}
+
+func asJSON(value interface{}) string {
+	raw, _ := json.Marshal(value)
+	return string(raw)
+}
+
+func sorted(values []string) []string {
+	copied := append([]string(nil), values...)
+	sort.Strings(copied)
+	return copied
+}
@@ -0,0 +1,121 @@
+package generator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// adminState é o estado que a Admin API falsa devolve, por caminho de listagem.
+type adminState map[string][]interface{}
+
+func (s adminState) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	items, ok := s[r.URL.Path]
+	if !ok {
+		items = []interface{}{}
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"data": items, "next": nil})
+}
+
+// stateOf monta o estado de um gateway que aplicou exatamente a configuração.
+func stateOf(config *Config) adminState {
+	state := adminState{}
+	for _, service := range config.Services {
+		state["/services"] = append(state["/services"], adminService{ID: "s-" + service.Name, Name: service.Name, Protocol: service.Protocol, Host: service.Host, Path: service.Path})
+		for _, route := range service.Routes {
+			state["/routes"] = append(state["/routes"], adminRoute{
+				ID: "r-" + route.Name, Name: route.Name, Methods: route.Methods, Paths: route.Paths,
+				StripPath: route.StripPath, RegexPriority: route.RegexPriority, Service: &entityRef{ID: "s-" + service.Name},
+			})
+			for _, plugin := range route.Plugins {
+				state["/plugins"] = append(state["/plugins"], adminPlugin{Name: plugin.Name, Config: plugin.Config, Route: &entityRef{ID: "r-" + route.Name}})
+			}
+		}
+	}
+	for _, plugin := range config.Plugins {
+		state["/plugins"] = append(state["/plugins"], adminPlugin{Name: plugin.Name, Config: plugin.Config})
+	}
+	for _, upstream := range config.Upstreams {
+		state["/upstreams"] = append(state["/upstreams"], adminUpstream{ID: "u-" + upstream.Name, Name: upstream.Name})
+		for _, target := range upstream.Targets {
+			path := "/upstreams/u-" + upstream.Name + "/targets"
+			state[path] = append(state[path], adminTarget{Target: target.Target, Weight: target.Weight})
+		}
+	}
+	return state
+}
+
+func TestDiff(t *testing.T) {
+	desired, err := Build([]ServiceSpec{{
+		Name:    "usermanager",
+		Targets: []string{"host:8081"},
+		Routes: []RouteInfo{
+			{Method: "POST", Path: "/login", Public: true},
+			{Method: "GET", Path: "/user/:id"},
+		},
+	}}, Options{RateLimitPerMinute: 60, CorsOrigins: []string{"*"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		change func(state adminState)
+		want   []string
+	}{
+		{
+			name:   "in sync",
+			change: func(state adminState) {},
+		},
+		{
+			name: "route methods",
+			change: func(state adminState) {
+				route := state["/routes"][1].(adminRoute)
+				route.Methods = []string{"GET", "POST"}
+				state["/routes"][1] = route
+			},
+			want: []string{`~ route usermanager-user-id: methods ["GET"] != ["GET","POST"]`},
+		},
+		{
+			name: "consumer plugin",
+			change: func(state adminState) {
+				state["/consumers"] = []interface{}{adminConsumer{ID: "c-1", Username: "ana@example.com"}}
+				state["/plugins"] = append(state["/plugins"], adminPlugin{
+					Name:     "rate-limiting",
+					Config:   map[string]interface{}{"minute": 5},
+					Consumer: &entityRef{ID: "c-1"},
+				})
+			},
+			want: []string{"- plugin rate-limiting@global@consumer:ana@example.com"},
+		},
+		{
+			name: "consumer plugin on a route",
+			change: func(state adminState) {
+				state["/plugins"] = append(state["/plugins"], adminPlugin{
+					Name:     "jwt",
+					Route:    &entityRef{ID: "r-usermanager-user-id"},
+					Consumer: &entityRef{ID: "c-unknown"},
+				})
+			},
+			want: []string{"- plugin jwt@route:usermanager-user-id@consumer:c-unknown"},
+		},
+	} {
+		state := stateOf(desired)
+		tc.change(state)
+		server := httptest.NewServer(state)
+
+		changes, err := NewAdminAPI(server.URL).Diff(desired)
+		server.Close()
+		if err != nil {
+			t.Fatalf("%s: Diff: %v", tc.name, err)
+		}
+		if len(changes) == 0 && len(tc.want) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(changes, tc.want) {
+			t.Errorf("%s: changes = %q, want %q", tc.name, changes, tc.want)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+)
+
+// RouteInfo é uma rota da tabela do Gin, como impressa por `go run ./cmd/routes`
+// em cada microserviço.
+type RouteInfo struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Public bool   `json:"public"`
+}
+
+// LoadRoutes executa o comando cmd/routes dentro da pasta do microserviço.
+func LoadRoutes(serviceDir string) ([]RouteInfo, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("go", "run", "./cmd/routes")
+	cmd.Dir = serviceDir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("go run ./cmd/routes in %s: %v: %s", serviceDir, err, stderr.String())
+	}
+
+	var routes []RouteInfo
+	if err := json.Unmarshal(stdout.Bytes(), &routes); err != nil {
+		return nil, fmt.Errorf("invalid route table from %s: %v", serviceDir, err)
+	}
+	return routes, nil
+}
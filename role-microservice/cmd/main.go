@@ -26,6 +26,7 @@ import (
 	"login-api/models"
 	"os"
 	"reflect"
+	"shared/gatewayauth"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -44,6 +45,8 @@ import (
 func AutoMigrate(db *gorm.DB) {
 	models := []interface{}{
 		&models.Role{},
+		// Tabela do user-microservice, migrada aqui também para o serviço subir sozinho.
+		&models.OutboxEvent{},
 	}
 
 	for _, model := range models {
@@ -62,6 +65,14 @@ func main() {
 	var secretKey string = os.Getenv("SECRET_KEY")
 	jwtAuth, _ := middleware.NewJWTTokenMaker(secretKey)
 	controllers.Initialize(config.Connect(), jwtAuth)
+	controllers.UseTokenChecker(repositories.NewGormTokenRepository(db))
+	if os.Getenv("AUTH_MODE") == "trusted-gateway" {
+		trustedGateway, err := gatewayauth.NewTrustedGatewayFromEnv()
+		if err != nil {
+			log.Fatalf("Invalid trusted gateway configuration: %v", err)
+		}
+		controllers.UseTrustedGateway(trustedGateway)
+	}
 	router := gin.Default()
 
 	// Repository
@@ -69,6 +80,9 @@ func main() {
 
 	// Use Case
 	roleUseCase := usecases.NewRoleUseCase(roleRepo)
+	if err := roleUseCase.EnsureSystemRoles(); err != nil {
+		log.Fatalf("Failed to seed system roles: %v", err)
+	}
 
 	// Controller
 	roleController := controllers.NewRoleController(roleUseCase)
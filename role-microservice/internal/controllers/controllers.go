@@ -1,7 +1,9 @@
 package controllers
 
 import (
+	"errors"
 	middleware "login-api/middleware"
+	"shared/gatewayauth"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -10,10 +12,11 @@ import (
 
 // Response representa uma resposta genérica da API
 type Response struct {
-	Data    interface{}       `json:"data,omitempty"`
-	Message string            `json:"message,omitempty"`
-	Error   string            `json:"error,omitempty"`
-	Errors  map[string]string `json:"errors,omitempty"`
+	Data       interface{}       `json:"data,omitempty"`
+	Pagination *Pagination       `json:"pagination,omitempty"`
+	Message    string            `json:"message,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	Errors     map[string]string `json:"errors,omitempty"`
 }
 
 // ErrorResponse representa uma resposta de erro
@@ -24,13 +27,48 @@ type ErrorResponse struct {
 
 var db *gorm.DB
 var auth middleware.Auth
+var trustedGateway *gatewayauth.TrustedGateway
+var tokenChecker TokenChecker
+
+// TokenChecker diz se um token com assinatura válida foi revogado no user-microservice (logout).
+type TokenChecker interface {
+	IsRevoked(tokenID string) (bool, error)
+}
 
 func Initialize(dbConnection *gorm.DB, authService middleware.Auth) {
 	db = dbConnection
 	auth = authService
 }
 
+// UseTrustedGateway faz o Authenticate confiar na identidade enviada pelo Kong
+// em vez de verificar o JWT. Requisições que não vêm do gateway são rejeitadas.
+func UseTrustedGateway(gateway *gatewayauth.TrustedGateway) {
+	trustedGateway = gateway
+}
+
+func UseTokenChecker(checker TokenChecker) {
+	tokenChecker = checker
+}
+
 func Authenticate(c *gin.Context) {
+	if trustedGateway != nil {
+		identity, err := trustedGateway.Identify(c.Request)
+		if err != nil {
+			c.JSON(401, gin.H{"error": "Request must come through the API gateway"})
+			c.Abort()
+			return
+		}
+		if err := checkGatewayIdentity(c, identity); err != nil {
+			c.JSON(401, gin.H{"error": "Token has been revoked"})
+			c.Abort()
+			return
+		}
+		c.Set("Email", identity.Username)
+		c.Set("Roles", identity.Roles)
+		c.Next()
+		return
+	}
+
 	authHeader := c.GetHeader("Authorization")
 	if authHeader == "" {
 		c.JSON(401, gin.H{"error": "Authorization header is required"})
@@ -46,7 +84,45 @@ func Authenticate(c *gin.Context) {
 		return
 	}
 
+	if err := checkRevoked(payload); err != nil {
+		c.JSON(401, gin.H{"error": "Token has been revoked"})
+		c.Abort()
+		return
+	}
+
 	c.Set("Email", payload.Username)
 	c.Set("Roles", payload.Roles)
 	c.Next()
 }
+
+// checkGatewayIdentity verifica no modo trusted-gateway o JWT repassado pelo Kong: ele precisa
+// ser do consumer informado, não ter expirado e não ter sido revogado. Sem JWT (ex.: key-auth)
+// não há token a verificar.
+func checkGatewayIdentity(c *gin.Context, identity *gatewayauth.GatewayIdentity) error {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		return nil
+	}
+	payload, err := auth.VerifyToken(strings.TrimPrefix(authHeader, "Bearer "))
+	if err != nil {
+		return err
+	}
+	if payload.Username != identity.Username {
+		return errors.New("token does not belong to the gateway consumer")
+	}
+	return checkRevoked(payload)
+}
+
+func checkRevoked(payload *middleware.Payload) error {
+	if tokenChecker == nil {
+		return nil
+	}
+	revoked, err := tokenChecker.IsRevoked(payload.ID.String())
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return errors.New("token has been revoked")
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	middleware "login-api/middleware"
+	"shared/gatewayauth"
+
+	"github.com/gin-gonic/gin"
+)
+
+type revokedTokens map[string]bool
+
+func (r revokedTokens) IsRevoked(tokenID string) (bool, error) {
+	return r[tokenID], nil
+}
+
+func TestAuthenticateTrustedGatewayChecksForwardedToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	jwtAuth, err := middleware.NewJWTTokenMaker("01234567890123456789012345678901")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, network, _ := net.ParseCIDR("10.0.0.0/8")
+	revoked := revokedTokens{}
+	Initialize(nil, jwtAuth)
+	UseTrustedGateway(&gatewayauth.TrustedGateway{
+		TrustedNetworks: []*net.IPNet{network},
+		UsernameHeader:  "X-Consumer-Username",
+		RolesHeaders:    []string{"X-Consumer-Groups"},
+	})
+	UseTokenChecker(revoked)
+	defer func() {
+		UseTrustedGateway(nil)
+		UseTokenChecker(nil)
+	}()
+
+	valid, _ := jwtAuth.CreateTokenWithRoles("ana@example.com", []string{"Admin"}, time.Hour)
+	expired, _ := jwtAuth.CreateTokenWithRoles("ana@example.com", []string{"Admin"}, -time.Minute)
+	other, _ := jwtAuth.CreateTokenWithRoles("bia@example.com", []string{"Admin"}, time.Hour)
+	logout, _ := jwtAuth.CreateTokenWithRoles("ana@example.com", []string{"Admin"}, time.Hour)
+	payload, _ := jwtAuth.VerifyToken(logout)
+	revoked[payload.ID.String()] = true
+
+	router := gin.New()
+	router.GET("/role", Authenticate, func(c *gin.Context) { c.Status(200) })
+
+	for _, tc := range []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"valid", valid, 200},
+		{"without jwt", "", 200},
+		{"expired", expired, 401},
+		{"another consumer", other, 401},
+		{"revoked", logout, 401},
+	} {
+		r := httptest.NewRequest("GET", "/role", nil)
+		r.RemoteAddr = "10.1.2.3:5000"
+		r.Header.Set("X-Consumer-Username", "ana@example.com")
+		r.Header.Set("X-Consumer-Groups", "Admin")
+		if tc.token != "" {
+			r.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		if w.Code != tc.want {
+			t.Errorf("%s: status = %d, want %d", tc.name, w.Code, tc.want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"math"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// Pagination acompanha as listagens paginadas com o total e os links de navegação.
+type Pagination struct {
+	Total    int64             `json:"total"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"page_size"`
+	Links    map[string]string `json:"links"`
+}
+
+// parsePageParams lê page e page_size, com os padrões quando ausentes.
+func parsePageParams(c *gin.Context, errorMessages map[string]string) (int, int) {
+	page, pageSize := 1, defaultPageSize
+	if value := c.Query("page"); value != "" {
+		var err error
+		page, err = strconv.Atoi(value)
+		if err != nil || page < 1 {
+			errorMessages["page"] = "Page must be a positive integer"
+		}
+	}
+	if value := c.Query("page_size"); value != "" {
+		var err error
+		pageSize, err = strconv.Atoi(value)
+		if err != nil || pageSize < 1 || pageSize > maxPageSize {
+			errorMessages["page_size"] = "Page size must be between 1 and " + strconv.Itoa(maxPageSize)
+		}
+	}
+	return page, pageSize
+}
+
+// offsetPagination monta a paginação por página com os links first, prev, next e last.
+func offsetPagination(c *gin.Context, page, pageSize int, total int64) *Pagination {
+	pagination := &Pagination{
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+		Links:    map[string]string{"self": c.Request.URL.RequestURI()},
+	}
+
+	lastPage := int(math.Max(1, math.Ceil(float64(total)/float64(pageSize))))
+	pagination.Links["first"] = pageLink(c, map[string]string{"page": "1"})
+	pagination.Links["last"] = pageLink(c, map[string]string{"page": strconv.Itoa(lastPage)})
+	if page > 1 {
+		pagination.Links["prev"] = pageLink(c, map[string]string{"page": strconv.Itoa(page - 1)})
+	}
+	if page < lastPage {
+		pagination.Links["next"] = pageLink(c, map[string]string{"page": strconv.Itoa(page + 1)})
+	}
+	return pagination
+}
+
+// pageLink repete a query atual trocando os parâmetros informados.
+func pageLink(c *gin.Context, changes map[string]string) string {
+	values := url.Values{}
+	for key, list := range c.Request.URL.Query() {
+		values[key] = list
+	}
+	for key, value := range changes {
+		values.Set(key, value)
+	}
+	return c.Request.URL.Path + "?" + values.Encode()
+}
@@ -7,8 +7,13 @@
 package controllers
 
 import (
+	"errors"
+	"login-api/internal/dto"
+	"login-api/internal/repositories"
 	"login-api/internal/usecases"
 	"login-api/models"
+	"shared/etag"
+	"shared/representation"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -27,10 +32,16 @@ func NewRoleController(roleUseCase *usecases.RoleUseCase) *RoleController {
 // @Tags roles
 // @Accept json
 // @Produce json
-// @Success 200 {object} Response{data=[]domains.Role} "Success"
+// @Param fields query string false "Comma-separated fields to return, e.g. id,name,display_name"
+// @Success 200 {object} Response{data=[]dto.Role} "Success"
 // @Failure 500 {object} ErrorResponse{error=string} "Internal Server Error"
 // @Router /roles [get]
 func (ctrl *RoleController) GetRoles(c *gin.Context) {
+	options, ok := roleOptions(c)
+	if !ok {
+		return
+	}
+
 	roles, err := ctrl.roleUseCase.GetAll()
 	if err != nil {
 		c.JSON(500, ErrorResponse{
@@ -40,7 +51,7 @@ func (ctrl *RoleController) GetRoles(c *gin.Context) {
 	}
 
 	c.JSON(200, Response{
-		Data: roles,
+		Data: options.Sparse(dto.NewRoles(roles)),
 	})
 }
 
@@ -50,10 +61,18 @@ func (ctrl *RoleController) GetRoles(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Role ID"
-// @Success 200 {object} Response{data=domains.Role} "Success"
+// @Param fields query string false "Comma-separated fields to return, e.g. id,name,display_name"
+// @Param If-None-Match header string false "ETag from a previous response"
+// @Success 200 {object} Response{data=dto.Role} "Success"
+// @Success 304 "Not Modified"
 // @Router /roles/{id} [get]
 func (ctrl *RoleController) GetRole(c *gin.Context) {
 	id := c.Param("id")
+	options, ok := roleOptions(c)
+	if !ok {
+		return
+	}
+
 	role, err := ctrl.roleUseCase.GetByID(id)
 	if err != nil {
 		c.JSON(404, ErrorResponse{
@@ -61,42 +80,32 @@ func (ctrl *RoleController) GetRole(c *gin.Context) {
 		})
 		return
 	}
+	if etag.NotModified(c, role.Version, options.Key()) {
+		return
+	}
 
 	c.JSON(200, Response{
-		Data: role,
+		Data: options.Sparse(dto.NewRole(role)),
 	})
 }
 
 // @Summary Create new role
-// @Description Create a new role
+// @Description Create a new role. Roles created through the API are never system roles
 // @Tags roles
 // @Accept json
 // @Produce json
-// @Param role body domains.Role true "Role information"
-// @Success 200 {object} Response{data=domains.Role} "Success"
+// @Param role body models.RoleRequest true "Role information"
+// @Success 200 {object} Response{data=dto.Role} "Success"
 // @Failure 400 {object} ErrorResponse{errors=map[string]string} "Validation Error"
 // @Failure 409 {object} ErrorResponse{error=string} "Role Already Exists"
 // @Failure 500 {object} ErrorResponse{error=string} "Internal Server Error"
 func (ctrl *RoleController) CreateRole(c *gin.Context) {
-	var role models.Role
-	if err := c.ShouldBindJSON(&role); err != nil {
-		if validationErrors, ok := err.(validator.ValidationErrors); ok {
-			errorMessages := make(map[string]string)
-			for _, fieldErr := range validationErrors {
-				switch fieldErr.Field() {
-				case "Name":
-					errorMessages["name"] = "Role name is required"
-				case "Description":
-					errorMessages["description"] = "Role description is required"
-				}
-			}
-			c.JSON(400, ErrorResponse{
-				Errors: errorMessages,
-			})
-			return
-		}
+	var request models.RoleRequest
+	if !bindRoleRequest(c, &request) {
+		return
 	}
 
+	role := request.Role()
 	err := ctrl.roleUseCase.Create(&role)
 	if err != nil {
 		if err.Error() == "role already exists" {
@@ -111,33 +120,42 @@ func (ctrl *RoleController) CreateRole(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", etag.Tag(role.Version))
 	c.JSON(200, Response{
-		Data: role,
+		Data: dto.NewRole(&role),
 	})
 }
 
 // @Summary Update role
-// @Description Update an existing role's information
+// @Description Update an existing role's information. System roles keep their name
 // @Tags roles
 // @Accept json
 // @Produce json
 // @Param id path string true "Role ID"
-// @Param role body domains.Role true "Updated role information"
-// @Success 200 {object} Response{data=domains.Role} "Success"
+// @Param If-Match header string true "ETag of the version being replaced"
+// @Param role body models.RoleRequest true "Updated role information"
+// @Success 200 {object} Response{data=dto.Role} "Success"
 // @Failure 404 {object} ErrorResponse{error=string} "Role Not Found"
 // @Failure 400 {object} ErrorResponse{error=string} "Invalid Data"
+// @Failure 403 {object} ErrorResponse{error=string} "System Role"
+// @Failure 409 {object} ErrorResponse{error=string} "Role Already Exists"
+// @Failure 412 {object} ErrorResponse{error=string} "Version Mismatch"
+// @Failure 428 {object} ErrorResponse{error=string} "If-Match Required"
 // @Failure 500 {object} ErrorResponse{error=string} "Update Failed"
 func (ctrl *RoleController) UpdateRole(c *gin.Context) {
 	id := c.Param("id")
-	var role models.Role
-	if err := c.ShouldBindJSON(&role); err != nil {
-		c.JSON(400, ErrorResponse{
-			Error: "Invalid data provided",
-		})
+	version, ok := etag.IfMatch(c)
+	if !ok {
+		return
+	}
+
+	var request models.RoleRequest
+	if !bindRoleRequest(c, &request) {
 		return
 	}
 
-	err := ctrl.roleUseCase.Update(id, &role)
+	role := request.Role()
+	err := ctrl.roleUseCase.Update(id, &role, version)
 	if err != nil {
 		if err.Error() == "role not found" {
 			c.JSON(404, ErrorResponse{
@@ -145,32 +163,73 @@ func (ctrl *RoleController) UpdateRole(c *gin.Context) {
 			})
 			return
 		}
+		if errors.Is(err, usecases.ErrSystemRole) {
+			c.JSON(403, ErrorResponse{
+				Error: "System roles cannot be renamed",
+			})
+			return
+		}
+		if err.Error() == "role already exists" {
+			c.JSON(409, ErrorResponse{
+				Error: "Role already exists",
+			})
+			return
+		}
+		if errors.Is(err, repositories.ErrVersionConflict) {
+			etag.PreconditionFailed(c)
+			return
+		}
 		c.JSON(500, ErrorResponse{
 			Error: "Failed to update role",
 		})
 		return
 	}
 
+	c.Header("ETag", etag.Tag(role.Version))
 	c.JSON(200, Response{
-		Data: role,
+		Data: dto.NewRole(&role),
 	})
 }
 
 // @Summary Delete role
-// @Description Delete a role by its ID
+// @Description Delete a role by its ID. A role assigned to users is only deleted with reassign_to, which moves the direct and group assignments to another role, or with force=true, which drops them. A role.deleted event lists the affected users
 // @Tags roles
 // @Accept json
 // @Produce json
 // @Param id path string true "Role ID"
+// @Param reassign_to query string false "ID of the role that receives the assignments"
+// @Param force query bool false "Drop the assignments"
+// @Param If-Match header string true "ETag of the version being deleted"
 // @Success 200 {object} Response{message=string} "Success"
 // @Failure 404 {object} ErrorResponse{error=string} "Role Not Found"
-// @Failure 400 {object} ErrorResponse{error=string} "Role In Use"
+// @Failure 400 {object} ErrorResponse{error=string} "Role In Use or Invalid Strategy"
+// @Failure 403 {object} ErrorResponse{error=string} "System Role"
+// @Failure 412 {object} ErrorResponse{error=string} "Version Mismatch"
+// @Failure 428 {object} ErrorResponse{error=string} "If-Match Required"
 // @Failure 500 {object} ErrorResponse{error=string} "Delete Failed"
 // @Router /roles/{id} [delete]
 func (ctrl *RoleController) DeleteRole(c *gin.Context) {
 	id := c.Param("id")
+	version, ok := etag.IfMatch(c)
+	if !ok {
+		return
+	}
 
-	err := ctrl.roleUseCase.Delete(id)
+	err := ctrl.roleUseCase.Delete(id, version, models.RoleDeleteOptions{
+		ReassignTo: c.Query("reassign_to"),
+		Force:      c.Query("force") == "true",
+		DeletedBy:  c.GetString("Email"),
+	})
+	if errors.Is(err, repositories.ErrVersionConflict) {
+		etag.PreconditionFailed(c)
+		return
+	}
+	if errors.Is(err, usecases.ErrSystemRole) {
+		c.JSON(403, ErrorResponse{
+			Error: "System roles cannot be deleted",
+		})
+		return
+	}
 	if err != nil {
 		switch err.Error() {
 		case "role not found":
@@ -179,7 +238,19 @@ func (ctrl *RoleController) DeleteRole(c *gin.Context) {
 			})
 		case "role is assigned to users":
 			c.JSON(400, ErrorResponse{
-				Error: "Cannot delete role as it is assigned to users",
+				Error: "Cannot delete role as it is assigned to users; use reassign_to or force=true",
+			})
+		case "reassign_to and force cannot be combined":
+			c.JSON(400, ErrorResponse{
+				Error: "Use either reassign_to or force, not both",
+			})
+		case "reassignment role not found":
+			c.JSON(400, ErrorResponse{
+				Error: "Reassignment role not found",
+			})
+		case "cannot reassign to the deleted role":
+			c.JSON(400, ErrorResponse{
+				Error: "Cannot reassign to the role being deleted",
 			})
 		default:
 			c.JSON(500, ErrorResponse{
@@ -193,3 +264,51 @@ func (ctrl *RoleController) DeleteRole(c *gin.Context) {
 		Message: "Role successfully deleted",
 	})
 }
+
+// bindRoleRequest lê o corpo e responde 400 com uma mensagem por campo inválido.
+func bindRoleRequest(c *gin.Context, request *models.RoleRequest) bool {
+	err := c.ShouldBindJSON(request)
+	if err == nil {
+		return true
+	}
+
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		c.JSON(400, ErrorResponse{
+			Error: "Invalid data provided",
+		})
+		return false
+	}
+
+	errorMessages := make(map[string]string)
+	for _, fieldErr := range validationErrors {
+		switch fieldErr.Field() {
+		case "Name":
+			errorMessages["name"] = "Role name is required and must have at most 50 characters"
+		case "DisplayName":
+			errorMessages["display_name"] = "Display name must have at most 100 characters"
+		case "Description":
+			errorMessages["description"] = "Role description must have at most 500 characters"
+		case "Color":
+			errorMessages["color"] = "Color must be a hex color, e.g. #1976d2"
+		case "Icon":
+			errorMessages["icon"] = "Icon must have at most 50 characters"
+		}
+	}
+	c.JSON(400, ErrorResponse{
+		Errors: errorMessages,
+	})
+	return false
+}
+
+// roleOptions lê ?fields= e responde 400 quando pedem um campo que o papel não tem.
+func roleOptions(c *gin.Context) (representation.Options, bool) {
+	options, errorMessages := representation.ParseOptions(c.Query("fields"), "", dto.RoleFields, nil)
+	if len(errorMessages) > 0 {
+		c.JSON(400, ErrorResponse{
+			Errors: errorMessages,
+		})
+		return options, false
+	}
+	return options, true
+}
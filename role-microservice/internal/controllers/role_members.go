@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"errors"
+	"login-api/internal/usecases"
+	"login-api/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// @Summary List role members
+// @Description List the users directly assigned to the role, ordered by user ID. Roles inherited from groups are not included
+// @Tags role-members
+// @Produce json
+// @Param id path string true "Role ID"
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} Response{data=[]models.RoleMember,pagination=Pagination} "Success"
+// @Failure 400 {object} ErrorResponse{errors=map[string]string} "Invalid Pagination"
+// @Failure 404 {object} ErrorResponse{error=string} "Role Not Found"
+// @Failure 500 {object} ErrorResponse{error=string} "Internal Server Error"
+// @Router /role/{id}/members [get]
+func (ctrl *RoleController) GetMembers(c *gin.Context) {
+	errorMessages := make(map[string]string)
+	page, pageSize := parsePageParams(c, errorMessages)
+	if len(errorMessages) > 0 {
+		c.JSON(400, ErrorResponse{
+			Errors: errorMessages,
+		})
+		return
+	}
+
+	members, total, err := ctrl.roleUseCase.GetMembers(c.Param("id"), page, pageSize)
+	if err != nil {
+		if err.Error() == "role not found" {
+			c.JSON(404, ErrorResponse{
+				Error: "Role not found",
+			})
+			return
+		}
+		c.JSON(500, ErrorResponse{
+			Error: "Failed to retrieve role members",
+		})
+		return
+	}
+	if members == nil {
+		members = []models.RoleMember{}
+	}
+
+	c.JSON(200, Response{
+		Data:       members,
+		Pagination: offsetPagination(c, page, pageSize, total),
+	})
+}
+
+// @Summary Add role members
+// @Description Assign the role to several users at once, optionally limited to a period. Users that already have the role are returned as unchanged; nothing is changed if any user does not exist
+// @Tags role-members
+// @Accept json
+// @Produce json
+// @Param id path string true "Role ID"
+// @Param members body models.RoleMembersRequest true "User IDs, validity period and reason"
+// @Success 200 {object} Response{data=models.RoleMembersResult} "Success"
+// @Failure 400 {object} ErrorResponse{error=string} "Invalid Data or Validity Period"
+// @Failure 404 {object} ErrorResponse{errors=map[string]string} "Role or Users Not Found"
+// @Failure 500 {object} ErrorResponse{error=string} "Internal Server Error"
+// @Router /role/{id}/members [post]
+func (ctrl *RoleController) AddMembers(c *gin.Context) {
+	var request models.RoleMembersRequest
+	if !bindMembersRequest(c, &request) {
+		return
+	}
+	request.GrantedBy = c.GetString("Email")
+
+	result, err := ctrl.roleUseCase.AddMembers(c.Param("id"), request)
+	if err != nil {
+		if err.Error() == "invalid validity period" {
+			c.JSON(400, ErrorResponse{
+				Error: "valid_until must be in the future and after valid_from",
+			})
+			return
+		}
+		respondMembersError(c, err, "Failed to add role members")
+		return
+	}
+
+	c.JSON(200, Response{
+		Data: result,
+	})
+}
+
+// @Summary Remove role members
+// @Description Remove the role from several users at once. Users without the role are returned as unchanged
+// @Tags role-members
+// @Accept json
+// @Produce json
+// @Param id path string true "Role ID"
+// @Param members body models.RoleMembersRemoveRequest true "User IDs"
+// @Success 200 {object} Response{data=models.RoleMembersResult} "Success"
+// @Failure 400 {object} ErrorResponse{errors=map[string]string} "Invalid Data"
+// @Failure 404 {object} ErrorResponse{errors=map[string]string} "Role or Users Not Found"
+// @Failure 500 {object} ErrorResponse{error=string} "Internal Server Error"
+// @Router /role/{id}/members [delete]
+func (ctrl *RoleController) RemoveMembers(c *gin.Context) {
+	var request models.RoleMembersRemoveRequest
+	if !bindMembersRequest(c, &request) {
+		return
+	}
+
+	result, err := ctrl.roleUseCase.RemoveMembers(c.Param("id"), request)
+	if err != nil {
+		respondMembersError(c, err, "Failed to remove role members")
+		return
+	}
+
+	c.JSON(200, Response{
+		Data: result,
+	})
+}
+
+// bindMembersRequest lê o corpo; a única validação é a lista de usuários.
+func bindMembersRequest(c *gin.Context, request interface{}) bool {
+	err := c.ShouldBindJSON(request)
+	if err == nil {
+		return true
+	}
+	if _, ok := err.(validator.ValidationErrors); ok {
+		c.JSON(400, ErrorResponse{
+			Errors: map[string]string{"user_ids": "Between 1 and 500 user IDs are required"},
+		})
+		return false
+	}
+	c.JSON(400, ErrorResponse{
+		Error: "Invalid data provided",
+	})
+	return false
+}
+
+func respondMembersError(c *gin.Context, err error, message string) {
+	var unknown *usecases.UnknownUsersError
+	switch {
+	case errors.As(err, &unknown):
+		errorMessages := make(map[string]string)
+		for _, id := range unknown.IDs {
+			errorMessages[strconv.FormatUint(id, 10)] = "User not found"
+		}
+		c.JSON(404, ErrorResponse{Error: "User not found", Errors: errorMessages})
+	case err.Error() == "role not found":
+		c.JSON(404, ErrorResponse{Error: "Role not found"})
+	default:
+		c.JSON(500, ErrorResponse{Error: message})
+	}
+}
@@ -0,0 +1,47 @@
+// Package dto define as representações devolvidas pela API, separadas dos modelos do
+// GORM para que campos internos, como a senha, nunca sejam serializados.
+package dto
+
+import (
+	"login-api/models"
+	"time"
+)
+
+// RoleFields são os campos aceitos em ?fields=.
+var RoleFields = []string{"id", "name", "display_name", "description", "color", "icon", "is_system", "created_at", "updated_at", "version"}
+
+type Role struct {
+	ID          uint64    `json:"id"`
+	Name        string    `json:"name"`
+	DisplayName string    `json:"display_name"`
+	Description string    `json:"description"`
+	Color       string    `json:"color,omitempty"`
+	Icon        string    `json:"icon,omitempty"`
+	IsSystem    bool      `json:"is_system"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Version     uint64    `json:"version"`
+}
+
+func NewRole(role *models.Role) Role {
+	return Role{
+		ID:          role.ID,
+		Name:        role.Name,
+		DisplayName: role.DisplayName,
+		Description: role.Description,
+		Color:       role.Color,
+		Icon:        role.Icon,
+		IsSystem:    role.IsSystem,
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+		Version:     role.Version,
+	}
+}
+
+func NewRoles(roles []models.Role) []Role {
+	response := make([]Role, len(roles))
+	for i := range roles {
+		response[i] = NewRole(&roles[i])
+	}
+	return response
+}
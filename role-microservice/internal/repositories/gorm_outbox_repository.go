@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"events"
+	"login-api/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OutboxRepository grava na outbox compartilhada com o user-microservice, que faz a entrega.
+type OutboxRepository interface {
+	Add(event *models.OutboxEvent) error
+	// CorrelationID identifica os eventos gravados por esta instância, isto é, pela mesma transação.
+	CorrelationID() string
+}
+
+type GormOutboxRepository struct {
+	db            *gorm.DB
+	correlationID string
+}
+
+func NewGormOutboxRepository(db *gorm.DB) *GormOutboxRepository {
+	return &GormOutboxRepository{db: db, correlationID: events.NewID()}
+}
+
+func (r *GormOutboxRepository) Add(event *models.OutboxEvent) error {
+	if event.NextAttemptAt.IsZero() {
+		event.NextAttemptAt = time.Now()
+	}
+	return r.db.Create(event).Error
+}
+
+func (r *GormOutboxRepository) CorrelationID() string {
+	return r.correlationID
+}
@@ -1,23 +1,44 @@
 package repositories
 
 import (
+	"errors"
 	"login-api/models"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+// ErrVersionConflict indica que o registro mudou desde a versão lida pelo cliente.
+var ErrVersionConflict = errors.New("version conflict")
+
 type RoleRepository interface {
 	FindAll() ([]models.Role, error)
 	FindByID(id string) (*models.Role, error)
 	FindByName(name string) (*models.Role, error)
 	Create(role *models.Role) error
 	Update(role *models.Role) error
-	Delete(id string) error
-	CountUsersByRoleID(id string) (int64, error)
+	Delete(id string, version uint64) error
+	CountAssignments(id string) (int64, error)
+	// FindMembers lista as atribuições diretas do papel a usuários não excluídos, por id do usuário.
+	FindMembers(roleID uint64, page, pageSize int) ([]models.RoleMember, int64, error)
+	FindUsersByIDs(ids []uint64) ([]models.User, error)
+	// FindMemberIDs devolve, dentre userIDs, os que têm o papel em at; atribuições já vencidas,
+	// que o job do user-microservice ainda não removeu, não contam.
+	FindMemberIDs(roleID uint64, userIDs []uint64, at time.Time) ([]uint64, error)
+	AddMembers(roleID uint64, userIDs []uint64, grant models.RoleGrant) error
+	RemoveMembers(roleID uint64, userIDs []uint64) error
+	// FindAffectedUserIDs devolve os usuários não excluídos que têm o papel, direto ou por grupo.
+	FindAffectedUserIDs(roleID uint64) ([]uint64, error)
+	// ReassignMembers copia as atribuições diretas e de grupos de fromID para toID, mantendo o
+	// período de validade; quem já tem toID fica com a atribuição que tinha.
+	ReassignMembers(fromID, toID uint64, grantedBy, reason string) error
+	Transaction(fn func(repo RoleRepository) error) error
+	Outbox() OutboxRepository
 }
 
 type GormRoleRepository struct {
-	db *gorm.DB
+	db     *gorm.DB
+	outbox *GormOutboxRepository
 }
 
 func NewGormRoleRepository(db *gorm.DB) *GormRoleRepository {
@@ -52,16 +73,188 @@ func (r *GormRoleRepository) Create(role *models.Role) error {
 	return r.db.Create(role).Error
 }
 
+// Update grava o registro se ele ainda estiver em role.Version e incrementa a versão;
+// caso contrário devolve ErrVersionConflict.
+// O nome do papel aparece nos usuários com ?include=roles, então a versão deles também muda.
 func (r *GormRoleRepository) Update(role *models.Role) error {
-	return r.db.Save(role).Error
+	version := role.Version
+	role.Version++
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(role).Where("version = ?", version).Select("*").Updates(role)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+		return bumpRoleHolderVersions(tx, role.ID)
+	})
+	if err != nil {
+		role.Version = version
+	}
+	return err
+}
+
+// Delete remove o papel se ele ainda estiver em version, junto com as atribuições diretas e de
+// grupos (as chaves estrangeiras criadas pelo AutoMigrate não têm ON DELETE CASCADE).
+// Delete apaga os vínculos com usuários e grupos antes do papel, que é referenciado por eles, tudo
+// na mesma transação: se a versão não bater, nada é apagado.
+func (r *GormRoleRepository) Delete(id string, version uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := bumpRoleHolderVersions(tx, id); err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM user_roles WHERE role_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM group_roles WHERE role_id = ?", id).Error; err != nil {
+			return err
+		}
+		result := tx.Where("version = ?", version).Delete(&models.Role{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+		return nil
+	})
+}
+
+// CountAssignments conta as atribuições do papel: diretas, em user_roles, e a grupos, em group_roles.
+func (r *GormRoleRepository) CountAssignments(id string) (int64, error) {
+	var users, groups int64
+	if err := r.db.Table("user_roles").Where("role_id = ?", id).Count(&users).Error; err != nil {
+		return 0, err
+	}
+	if err := r.db.Table("group_roles").Where("role_id = ?", id).Count(&groups).Error; err != nil {
+		return 0, err
+	}
+	return users + groups, nil
+}
+
+func (r *GormRoleRepository) FindMembers(roleID uint64, page, pageSize int) ([]models.RoleMember, int64, error) {
+	query := r.db.Table("user_roles").
+		Joins("JOIN users ON users.id = user_roles.user_id").
+		Where("user_roles.role_id = ? AND users.deleted_at IS NULL", roleID).
+		Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var members []models.RoleMember
+	result := query.
+		Select("users.id AS user_id, users.name, users.email, user_roles.valid_from, user_roles.valid_until, user_roles.granted_by, user_roles.reason").
+		Order("users.id").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Scan(&members)
+	return members, total, result.Error
 }
 
-func (r *GormRoleRepository) Delete(id string) error {
-	return r.db.Delete(&models.Role{}, "id = ?", id).Error
+func (r *GormRoleRepository) FindUsersByIDs(ids []uint64) ([]models.User, error) {
+	var users []models.User
+	result := r.db.Where("id IN ?", ids).Find(&users)
+	return users, result.Error
 }
 
-func (r *GormRoleRepository) CountUsersByRoleID(id string) (int64, error) {
-	var count int64
-	result := r.db.Table("user_roles").Where("role_id = ?", id).Count(&count)
-	return count, result.Error
+func (r *GormRoleRepository) FindMemberIDs(roleID uint64, userIDs []uint64, at time.Time) ([]uint64, error) {
+	var ids []uint64
+	result := r.db.Table("user_roles").
+		Where("role_id = ? AND user_id IN ?", roleID, userIDs).
+		Where("valid_until IS NULL OR valid_until > ?", at).
+		Pluck("user_id", &ids)
+	return ids, result.Error
+}
+
+// AddMembers grava as atribuições; uma atribuição vencida que ainda está na tabela é renovada com
+// o novo prazo. As versões são incrementadas antes, travando os usuários na mesma ordem que o
+// user-microservice usa ao alterar papéis.
+func (r *GormRoleRepository) AddMembers(roleID uint64, userIDs []uint64, grant models.RoleGrant) error {
+	if err := bumpUserVersions(r.db, userIDs); err != nil {
+		return err
+	}
+	for _, userID := range userIDs {
+		err := r.db.Exec(`
+			INSERT INTO user_roles (user_id, role_id, valid_from, valid_until, granted_by, reason) VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (user_id, role_id) DO UPDATE SET
+				valid_from = EXCLUDED.valid_from,
+				valid_until = EXCLUDED.valid_until,
+				granted_by = EXCLUDED.granted_by,
+				reason = EXCLUDED.reason`,
+			userID, roleID, grant.ValidFrom, grant.ValidUntil, grant.GrantedBy, grant.Reason,
+		).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *GormRoleRepository) RemoveMembers(roleID uint64, userIDs []uint64) error {
+	if err := r.db.Exec("DELETE FROM user_roles WHERE role_id = ? AND user_id IN ?", roleID, userIDs).Error; err != nil {
+		return err
+	}
+	return bumpUserVersions(r.db, userIDs)
+}
+
+func (r *GormRoleRepository) FindAffectedUserIDs(roleID uint64) ([]uint64, error) {
+	var ids []uint64
+	result := r.db.Table("users").
+		Where("users.deleted_at IS NULL").
+		Where("users.id IN (SELECT user_id FROM user_roles WHERE role_id = ?) OR users.id IN (SELECT user_groups.user_id FROM user_groups JOIN group_roles ON group_roles.group_id = user_groups.group_id WHERE group_roles.role_id = ?)", roleID, roleID).
+		Order("users.id").
+		Pluck("users.id", &ids)
+	return ids, result.Error
+}
+
+func (r *GormRoleRepository) ReassignMembers(fromID, toID uint64, grantedBy, reason string) error {
+	if err := bumpRoleHolderVersions(r.db, fromID); err != nil {
+		return err
+	}
+	err := r.db.Exec(
+		`INSERT INTO user_roles (user_id, role_id, valid_from, valid_until, granted_by, reason)
+		SELECT user_id, ?, valid_from, valid_until, ?, ? FROM user_roles
+		WHERE role_id = ? AND user_id NOT IN (SELECT user_id FROM user_roles WHERE role_id = ?)`,
+		toID, grantedBy, reason, fromID, toID,
+	).Error
+	if err != nil {
+		return err
+	}
+	return r.db.Exec(
+		`INSERT INTO group_roles (group_id, role_id)
+		SELECT group_id, ? FROM group_roles
+		WHERE role_id = ? AND group_id NOT IN (SELECT group_id FROM group_roles WHERE role_id = ?)`,
+		toID, fromID, toID,
+	).Error
+}
+
+// bumpUserVersions incrementa a versão dos usuários cujos papéis diretos mudaram, para que o
+// ETag da representação com ?include=roles deixe de valer.
+func bumpUserVersions(db *gorm.DB, userIDs []uint64) error {
+	return db.Exec("UPDATE users SET version = version + 1 WHERE id IN ?", userIDs).Error
+}
+
+// bumpRoleHolderVersions faz o mesmo para todos os usuários com atribuição direta do papel.
+func bumpRoleHolderVersions(db *gorm.DB, roleID interface{}) error {
+	return db.Exec("UPDATE users SET version = version + 1 WHERE id IN (SELECT user_id FROM user_roles WHERE role_id = ?)", roleID).Error
+}
+
+// Transaction executa fn com um repositório ligado à mesma transação; a outbox dele é
+// sempre a mesma instância, então os eventos da operação compartilham a correlação.
+func (r *GormRoleRepository) Transaction(fn func(repo RoleRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		repo := NewGormRoleRepository(tx)
+		repo.outbox = NewGormOutboxRepository(tx)
+		return fn(repo)
+	})
+}
+
+func (r *GormRoleRepository) Outbox() OutboxRepository {
+	if r.outbox != nil {
+		return r.outbox
+	}
+	return NewGormOutboxRepository(r.db)
 }
@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"errors"
+	"login-api/models"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func newMockRoleRepository(t *testing.T) (*GormRoleRepository, sqlmock.Sqlmock) {
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("gorm: %v", err)
+	}
+	return NewGormRoleRepository(db), mock
+}
+
+func TestDeleteRoleWithMembersRemovesLinksFirst(t *testing.T) {
+	repo, mock := newMockRoleRepository(t)
+
+	// user_roles e group_roles referenciam roles, então saem antes do papel; a versão dos
+	// usuários que tinham o papel muda junto.
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE users SET version = version + 1")).
+		WithArgs("5").WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM user_roles WHERE role_id = $1")).
+		WithArgs("5").WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM group_roles WHERE role_id = $1")).
+		WithArgs("5").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "roles"`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := repo.Delete("5", 2); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDeleteRoleVersionConflictKeepsLinks(t *testing.T) {
+	repo, mock := newMockRoleRepository(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE users SET version = version + 1")).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM user_roles WHERE role_id = $1")).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM group_roles WHERE role_id = $1")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "roles"`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	if err := repo.Delete("5", 1); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("Delete: err = %v, want ErrVersionConflict", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUpdateRoleBumpsHolderVersions(t *testing.T) {
+	repo, mock := newMockRoleRepository(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "roles"`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE users SET version = version + 1 WHERE id IN (SELECT user_id FROM user_roles WHERE role_id = $1)")).
+		WithArgs(uint64(5)).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	role := &models.Role{ID: 5, Name: "Auditor", Version: 3}
+	if err := repo.Update(role); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if role.Version != 4 {
+		t.Errorf("Version = %d, want 4", role.Version)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFindMemberIDsIgnoresExpiredAssignments(t *testing.T) {
+	repo, mock := newMockRoleRepository(t)
+	at := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM "user_roles" WHERE (role_id = $1 AND user_id IN ($2,$3)) AND (valid_until IS NULL OR valid_until > $4)`)).
+		WithArgs(uint64(5), uint64(1), uint64(2), at).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1))
+
+	ids, err := repo.FindMemberIDs(5, []uint64{1, 2}, at)
+	if err != nil {
+		t.Fatalf("FindMemberIDs: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != 1 {
+		t.Errorf("ids = %v", ids)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAddMembersRenewsExistingAssignment(t *testing.T) {
+	repo, mock := newMockRoleRepository(t)
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE users SET version = version + 1 WHERE id IN ($1)")).
+		WithArgs(uint64(2)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("ON CONFLICT (user_id, role_id) DO UPDATE SET")).
+		WithArgs(uint64(2), uint64(5), nil, nil, "admin@example.com", "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.AddMembers(5, []uint64{2}, models.RoleGrant{GrantedBy: "admin@example.com"}); err != nil {
+		t.Fatalf("AddMembers: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -20,7 +20,7 @@ func Routers(router *gin.Engine, roleController *controllers.RoleController) {
 		AllowOrigins:     []string{"*"},
 		AllowHeaders:     []string{"*"},
 		AllowMethods:     []string{"*"},
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    []string{"Content-Length", "ETag"},
 		AllowCredentials: true,
 	}))
 
@@ -38,6 +38,9 @@ func Routers(router *gin.Engine, roleController *controllers.RoleController) {
 			roleRoutes.POST("", middleware.RequireRoles(ROLE_ADMIN), roleController.CreateRole)
 			roleRoutes.PUT(":id", middleware.RequireRoles(ROLE_ADMIN), roleController.UpdateRole)
 			roleRoutes.DELETE(":id", middleware.RequireRoles(ROLE_ADMIN), roleController.DeleteRole)
+			roleRoutes.GET(":id/members", middleware.RequireRoles(ROLE_ADMIN), roleController.GetMembers)
+			roleRoutes.POST(":id/members", middleware.RequireRoles(ROLE_ADMIN), roleController.AddMembers)
+			roleRoutes.DELETE(":id/members", middleware.RequireRoles(ROLE_ADMIN), roleController.RemoveMembers)
 		}
 	}
 }
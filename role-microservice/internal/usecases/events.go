@@ -0,0 +1,15 @@
+package usecases
+
+import (
+	"login-api/internal/repositories"
+	"shared/outbox"
+)
+
+// eventSource é o atributo source do CloudEvents dos eventos deste serviço.
+const eventSource = "/role-microservice"
+
+// publish grava o evento, no envelope CloudEvents, na outbox compartilhada. Use a outbox do
+// repositório da transação para que o evento só exista se a mudança for gravada.
+func publish(writer repositories.OutboxRepository, eventType string, data interface{}) error {
+	return outbox.Publish(writer, eventSource, eventType, data)
+}
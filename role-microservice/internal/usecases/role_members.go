@@ -0,0 +1,198 @@
+package usecases
+
+import (
+	"errors"
+	"events"
+	"login-api/internal/repositories"
+	"login-api/models"
+	"sort"
+	"time"
+)
+
+// UnknownUsersError lista os IDs de usuários que não existem (ou foram excluídos).
+type UnknownUsersError struct {
+	IDs []uint64
+}
+
+func (e *UnknownUsersError) Error() string {
+	return "user not found"
+}
+
+// GetMembers lista as atribuições diretas do papel; papéis herdados de grupos não aparecem.
+func (uc *RoleUseCase) GetMembers(id string, page, pageSize int) ([]models.RoleMember, int64, error) {
+	role, err := uc.repo.FindByID(id)
+	if err != nil {
+		return nil, 0, errors.New("role not found")
+	}
+	return uc.repo.FindMembers(role.ID, page, pageSize)
+}
+
+// AddMembers atribui o papel aos usuários que ainda não o têm, numa transação, e publica um
+// user.role_added para cada um, como uma atribuição feita pelo user-microservice.
+func (uc *RoleUseCase) AddMembers(id string, request models.RoleMembersRequest) (*models.RoleMembersResult, error) {
+	role, err := uc.repo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("role not found")
+	}
+
+	grant := request.RoleGrant
+	if grant.ValidUntil != nil {
+		if !grant.ValidUntil.After(time.Now()) || (grant.ValidFrom != nil && !grant.ValidUntil.After(*grant.ValidFrom)) {
+			return nil, errors.New("invalid validity period")
+		}
+	}
+
+	users, err := uc.findUsers(request.UserIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.RoleMembersResult{Changed: []uint64{}, Unchanged: []uint64{}}
+	err = uc.repo.Transaction(func(repo repositories.RoleRepository) error {
+		existing, err := repo.FindMemberIDs(role.ID, userIDs(users), time.Now())
+		if err != nil {
+			return err
+		}
+		isMember := make(map[uint64]bool)
+		for _, userID := range existing {
+			isMember[userID] = true
+		}
+
+		var added []models.User
+		for _, user := range users {
+			if isMember[user.ID] {
+				result.Unchanged = append(result.Unchanged, user.ID)
+				continue
+			}
+			added = append(added, user)
+			result.Changed = append(result.Changed, user.ID)
+		}
+		if len(added) == 0 {
+			return nil
+		}
+
+		if err := repo.AddMembers(role.ID, userIDs(added), grant); err != nil {
+			return err
+		}
+		for _, user := range added {
+			err := publish(repo.Outbox(), events.UserRoleAddedType, events.UserRoleAdded{
+				ID:         user.ID,
+				Email:      user.Email,
+				RoleID:     role.ID,
+				RoleName:   role.Name,
+				ValidFrom:  grant.ValidFrom,
+				ValidUntil: grant.ValidUntil,
+				GrantedBy:  grant.GrantedBy,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RemoveMembers retira o papel dos usuários informados que o têm e publica um user.role_removed
+// para cada um. Usuários sem o papel são devolvidos em Unchanged.
+func (uc *RoleUseCase) RemoveMembers(id string, request models.RoleMembersRemoveRequest) (*models.RoleMembersResult, error) {
+	role, err := uc.repo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("role not found")
+	}
+
+	users, err := uc.findUsers(request.UserIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.RoleMembersResult{Changed: []uint64{}, Unchanged: []uint64{}}
+	err = uc.repo.Transaction(func(repo repositories.RoleRepository) error {
+		existing, err := repo.FindMemberIDs(role.ID, userIDs(users), time.Now())
+		if err != nil {
+			return err
+		}
+		isMember := make(map[uint64]bool)
+		for _, userID := range existing {
+			isMember[userID] = true
+		}
+
+		var removed []models.User
+		for _, user := range users {
+			if !isMember[user.ID] {
+				result.Unchanged = append(result.Unchanged, user.ID)
+				continue
+			}
+			removed = append(removed, user)
+			result.Changed = append(result.Changed, user.ID)
+		}
+		if len(removed) == 0 {
+			return nil
+		}
+
+		if err := repo.RemoveMembers(role.ID, userIDs(removed)); err != nil {
+			return err
+		}
+		for _, user := range removed {
+			err := publish(repo.Outbox(), events.UserRoleRemovedType, events.UserRoleRemoved{
+				ID:       user.ID,
+				Email:    user.Email,
+				RoleID:   role.ID,
+				RoleName: role.Name,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// findUsers carrega os usuários sem repetições, em ordem de id, ou devolve UnknownUsersError
+// com os que não existem.
+func (uc *RoleUseCase) findUsers(ids []uint64) ([]models.User, error) {
+	seen := make(map[uint64]bool)
+	var unique []uint64
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	users, err := uc.repo.FindUsersByIDs(unique)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[uint64]bool)
+	for _, user := range users {
+		found[user.ID] = true
+	}
+	var missing []uint64
+	for _, id := range unique {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &UnknownUsersError{IDs: missing}
+	}
+
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+func userIDs(users []models.User) []uint64 {
+	ids := make([]uint64, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+	return ids
+}
@@ -2,10 +2,17 @@ package usecases
 
 import (
 	"errors"
+	"events"
+	"fmt"
+	"log"
 	"login-api/internal/repositories"
 	"login-api/models"
+	"time"
 )
 
+// ErrSystemRole indica uma tentativa de renomear ou excluir um papel de sistema.
+var ErrSystemRole = errors.New("system role cannot be renamed or deleted")
+
 type RoleUseCase struct {
 	repo repositories.RoleRepository
 }
@@ -28,32 +35,138 @@ func (uc *RoleUseCase) Create(role *models.Role) error {
 		return errors.New("role already exists")
 	}
 
+	role.IsSystem = false
 	return uc.repo.Create(role)
 }
 
-func (uc *RoleUseCase) Update(id string, role *models.Role) error {
-	_, err := uc.repo.FindByID(id)
+// EnsureSystemRoles cria os papéis de sistema que faltam e marca como de sistema os que já
+// existem (ex.: inseridos pelo database/addData), completando só os metadados vazios.
+func (uc *RoleUseCase) EnsureSystemRoles() error {
+	for _, systemRole := range models.SystemRoles {
+		role, err := uc.repo.FindByName(systemRole.Name)
+		if err != nil {
+			role := systemRole
+			role.IsSystem = true
+			if err := uc.repo.Create(&role); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if role.IsSystem {
+			continue
+		}
+		role.IsSystem = true
+		if role.DisplayName == "" {
+			role.DisplayName = systemRole.DisplayName
+		}
+		if role.Description == "" {
+			role.Description = systemRole.Description
+		}
+		if role.Color == "" {
+			role.Color = systemRole.Color
+		}
+		if role.Icon == "" {
+			role.Icon = systemRole.Icon
+		}
+		if err := uc.repo.Update(role); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Update substitui o papel se ele ainda estiver em `version`.
+func (uc *RoleUseCase) Update(id string, role *models.Role, version uint64) error {
+	existingRole, err := uc.repo.FindByID(id)
 	if err != nil {
 		return errors.New("role not found")
 	}
+	if role.Name != existingRole.Name {
+		if existingRole.IsSystem {
+			return ErrSystemRole
+		}
+		if other, _ := uc.repo.FindByName(role.Name); other != nil {
+			return errors.New("role already exists")
+		}
+	}
 
+	role.ID = existingRole.ID
+	role.IsSystem = existingRole.IsSystem
+	role.CreatedAt = existingRole.CreatedAt
+	role.Version = version
 	return uc.repo.Update(role)
 }
 
-func (uc *RoleUseCase) Delete(id string) error {
-	_, err := uc.repo.FindByID(id)
+// Delete exclui o papel se ele ainda estiver em `version`. Um papel
+// atribuído a usuários só é excluído com options.ReassignTo, que move as atribuições diretas e de
+// grupos para outro papel, ou com options.Force, que as descarta. Tudo acontece numa transação
+// que também grava o role.deleted com os usuários afetados.
+func (uc *RoleUseCase) Delete(id string, version uint64, options models.RoleDeleteOptions) error {
+	existingRole, err := uc.repo.FindByID(id)
 	if err != nil {
 		return errors.New("role not found")
 	}
-
-	count, err := uc.repo.CountUsersByRoleID(id)
-	if err != nil {
-		return err
+	if existingRole.IsSystem {
+		return ErrSystemRole
+	}
+	if options.ReassignTo != "" && options.Force {
+		return errors.New("reassign_to and force cannot be combined")
 	}
 
-	if count > 0 {
-		return errors.New("role is assigned to users")
+	var target *models.Role
+	if options.ReassignTo != "" {
+		target, err = uc.repo.FindByID(options.ReassignTo)
+		if err != nil {
+			return errors.New("reassignment role not found")
+		}
+		if target.ID == existingRole.ID {
+			return errors.New("cannot reassign to the deleted role")
+		}
 	}
 
-	return uc.repo.Delete(id)
+	return uc.repo.Transaction(func(repo repositories.RoleRepository) error {
+		// Papéis dados a grupos também contam: apagá-los tiraria o papel dos membros.
+		count, err := repo.CountAssignments(id)
+		if err != nil {
+			return err
+		}
+		if count > 0 && target == nil && !options.Force {
+			return errors.New("role is assigned to users")
+		}
+
+		affected, err := repo.FindAffectedUserIDs(existingRole.ID)
+		if err != nil {
+			return err
+		}
+
+		event := events.RoleDeleted{
+			ID:        existingRole.ID,
+			Name:      existingRole.Name,
+			Strategy:  events.RoleDeleteUnassigned,
+			UserIDs:   affected,
+			DeletedBy: options.DeletedBy,
+			DeletedAt: time.Now(),
+		}
+		if event.UserIDs == nil {
+			event.UserIDs = []uint64{}
+		}
+		switch {
+		case target != nil:
+			reason := fmt.Sprintf("reassigned from deleted role %s", existingRole.Name)
+			if err := repo.ReassignMembers(existingRole.ID, target.ID, options.DeletedBy, reason); err != nil {
+				return err
+			}
+			event.Strategy = events.RoleDeleteReassign
+			event.ReassignedTo = &events.Role{ID: target.ID, Name: target.Name}
+		case options.Force:
+			event.Strategy = events.RoleDeleteForce
+		}
+
+		if err := repo.Delete(id, version); err != nil {
+			return err
+		}
+		log.Printf("Role %s (%d) deleted by %s: %s, %d users affected", existingRole.Name, existingRole.ID, options.DeletedBy, event.Strategy, len(affected))
+		return publish(repo.Outbox(), events.RoleDeletedType, event)
+	})
 }
@@ -0,0 +1,146 @@
+package usecases
+
+import (
+	"errors"
+	"events"
+	"login-api/internal/repositories"
+	"login-api/models"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+type fakeOutbox struct {
+	events []models.OutboxEvent
+}
+
+func (o *fakeOutbox) Add(event *models.OutboxEvent) error {
+	o.events = append(o.events, *event)
+	return nil
+}
+
+func (o *fakeOutbox) CorrelationID() string {
+	return "correlation"
+}
+
+// fakeRoleRepository guarda os papéis e quem os tem, direto (userRoles) ou por grupo
+// (groupRoles, com os membros de cada grupo em groupMembers).
+type fakeRoleRepository struct {
+	repositories.RoleRepository
+	roles        map[uint64]*models.Role
+	userRoles    map[uint64][]uint64
+	groupRoles   map[uint64][]uint64
+	groupMembers map[uint64][]uint64
+	calls        []string
+	outbox       *fakeOutbox
+}
+
+func newFakeRoleRepository(roles ...models.Role) *fakeRoleRepository {
+	repo := &fakeRoleRepository{
+		roles:        make(map[uint64]*models.Role),
+		userRoles:    make(map[uint64][]uint64),
+		groupRoles:   make(map[uint64][]uint64),
+		groupMembers: make(map[uint64][]uint64),
+		outbox:       &fakeOutbox{},
+	}
+	for i := range roles {
+		repo.roles[roles[i].ID] = &roles[i]
+	}
+	return repo
+}
+
+func (r *fakeRoleRepository) FindByID(id string) (*models.Role, error) {
+	roleID, _ := strconv.ParseUint(id, 10, 64)
+	role, ok := r.roles[roleID]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	copied := *role
+	return &copied, nil
+}
+
+func (r *fakeRoleRepository) CountAssignments(id string) (int64, error) {
+	roleID, _ := strconv.ParseUint(id, 10, 64)
+	return int64(len(r.userRoles[roleID]) + len(r.groupRoles[roleID])), nil
+}
+
+func (r *fakeRoleRepository) FindAffectedUserIDs(roleID uint64) ([]uint64, error) {
+	ids := append([]uint64{}, r.userRoles[roleID]...)
+	for _, groupID := range r.groupRoles[roleID] {
+		ids = append(ids, r.groupMembers[groupID]...)
+	}
+	return ids, nil
+}
+
+func (r *fakeRoleRepository) ReassignMembers(fromID, toID uint64, grantedBy, reason string) error {
+	r.calls = append(r.calls, "reassign")
+	r.userRoles[toID] = append(r.userRoles[toID], r.userRoles[fromID]...)
+	r.groupRoles[toID] = append(r.groupRoles[toID], r.groupRoles[fromID]...)
+	return nil
+}
+
+func (r *fakeRoleRepository) Delete(id string, version uint64) error {
+	r.calls = append(r.calls, "delete")
+	roleID, _ := strconv.ParseUint(id, 10, 64)
+	delete(r.roles, roleID)
+	delete(r.userRoles, roleID)
+	delete(r.groupRoles, roleID)
+	return nil
+}
+
+func (r *fakeRoleRepository) Transaction(fn func(repo repositories.RoleRepository) error) error {
+	return fn(r)
+}
+
+func (r *fakeRoleRepository) Outbox() repositories.OutboxRepository {
+	return r.outbox
+}
+
+func TestDeleteBlocksRoleAssignedToGroups(t *testing.T) {
+	repo := newFakeRoleRepository(models.Role{ID: 5, Name: "Auditor", Version: 1})
+	repo.groupRoles[5] = []uint64{2}
+	repo.groupMembers[2] = []uint64{7, 8}
+
+	err := NewRoleUseCase(repo).Delete("5", 1, models.RoleDeleteOptions{DeletedBy: "admin@example.com"})
+	if err == nil || err.Error() != "role is assigned to users" {
+		t.Fatalf("Delete: err = %v, want role is assigned to users", err)
+	}
+	if len(repo.calls) != 0 || len(repo.outbox.events) != 0 {
+		t.Errorf("calls = %v, events = %d; want nothing done", repo.calls, len(repo.outbox.events))
+	}
+}
+
+func TestDeleteReassignsDirectAndGroupMembers(t *testing.T) {
+	repo := newFakeRoleRepository(models.Role{ID: 5, Name: "Auditor", Version: 1}, models.Role{ID: 6, Name: "Watcher", Version: 1})
+	repo.userRoles[5] = []uint64{3}
+	repo.groupRoles[5] = []uint64{2}
+	repo.groupMembers[2] = []uint64{7}
+
+	options := models.RoleDeleteOptions{ReassignTo: "6", DeletedBy: "admin@example.com"}
+	if err := NewRoleUseCase(repo).Delete("5", 1, options); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	if !reflect.DeepEqual(repo.calls, []string{"reassign", "delete"}) {
+		t.Errorf("calls = %v", repo.calls)
+	}
+	if !reflect.DeepEqual(repo.userRoles[6], []uint64{3}) || !reflect.DeepEqual(repo.groupRoles[6], []uint64{2}) {
+		t.Errorf("Watcher members = %v, groups = %v", repo.userRoles[6], repo.groupRoles[6])
+	}
+
+	if len(repo.outbox.events) != 1 {
+		t.Fatalf("events = %d, want one role.deleted", len(repo.outbox.events))
+	}
+	envelope, err := events.Decode(repo.outbox.events[0].RoutingKey, []byte(repo.outbox.events[0].Payload))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	var event events.RoleDeleted
+	if err := envelope.DataAs(&event); err != nil {
+		t.Fatalf("DataAs: %v", err)
+	}
+	// O user-microservice sincroniza o gateway de todos esses usuários ao receber o evento.
+	if event.Strategy != events.RoleDeleteReassign || !reflect.DeepEqual(event.UserIDs, []uint64{3, 7}) {
+		t.Errorf("event = %+v", event)
+	}
+}
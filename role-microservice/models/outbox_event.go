@@ -0,0 +1,7 @@
+package models
+
+import "shared/outbox"
+
+// OutboxEvent é uma linha da outbox do user-microservice, no mesmo banco: os eventos gravados
+// aqui na transação da mudança são publicados pelo relay de lá.
+type OutboxEvent = outbox.Event
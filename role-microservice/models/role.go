@@ -1,6 +1,51 @@
 package models
 
+import "time"
+
 type Role struct {
-	ID   uint64 `gorm:"primaryKey;autoIncrement;type:integer"`
-	Name string `json:"name"`
+	ID          uint64    `gorm:"primaryKey;autoIncrement;type:integer"`
+	Name        string    `json:"name"`
+	DisplayName string    `json:"display_name"`
+	Description string    `json:"description"`
+	Color       string    `json:"color"`
+	Icon        string    `json:"icon"`
+	IsSystem    bool      `json:"is_system" gorm:"not null;default:false"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Version     uint64    `json:"version" gorm:"not null;default:1"`
+}
+
+// RoleRequest são os campos que o cliente pode definir; is_system só é dado pela inicialização.
+type RoleRequest struct {
+	Name        string `json:"name" binding:"required,max=50"`
+	DisplayName string `json:"display_name" binding:"max=100"`
+	Description string `json:"description" binding:"max=500"`
+	Color       string `json:"color" binding:"omitempty,hexcolor"`
+	Icon        string `json:"icon" binding:"max=50"`
+}
+
+func (r RoleRequest) Role() Role {
+	return Role{
+		Name:        r.Name,
+		DisplayName: r.DisplayName,
+		Description: r.Description,
+		Color:       r.Color,
+		Icon:        r.Icon,
+	}
+}
+
+// SystemRoles são usados nas rotas dos serviços, então existem sempre: são criados (ou
+// marcados como de sistema) na inicialização e não podem ser renomeados nem excluídos.
+var SystemRoles = []Role{
+	{Name: "Admin", DisplayName: "Administrator", Description: "Full access, including users, roles and groups", Color: "#d32f2f", Icon: "shield"},
+	{Name: "Modifier", DisplayName: "Modifier", Description: "Can create, edit and delete items", Color: "#f57c00", Icon: "edit"},
+	{Name: "Watcher", DisplayName: "Watcher", Description: "Read-only access to items", Color: "#1976d2", Icon: "eye"},
+}
+
+// RoleDeleteOptions diz o que fazer com as atribuições do papel excluído: movê-las para
+// ReassignTo ou, com Force, descartá-las. Sem nenhum dos dois, papéis atribuídos não são excluídos.
+type RoleDeleteOptions struct {
+	ReassignTo string
+	Force      bool
+	DeletedBy  string
 }
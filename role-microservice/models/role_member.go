@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// User é a parte da tabela users (do user-microservice) que o serviço de papéis lê.
+type User struct {
+	ID        uint64 `gorm:"primaryKey;type:integer"`
+	Name      string
+	Email     string
+	DeletedAt gorm.DeletedAt
+}
+
+// RoleMember é uma atribuição direta do papel (linha de user_roles) com os dados do usuário.
+type RoleMember struct {
+	UserID     uint64     `json:"user_id"`
+	Name       string     `json:"name"`
+	Email      string     `json:"email"`
+	ValidFrom  *time.Time `json:"valid_from,omitempty"`
+	ValidUntil *time.Time `json:"valid_until,omitempty"`
+	GrantedBy  string     `json:"granted_by,omitempty"`
+	Reason     string     `json:"reason,omitempty"`
+}
+
+// RoleGrant são os dados opcionais de uma atribuição, como no user-microservice.
+type RoleGrant struct {
+	ValidFrom  *time.Time `json:"valid_from"`
+	ValidUntil *time.Time `json:"valid_until"`
+	Reason     string     `json:"reason"`
+	GrantedBy  string     `json:"-"`
+}
+
+// RoleMembersRequest adiciona o papel a vários usuários, com prazo e motivo opcionais.
+type RoleMembersRequest struct {
+	UserIDs []uint64 `json:"user_ids" binding:"required,min=1,max=500"`
+	RoleGrant
+}
+
+type RoleMembersRemoveRequest struct {
+	UserIDs []uint64 `json:"user_ids" binding:"required,min=1,max=500"`
+}
+
+// RoleMembersResult separa os usuários alterados dos que já estavam no estado pedido.
+type RoleMembersResult struct {
+	Changed   []uint64 `json:"changed"`
+	Unchanged []uint64 `json:"unchanged"`
+}
@@ -0,0 +1,96 @@
+// Package etag implementa o controle de concorrência otimista por versão usado pelos serviços:
+// o ETag é a versão do registro, o If-None-Match evita reenviar o que o cliente já tem e o
+// If-Match é obrigatório nas alterações.
+package etag
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tag formata a versão do recurso como um ETag forte.
+func Tag(version uint64) string {
+	return VariantTag(version, "")
+}
+
+// VariantTag formata o ETag de uma representação da versão: representações diferentes do mesmo
+// registro (ex.: ?fields=) não podem ter o mesmo ETag forte, então a variante vai depois da
+// versão, como em "3;fields=id,name". Sem variante é o mesmo que Tag.
+func VariantTag(version uint64, variant string) string {
+	tag := strconv.FormatUint(version, 10)
+	if variant != "" {
+		tag += ";" + variant
+	}
+	return `"` + tag + `"`
+}
+
+// NotModified envia o ETag da versão atual na representação pedida (variant) e responde 304
+// quando o If-None-Match do cliente já corresponde a ele.
+func NotModified(c *gin.Context, version uint64, variant string) bool {
+	tag := VariantTag(version, variant)
+	c.Header("ETag", tag)
+
+	for _, candidate := range splitTags(c.GetHeader("If-None-Match")) {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == tag {
+			c.Status(304)
+			return true
+		}
+	}
+	return false
+}
+
+// IfMatch lê a versão esperada do If-Match, ignorando a variante da representação lida. Sem o
+// header, ou com "*", que sobrescreveria qualquer versão, responde 428; com um valor que não é
+// uma versão, 412.
+func IfMatch(c *gin.Context) (uint64, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		c.JSON(428, gin.H{
+			"error": "If-Match header is required",
+		})
+		return 0, false
+	}
+	if header == "*" {
+		c.JSON(428, gin.H{
+			"error": "If-Match must be the ETag of the version being changed",
+		})
+		return 0, false
+	}
+
+	value, _, _ := strings.Cut(strings.Trim(header, `"`), ";")
+	version, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || version == 0 || !strings.HasPrefix(header, `"`) {
+		PreconditionFailed(c)
+		return 0, false
+	}
+	return version, true
+}
+
+// PreconditionFailed responde 412, usado também quando a gravação encontra outra versão.
+func PreconditionFailed(c *gin.Context) {
+	c.JSON(412, gin.H{
+		"error": "Resource was modified since it was read; fetch it again and retry",
+	})
+}
+
+// splitTags separa a lista de ETags de um header; vírgulas entre aspas fazem parte do ETag.
+func splitTags(header string) []string {
+	var tags []string
+	quoted := false
+	start := 0
+	for i, char := range header {
+		switch char {
+		case '"':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				tags = append(tags, header[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(tags, header[start:])
+}
@@ -0,0 +1,77 @@
+package etag
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"shared/representation"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newContext(header, value string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/user/1", nil)
+	if value != "" {
+		c.Request.Header.Set(header, value)
+	}
+	return c, w
+}
+
+func TestNotModifiedDependsOnRepresentation(t *testing.T) {
+	full := representation.Options{}
+	sparse, _ := representation.ParseOptions("name,id", "", []string{"id", "name"}, nil)
+	reordered, _ := representation.ParseOptions("id,name", "", []string{"id", "name"}, nil)
+	withRoles, _ := representation.ParseOptions("", "roles", []string{"id"}, []string{"roles"})
+
+	sparseTag := VariantTag(3, sparse.Key())
+	if sparseTag != `"3;fields=id,name"` {
+		t.Fatalf("sparse tag = %s", sparseTag)
+	}
+
+	for _, tc := range []struct {
+		name        string
+		ifNoneMatch string
+		options     representation.Options
+		want        bool
+	}{
+		{"same representation", sparseTag, reordered, true},
+		{"list of tags", `"2", ` + sparseTag, sparse, true},
+		{"full representation", sparseTag, full, false},
+		{"with roles", Tag(3), withRoles, false},
+		{"weak comparison", "W/" + Tag(3), full, true},
+		{"older version", VariantTag(2, sparse.Key()), sparse, false},
+	} {
+		c, _ := newContext("If-None-Match", tc.ifNoneMatch)
+		if got := NotModified(c, 3, tc.options.Key()); got != tc.want {
+			t.Errorf("%s: NotModified = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestIfMatchReadsTheVersionOfAnyRepresentation(t *testing.T) {
+	for _, tc := range []struct {
+		ifMatch string
+		version uint64
+		ok      bool
+		status  int
+	}{
+		{`"3"`, 3, true, 0},
+		{`"3;fields=id,name"`, 3, true, 0},
+		{"", 0, false, 428},
+		{"*", 0, false, 428},
+		{"3", 0, false, 412},
+		{`"abc"`, 0, false, 412},
+	} {
+		c, w := newContext("If-Match", tc.ifMatch)
+		version, ok := IfMatch(c)
+		if version != tc.version || ok != tc.ok {
+			t.Errorf("If-Match %q: got (%d, %v), want (%d, %v)", tc.ifMatch, version, ok, tc.version, tc.ok)
+		}
+		if !tc.ok && w.Code != tc.status {
+			t.Errorf("If-Match %q: status = %d, want %d", tc.ifMatch, w.Code, tc.status)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+// Package gatewayauth lê a identidade que o Kong já validou, para os serviços rodando
+// com AUTH_MODE=trusted-gateway.
+package gatewayauth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrUntrustedSource     = errors.New("request did not come from a trusted gateway")
+	ErrMissingIdentity     = errors.New("gateway did not forward a consumer identity")
+	ErrInvalidSignature    = errors.New("gateway signature is invalid")
+	ErrReplayedSignature   = errors.New("gateway signature was already used")
+	maxGatewaySignatureAge = 5 * time.Minute
+)
+
+// TrustedGateway lê a identidade já validada pelo Kong a partir dos headers da
+// requisição, em vez de verificar o JWT novamente. Os headers só são aceitos
+// quando a conexão vem de um IP liberado ou quando trazem uma assinatura HMAC válida.
+type TrustedGateway struct {
+	TrustedNetworks []*net.IPNet
+	SharedSecret    string
+	UsernameHeader  string
+	UserIDHeader    string
+	RolesHeaders    []string
+	SignatureHeader string
+	TimestampHeader string
+	NonceHeader     string
+
+	mu         sync.Mutex
+	seenNonces map[string]time.Time
+}
+
+type GatewayIdentity struct {
+	Username string
+	UserID   string
+	Roles    []string
+}
+
+// NewTrustedGatewayFromEnv usa TRUSTED_GATEWAY_IPS (IPs ou CIDRs separados por vírgula),
+// GATEWAY_HMAC_SECRET e GATEWAY_ROLES_HEADERS (padrão X-Consumer-Groups, enviado pelo plugin acl).
+//
+// Só o plugin acl sobrescreve X-Consumer-Groups; sem ele o header enviado pelo cliente chega
+// intacto ao serviço. Por isso o modo exige GATEWAY_ACL_PLUGIN=true, que confirma que o kong.yaml
+// foi gerado com -acl-groups.
+func NewTrustedGatewayFromEnv() (*TrustedGateway, error) {
+	if os.Getenv("GATEWAY_ACL_PLUGIN") != "true" {
+		return nil, errors.New("trusted gateway mode reads roles from headers that only the Kong acl plugin overwrites; generate kong.yaml with -acl-groups and set GATEWAY_ACL_PLUGIN=true")
+	}
+
+	gateway := &TrustedGateway{
+		SharedSecret:    os.Getenv("GATEWAY_HMAC_SECRET"),
+		UsernameHeader:  "X-Consumer-Username",
+		UserIDHeader:    "X-Authenticated-Userid",
+		RolesHeaders:    []string{"X-Consumer-Groups"},
+		SignatureHeader: "X-Gateway-Signature",
+		TimestampHeader: "X-Gateway-Timestamp",
+		NonceHeader:     "X-Gateway-Nonce",
+	}
+
+	if headers := os.Getenv("GATEWAY_ROLES_HEADERS"); headers != "" {
+		gateway.RolesHeaders = splitList(headers)
+	}
+
+	for _, entry := range splitList(os.Getenv("TRUSTED_GATEWAY_IPS")) {
+		if !strings.Contains(entry, "/") {
+			if strings.Contains(entry, ":") {
+				entry += "/128"
+			} else {
+				entry += "/32"
+			}
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, err
+		}
+		gateway.TrustedNetworks = append(gateway.TrustedNetworks, network)
+	}
+
+	if len(gateway.TrustedNetworks) == 0 && gateway.SharedSecret == "" {
+		return nil, errors.New("trusted gateway mode needs TRUSTED_GATEWAY_IPS or GATEWAY_HMAC_SECRET")
+	}
+	return gateway, nil
+}
+
+// Identify só confia nos headers se a requisição veio do gateway.
+func (g *TrustedGateway) Identify(r *http.Request) (*GatewayIdentity, error) {
+	identity := &GatewayIdentity{
+		Username: r.Header.Get(g.UsernameHeader),
+		UserID:   r.Header.Get(g.UserIDHeader),
+	}
+	var rawRoles []string
+	for _, header := range g.RolesHeaders {
+		if value := r.Header.Get(header); value != "" {
+			rawRoles = append(rawRoles, value)
+			identity.Roles = append(identity.Roles, splitList(value)...)
+		}
+	}
+
+	if !g.fromTrustedNetwork(r) {
+		if g.SharedSecret == "" {
+			return nil, ErrUntrustedSource
+		}
+		if err := g.verifySignature(r, identity, strings.Join(rawRoles, ",")); err != nil {
+			return nil, err
+		}
+	}
+
+	if identity.Username == "" {
+		// Plugins como o oauth2 só enviam X-Authenticated-Userid.
+		identity.Username = identity.UserID
+	}
+	if identity.Username == "" {
+		return nil, ErrMissingIdentity
+	}
+	return identity, nil
+}
+
+// Sign gera a assinatura esperada em SignatureHeader; útil para o plugin do gateway e para testes.
+// Método e caminho (com a query) entram na assinatura, então ela não vale para outra rota, e o
+// nonce impede que a mesma requisição seja repetida dentro da janela de 5 minutos.
+func (g *TrustedGateway) Sign(method, requestURI, username, userID, roles, nonce string, timestamp time.Time) string {
+	mac := hmac.New(sha256.New, []byte(g.SharedSecret))
+	mac.Write([]byte(strings.Join([]string{
+		method,
+		requestURI,
+		username,
+		userID,
+		roles,
+		nonce,
+		strconv.FormatInt(timestamp.Unix(), 10),
+	}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (g *TrustedGateway) verifySignature(r *http.Request, identity *GatewayIdentity, roles string) error {
+	signature := r.Header.Get(g.SignatureHeader)
+	nonce := r.Header.Get(g.NonceHeader)
+	unix, err := strconv.ParseInt(r.Header.Get(g.TimestampHeader), 10, 64)
+	if signature == "" || nonce == "" || err != nil {
+		return ErrUntrustedSource
+	}
+
+	timestamp := time.Unix(unix, 0)
+	if age := time.Since(timestamp); age > maxGatewaySignatureAge || age < -maxGatewaySignatureAge {
+		return ErrInvalidSignature
+	}
+
+	expected := g.Sign(r.Method, r.URL.RequestURI(), identity.Username, identity.UserID, roles, nonce, timestamp)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return ErrInvalidSignature
+	}
+	return g.useNonce(nonce, timestamp)
+}
+
+// useNonce aceita cada nonce uma única vez enquanto a assinatura estiver dentro da janela;
+// depois disso a própria idade do timestamp já a recusa e o nonce pode ser esquecido.
+func (g *TrustedGateway) useNonce(nonce string, timestamp time.Time) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	if g.seenNonces == nil {
+		g.seenNonces = make(map[string]time.Time)
+	}
+	for seen, expiresAt := range g.seenNonces {
+		if now.After(expiresAt) {
+			delete(g.seenNonces, seen)
+		}
+	}
+	if _, seen := g.seenNonces[nonce]; seen {
+		return ErrReplayedSignature
+	}
+	g.seenNonces[nonce] = timestamp.Add(maxGatewaySignatureAge)
+	return nil
+}
+
+// Usa o endereço da conexão, nunca X-Forwarded-For, que pode ser forjado pelo cliente.
+func (g *TrustedGateway) fromTrustedNetwork(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, network := range g.TrustedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,120 @@
+package gatewayauth
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func newSignedGateway() *TrustedGateway {
+	return &TrustedGateway{
+		SharedSecret:    "secret",
+		UsernameHeader:  "X-Consumer-Username",
+		UserIDHeader:    "X-Authenticated-Userid",
+		RolesHeaders:    []string{"X-Consumer-Groups"},
+		SignatureHeader: "X-Gateway-Signature",
+		TimestampHeader: "X-Gateway-Timestamp",
+		NonceHeader:     "X-Gateway-Nonce",
+	}
+}
+
+func signedRequest(g *TrustedGateway, method, target, nonce string) *http.Request {
+	now := time.Now()
+	r := httptest.NewRequest(method, target, nil)
+	r.Header.Set("X-Consumer-Username", "ana@example.com")
+	r.Header.Set("X-Consumer-Groups", "Admin, Watcher")
+	r.Header.Set("X-Gateway-Nonce", nonce)
+	r.Header.Set("X-Gateway-Timestamp", strconv.FormatInt(now.Unix(), 10))
+	r.Header.Set("X-Gateway-Signature", g.Sign(method, target, "ana@example.com", "", "Admin, Watcher", nonce, now))
+	return r
+}
+
+func TestNewTrustedGatewayFromEnvRequiresACLPlugin(t *testing.T) {
+	t.Setenv("GATEWAY_HMAC_SECRET", "secret")
+	t.Setenv("GATEWAY_ACL_PLUGIN", "")
+	if _, err := NewTrustedGatewayFromEnv(); err == nil {
+		t.Fatal("trusted mode started without the acl plugin")
+	}
+
+	t.Setenv("GATEWAY_ACL_PLUGIN", "true")
+	if _, err := NewTrustedGatewayFromEnv(); err != nil {
+		t.Fatalf("NewTrustedGatewayFromEnv: %v", err)
+	}
+}
+
+func TestIdentifySignedRequest(t *testing.T) {
+	g := newSignedGateway()
+
+	identity, err := g.Identify(signedRequest(g, "GET", "/user/1?fields=name", "n-1"))
+	if err != nil {
+		t.Fatalf("Identify: %v", err)
+	}
+	if identity.Username != "ana@example.com" || !reflect.DeepEqual(identity.Roles, []string{"Admin", "Watcher"}) {
+		t.Errorf("identity = %+v", identity)
+	}
+}
+
+func TestIdentifyRejectsSignatureForAnotherRequest(t *testing.T) {
+	g := newSignedGateway()
+
+	for name, change := range map[string]func(r *http.Request){
+		"method": func(r *http.Request) { r.Method = "DELETE" },
+		"path":   func(r *http.Request) { r.URL.Path = "/user/2" },
+		"query":  func(r *http.Request) { r.URL.RawQuery = "fields=password" },
+		"roles":  func(r *http.Request) { r.Header.Set("X-Consumer-Groups", "Admin, Watcher, Modifier") },
+	} {
+		r := signedRequest(g, "GET", "/user/1?fields=name", "n-"+name)
+		change(r)
+		if _, err := g.Identify(r); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s changed: err = %v, want ErrInvalidSignature", name, err)
+		}
+	}
+}
+
+func TestIdentifyRejectsReplayedNonce(t *testing.T) {
+	g := newSignedGateway()
+
+	if _, err := g.Identify(signedRequest(g, "GET", "/user/1", "n-1")); err != nil {
+		t.Fatalf("Identify: %v", err)
+	}
+	if _, err := g.Identify(signedRequest(g, "GET", "/user/1", "n-1")); !errors.Is(err, ErrReplayedSignature) {
+		t.Errorf("replay: err = %v, want ErrReplayedSignature", err)
+	}
+}
+
+func TestIdentifyRequiresNonce(t *testing.T) {
+	g := newSignedGateway()
+
+	r := signedRequest(g, "GET", "/user/1", "")
+	if _, err := g.Identify(r); !errors.Is(err, ErrUntrustedSource) {
+		t.Errorf("err = %v, want ErrUntrustedSource", err)
+	}
+}
+
+func TestIdentifyTrustedNetwork(t *testing.T) {
+	_, network, _ := net.ParseCIDR("10.0.0.0/8")
+	g := &TrustedGateway{
+		TrustedNetworks: []*net.IPNet{network},
+		UsernameHeader:  "X-Consumer-Username",
+		RolesHeaders:    []string{"X-Consumer-Groups"},
+	}
+
+	r := httptest.NewRequest("GET", "/user/1", nil)
+	r.Header.Set("X-Consumer-Username", "ana@example.com")
+	r.RemoteAddr = "10.1.2.3:5000"
+	if _, err := g.Identify(r); err != nil {
+		t.Errorf("trusted network: %v", err)
+	}
+
+	// X-Forwarded-For não conta.
+	r.RemoteAddr = "203.0.113.9:5000"
+	r.Header.Set("X-Forwarded-For", "10.1.2.3")
+	if _, err := g.Identify(r); !errors.Is(err, ErrUntrustedSource) {
+		t.Errorf("untrusted network: err = %v, want ErrUntrustedSource", err)
+	}
+}
@@ -0,0 +1,51 @@
+// Package outbox grava eventos na tabela outbox_events, compartilhada pelos serviços no mesmo
+// banco: cada serviço grava na transação da própria mudança e o relay do user-microservice
+// faz a entrega ao RabbitMQ.
+package outbox
+
+import (
+	"encoding/json"
+	"events"
+	"time"
+)
+
+// Event é uma linha da outbox. SentAt nulo indica que ainda não foi confirmado pelo broker.
+type Event struct {
+	ID            uint64 `gorm:"primaryKey;autoIncrement;type:integer"`
+	RoutingKey    string
+	Payload       string
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time  `gorm:"index"`
+	SentAt        *time.Time `gorm:"index"`
+	CreatedAt     time.Time
+}
+
+func (Event) TableName() string {
+	return "outbox_events"
+}
+
+// Writer é a outbox de um repositório; a da transação garante que o evento só exista se a
+// mudança de estado for gravada.
+type Writer interface {
+	Add(event *Event) error
+	// CorrelationID identifica os eventos gravados pela mesma instância, isto é, pela mesma transação.
+	CorrelationID() string
+}
+
+// Publish grava o evento, já no envelope CloudEvents com o source do serviço, em w. O tipo
+// do evento também é a routing key.
+func Publish(w Writer, source, eventType string, data interface{}) error {
+	envelope, err := events.New(source, eventType, w.CorrelationID(), data)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+	return w.Add(&Event{
+		RoutingKey: eventType,
+		Payload:    string(payload),
+	})
+}
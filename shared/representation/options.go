@@ -0,0 +1,123 @@
+// Package representation trata das escolhas do cliente sobre a representação de um recurso,
+// ?fields= e ?include=, iguais em todos os serviços.
+package representation
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// Options são as escolhas do cliente sobre a representação: ?fields= e ?include=.
+type Options struct {
+	Fields  []string
+	Include map[string]bool
+}
+
+// ParseOptions valida ?fields= e ?include= (listas separadas por vírgula) contra os
+// campos e expansões aceitos pelo recurso. Pedir um campo expansível o inclui.
+func ParseOptions(fields, include string, allowedFields, allowedIncludes []string) (Options, map[string]string) {
+	options := Options{Include: make(map[string]bool)}
+	errorMessages := make(map[string]string)
+
+	for _, name := range splitList(include) {
+		if !contains(allowedIncludes, name) {
+			errorMessages["include"] = "Include must be one of " + strings.Join(allowedIncludes, ", ")
+			if len(allowedIncludes) == 0 {
+				errorMessages["include"] = "Include is not supported"
+			}
+			continue
+		}
+		options.Include[name] = true
+	}
+
+	for _, name := range splitList(fields) {
+		if !contains(allowedFields, name) && !contains(allowedIncludes, name) {
+			errorMessages["fields"] = "Unknown field " + name
+			continue
+		}
+		if contains(allowedIncludes, name) {
+			options.Include[name] = true
+		}
+		options.Fields = append(options.Fields, name)
+	}
+
+	return options, errorMessages
+}
+
+// Key identifica a representação escolhida, para compor o ETag (ver etag.VariantTag). É vazia
+// na representação padrão e não depende da ordem dos nomes em ?fields= e ?include=.
+func (o Options) Key() string {
+	fields := append([]string(nil), o.Fields...)
+	sort.Strings(fields)
+	var include []string
+	for name, ok := range o.Include {
+		if ok {
+			include = append(include, name)
+		}
+	}
+	sort.Strings(include)
+
+	var parts []string
+	if len(fields) > 0 {
+		parts = append(parts, "fields="+strings.Join(fields, ","))
+	}
+	if len(include) > 0 {
+		parts = append(parts, "include="+strings.Join(include, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Sparse reduz a representação (objeto ou lista) aos campos pedidos em ?fields=;
+// sem ?fields= devolve v inalterado.
+func (o Options) Sparse(v interface{}) interface{} {
+	if len(o.Fields) == 0 {
+		return v
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var list []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &list); err == nil {
+		for i := range list {
+			list[i] = o.pick(list[i])
+		}
+		return list
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return v
+	}
+	return o.pick(object)
+}
+
+func (o Options) pick(object map[string]json.RawMessage) map[string]json.RawMessage {
+	picked := make(map[string]json.RawMessage, len(o.Fields))
+	for _, name := range o.Fields {
+		if value, ok := object[name]; ok {
+			picked[name] = value
+		}
+	}
+	return picked
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
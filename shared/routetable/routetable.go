@@ -0,0 +1,67 @@
+// Package routetable gera a tabela de rotas do Gin em JSON usada pelo kong-config-generator
+// (go run ./cmd/routes em cada serviço).
+//
+// Uma rota é considerada pública quando uma requisição anônima não é
+// rejeitada com 401 pelo middleware de autenticação.
+package routetable
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Route é uma entrada da tabela de rotas.
+type Route struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Public bool   `json:"public"`
+}
+
+// NewRouter cria o router para registrar as rotas do serviço. Os controllers são nil, então
+// um handler alcançado pela sonda entra em pânico; o recovery responde 500 sem log.
+func NewRouter() *gin.Engine {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.Use(gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, err any) {
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}))
+	return router
+}
+
+// Routes lista as rotas do router, marcando as públicas.
+func Routes(router *gin.Engine) []Route {
+	var routes []Route
+	for _, route := range router.Routes() {
+		routes = append(routes, Route{
+			Method: route.Method,
+			Path:   route.Path,
+			Public: isPublic(router, route.Method, route.Path),
+		})
+	}
+	return routes
+}
+
+// Write grava as rotas em w no formato lido pelo kong-config-generator.
+func Write(w io.Writer, router *gin.Engine) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(Routes(router))
+}
+
+func isPublic(router *gin.Engine, method, path string) bool {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "probe"
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(method, strings.Join(segments, "/"), nil))
+	return recorder.Code != http.StatusUnauthorized
+}
@@ -0,0 +1,76 @@
+// Importa usuários de um arquivo CSV ou JSON, com as mesmas validações do POST /user/import.
+//
+//	go run ./cmd/import-users -file clientes.csv -dry-run
+//	go run ./cmd/import-users -file clientes.json
+//
+// Os usuários são criados como pending e recebem um convite para definir a senha; os eventos
+// user.invited ficam na outbox e são publicados pelo serviço em execução.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"log"
+	config "login-api/internal/config"
+	"login-api/internal/gateway"
+	"login-api/internal/repositories"
+	"login-api/internal/usecases"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	file := flag.String("file", "", "CSV or JSON file to import")
+	format := flag.String("format", "", "csv or json (default: file extension)")
+	dryRun := flag.Bool("dry-run", false, "only validate the file")
+	invitedBy := flag.String("invited-by", "import-users", "who is recorded as having sent the invitations")
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*file)), ".")
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		log.Fatalf("Failed to read %s: %v", *file, err)
+	}
+	rows, err := usecases.ParseUserImport(data, *format)
+	if err != nil {
+		log.Fatalf("Failed to parse %s: %v", *file, err)
+	}
+
+	godotenv.Load()
+	db := config.Connect()
+	var provisioner usecases.ConsumerProvisioner
+	if kongAdminURL := os.Getenv("KONG_ADMIN_URL"); kongAdminURL != "" {
+		provisioner = gateway.NewKongProvisioner(gateway.NewKongAdminClient(kongAdminURL), os.Getenv("SECRET_KEY"))
+	}
+	invitationConfig, err := config.NewInvitationConfig()
+	if err != nil {
+		log.Fatalf("Invalid invitation configuration: %v", err)
+	}
+	userRepo := repositories.NewGormUserRepository(db)
+	userUseCase := usecases.NewUserUseCase(userRepo, provisioner)
+	invitationUseCase := usecases.NewInvitationUseCase(repositories.NewGormInvitationRepository(db), userRepo, userUseCase, invitationConfig)
+
+	result, err := invitationUseCase.Import(rows, *dryRun, *invitedBy)
+	if result != nil {
+		report, _ := json.MarshalIndent(result, "", "  ")
+		os.Stdout.Write(append(report, '\n'))
+	}
+	if errors.Is(err, usecases.ErrImportHasErrors) {
+		log.Printf("Import has invalid rows, no user was created")
+		os.Exit(1)
+	}
+	if err != nil {
+		log.Fatalf("Failed to import users: %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+// Reconcilia os consumers do Kong com os usuários do banco.
+//
+//	go run ./cmd/kong-sync            aplica as correções
+//	go run ./cmd/kong-sync -dry-run   apenas mostra a divergência
+//
+// Usa KONG_ADMIN_URL (padrão http://localhost:8001), então pode ser apontado
+// para uma Admin API falsa rodando localmente.
+package main
+
+import (
+	"flag"
+	"log"
+	config "login-api/internal/config"
+	"login-api/internal/gateway"
+	"login-api/internal/repositories"
+	"login-api/internal/usecases"
+	"os"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "only report drift, do not change Kong")
+	flag.Parse()
+
+	godotenv.Load()
+	kongAdminURL := os.Getenv("KONG_ADMIN_URL")
+	if kongAdminURL == "" {
+		kongAdminURL = "http://localhost:8001"
+	}
+
+	db := config.Connect()
+	// Mesma regra do provisionamento: só usuários ativos, com os papéis vigentes diretos e de grupos.
+	users, err := usecases.NewUserUseCase(repositories.NewGormUserRepository(db), nil).GatewayUsers()
+	if err != nil {
+		log.Fatalf("Failed to load users: %v", err)
+	}
+
+	provisioner := gateway.NewKongProvisioner(gateway.NewKongAdminClient(kongAdminURL), os.Getenv("SECRET_KEY"))
+	report, err := provisioner.Reconcile(users, *dryRun)
+	if err != nil {
+		log.Fatalf("Failed to reconcile Kong consumers: %v", err)
+	}
+
+	log.Printf("Users checked: %d, provisioned: %d", len(users), report.Provisioned)
+	for _, username := range report.Missing {
+		log.Printf("Missing consumer (would create): %s", username)
+	}
+	for _, drift := range report.Drift {
+		log.Printf("Consumer out of date (would update): %s", drift)
+	}
+	for _, username := range report.Deprovisioned {
+		if *dryRun {
+			log.Printf("Inactive user consumer (would delete): %s", username)
+		} else {
+			log.Printf("Inactive user consumer deleted: %s", username)
+		}
+	}
+	for _, orphan := range report.Orphans {
+		if *dryRun {
+			log.Printf("Orphan consumer (would delete): %s", orphan)
+		} else {
+			log.Printf("Orphan consumer deleted: %s", orphan)
+		}
+	}
+	// A chave só é mostrada aqui; repasse-a ao responsável pela conta de serviço.
+	for _, apiKey := range report.APIKeys {
+		log.Printf("API key created for service account %s: %s", apiKey.Username, apiKey.Key)
+	}
+	for _, failure := range report.Failed {
+		log.Printf("Failed: %s", failure)
+	}
+	if len(report.Failed) > 0 {
+		os.Exit(1)
+	}
+}
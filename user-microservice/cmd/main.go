@@ -16,9 +16,14 @@
 package main
 
 import (
+	"events"
 	"log"
 	config "login-api/internal/config"
 	controllers "login-api/internal/controllers"
+	"login-api/internal/gateway"
+	"login-api/internal/handlers"
+	"login-api/internal/jobs"
+	"login-api/internal/messaging"
 	"login-api/internal/repositories"
 	routers "login-api/internal/routers"
 	"login-api/internal/usecases"
@@ -26,11 +31,11 @@ import (
 	"login-api/models"
 	"os"
 	"reflect"
-
-	amqp "github.com/rabbitmq/amqp091-go"
+	"shared/gatewayauth"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"github.com/rabbitmq/amqp091-go"
 	"gorm.io/gorm"
 
 	_ "login-api/docs"
@@ -48,6 +53,11 @@ func AutoMigrate(db *gorm.DB) {
 		&models.User{},
 		&models.Role{},
 		&models.UserRole{},
+		&models.RevokedToken{},
+		&models.Group{},
+		&models.LoginAttempt{},
+		&models.Invitation{},
+		&models.OutboxEvent{},
 	}
 
 	for _, model := range models {
@@ -59,37 +69,6 @@ func AutoMigrate(db *gorm.DB) {
 	}
 }
 
-func setupRabbitMQ() (*amqp.Connection, *amqp.Channel, error) {
-	// Adicionar RabbitMq go get github.com/rabbitmq/amqp091-go
-	conn, err := amqp.Dial(os.Getenv("RABBITMQ_URL"))
-	if err != nil {
-		return nil, nil, err
-	}
-
-	ch, err := conn.Channel()
-	if err != nil {
-		conn.Close()
-		return nil, nil, err
-	}
-
-	err = ch.ExchangeDeclare(
-		"user_events",
-		"topic",
-		true,
-		false,
-		false,
-		false,
-		nil,
-	)
-	if err != nil {
-		ch.Close()
-		conn.Close()
-		return nil, nil, err
-	}
-
-	return conn, ch, nil
-}
-
 func main() {
 	godotenv.Load()
 	db := config.Connect()
@@ -97,26 +76,120 @@ func main() {
 	var secretKey string = os.Getenv("SECRET_KEY")
 	jwtAuth, _ := middleware.NewJWTTokenMaker(secretKey)
 	controllers.Initialize(config.Connect(), jwtAuth)
-	router := gin.Default()
-	rabbitmqConn, rabbitmqChan, err := setupRabbitMQ()
-	if err != nil {
-		log.Fatalf("Failed to setup RabbitMQ: %v", err)
+	if os.Getenv("AUTH_MODE") == "trusted-gateway" {
+		trustedGateway, err := gatewayauth.NewTrustedGatewayFromEnv()
+		if err != nil {
+			log.Fatalf("Invalid trusted gateway configuration: %v", err)
+		}
+		controllers.UseTrustedGateway(trustedGateway)
 	}
-	defer rabbitmqConn.Close()
-	defer rabbitmqChan.Close()
+	router := gin.Default()
+	// Os eventos são gravados na outbox; o relay conecta ao RabbitMQ sob demanda, então o
+	// serviço sobe e atende mesmo com o broker fora.
+	publisher := messaging.NewConfirmingPublisher(config.SetupRabbitMQ)
+	defer publisher.Close()
 
 	// Repositories
 	userRepo := repositories.NewGormUserRepository(db)
+	tokenRepo := repositories.NewGormTokenRepository(db)
+	groupRepo := repositories.NewGormGroupRepository(db)
+	loginAttemptRepo := repositories.NewGormLoginAttemptRepository(db)
+	invitationRepo := repositories.NewGormInvitationRepository(db)
+	outboxRepo := repositories.NewGormOutboxRepository(db)
+
+	// Kong (opcional): só sincroniza consumers quando KONG_ADMIN_URL está definido
+	var provisioner usecases.ConsumerProvisioner
+	if kongAdminURL := os.Getenv("KONG_ADMIN_URL"); kongAdminURL != "" {
+		provisioner = gateway.NewKongProvisioner(gateway.NewKongAdminClient(kongAdminURL), secretKey)
+	}
 
 	// Use Cases
-	userUseCase := usecases.NewUserUseCase(userRepo, rabbitmqChan)
+	userUseCase := usecases.NewUserUseCase(userRepo, provisioner)
+	tokenUseCase := usecases.NewTokenUseCase(jwtAuth, tokenRepo, userRepo)
+	controllers.UseTokenChecker(tokenUseCase)
+	groupUseCase := usecases.NewGroupUseCase(groupRepo, userRepo, userUseCase)
+	fileStorage, err := config.NewStorage()
+	if err != nil {
+		log.Fatalf("Invalid storage configuration: %v", err)
+	}
+	avatarUseCase := usecases.NewAvatarUseCase(userRepo, fileStorage)
+	loginHistoryUseCase := usecases.NewLoginHistoryUseCase(loginAttemptRepo, userRepo)
+	controllers.UseLoginRecorder(loginHistoryUseCase)
+	privacyUseCase := usecases.NewPrivacyUseCase(userRepo, groupRepo, tokenRepo, loginAttemptRepo, userUseCase, avatarUseCase)
+	invitationConfig, err := config.NewInvitationConfig()
+	if err != nil {
+		log.Fatalf("Invalid invitation configuration: %v", err)
+	}
+	invitationUseCase := usecases.NewInvitationUseCase(invitationRepo, userRepo, userUseCase, invitationConfig)
+	outboxRelay := usecases.NewOutboxRelay(outboxRepo, publisher)
+
+	// Jobs
+	jobs.StartUserPurge(userUseCase)
+	jobs.StartRoleExpiry(userUseCase)
+	jobs.StartLoginHistoryPrune(loginHistoryUseCase)
+	jobs.StartOutboxRelay(outboxRelay)
+
+	// Mudanças de papéis feitas por outros serviços (opcional): só consome quando o Kong está
+	// configurado e RABBITMQ_URL está definido
+	if provisioner != nil && os.Getenv("RABBITMQ_URL") != "" {
+		rabbitmqConn, rabbitmqChan, err := config.SetupRabbitMQ()
+		if err != nil {
+			log.Fatalf("Failed to setup RabbitMQ: %v", err)
+		}
+		defer rabbitmqConn.Close()
+		defer rabbitmqChan.Close()
+
+		gatewaySyncHandler := handlers.NewGatewaySyncHandler(userUseCase)
+		if err := consumeGatewaySync(rabbitmqChan, gatewaySyncHandler); err != nil {
+			log.Fatalf("Failed to consume role events: %v", err)
+		}
+	}
 
 	// Controllers
 	userController := controllers.NewUserController(userUseCase)
-
-	routers.Routers(router, userController)
+	tokenController := controllers.NewTokenController(tokenUseCase)
+	groupController := controllers.NewGroupController(groupUseCase)
+	avatarController := controllers.NewAvatarController(avatarUseCase)
+	privacyController := controllers.NewPrivacyController(privacyUseCase)
+	loginHistoryController := controllers.NewLoginHistoryController(loginHistoryUseCase)
+	invitationController := controllers.NewInvitationController(invitationUseCase)
+
+	routers.Routers(router, userController, tokenController, groupController, avatarController, privacyController, loginHistoryController, invitationController)
 	// Para acessar o swagger: http://localhost:8081/swagger/index.html#/
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	port := os.Getenv("PORT")
 	router.Run(":" + port)
 }
+
+// consumeGatewaySync liga a fila às routing keys que alteram os papéis de usuários.
+func consumeGatewaySync(ch *amqp091.Channel, handler *handlers.GatewaySyncHandler) error {
+	q, err := ch.QueueDeclare(
+		"user_gateway_sync_queue",
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, routingKey := range []string{events.UserRoleAddedType, events.UserRoleRemovedType, events.RoleDeletedType} {
+		if err := ch.QueueBind(q.Name, routingKey, "user_events", false, nil); err != nil {
+			return err
+		}
+	}
+
+	msgs, err := ch.Consume(q.Name, "", false, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for msg := range msgs {
+			handler.HandleMessage(msg)
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,29 @@
+// Imprime a tabela de rotas do Gin em JSON, usada pelo kong-config-generator.
+//
+//	go run ./cmd/routes
+//
+// A tabela é montada por shared/routetable.
+package main
+
+import (
+	"log"
+	"os"
+
+	"login-api/internal/controllers"
+	routers "login-api/internal/routers"
+	"shared/routetable"
+
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+)
+
+func main() {
+	router := routetable.NewRouter()
+
+	routers.Routers(router, (*controllers.UserController)(nil), (*controllers.TokenController)(nil), (*controllers.GroupController)(nil), (*controllers.AvatarController)(nil), (*controllers.PrivacyController)(nil), (*controllers.LoginHistoryController)(nil), (*controllers.InvitationController)(nil))
+	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+
+	if err := routetable.Write(os.Stdout, router); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"login-api/internal/usecases"
+	"os"
+	"time"
+)
+
+// NewInvitationConfig lê INVITATION_SECRET (padrão SECRET_KEY), INVITATION_ACCEPT_URL
+// e INVITATION_TTL (padrão 72h).
+func NewInvitationConfig() (usecases.InvitationConfig, error) {
+	config := usecases.InvitationConfig{
+		Secret:    os.Getenv("INVITATION_SECRET"),
+		AcceptURL: os.Getenv("INVITATION_ACCEPT_URL"),
+		TTL:       72 * time.Hour,
+	}
+	if config.Secret == "" {
+		config.Secret = os.Getenv("SECRET_KEY")
+	}
+	if config.AcceptURL == "" {
+		config.AcceptURL = "http://localhost:8081/invitations/{token}/accept"
+	}
+	if value := os.Getenv("INVITATION_TTL"); value != "" {
+		ttl, err := time.ParseDuration(value)
+		if err != nil {
+			return config, err
+		}
+		config.TTL = ttl
+	}
+	return config, nil
+}
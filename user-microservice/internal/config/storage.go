@@ -0,0 +1,20 @@
+package config
+
+import (
+	"login-api/internal/storage"
+	"os"
+)
+
+// NewStorage escolhe onde guardar arquivos pelo STORAGE_DRIVER: "s3" (variáveis S3_*)
+// ou "local" (padrão), gravando em STORAGE_DIR.
+func NewStorage() (storage.Storage, error) {
+	if os.Getenv("STORAGE_DRIVER") == "s3" {
+		return storage.NewS3StorageFromEnv()
+	}
+
+	dir := os.Getenv("STORAGE_DIR")
+	if dir == "" {
+		dir = "data"
+	}
+	return storage.NewLocalStorage(dir), nil
+}
@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"login-api/internal/dto"
+	"login-api/internal/usecases"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxAvatarSize = 5 << 20
+
+type AvatarController struct {
+	avatarUseCase *usecases.AvatarUseCase
+}
+
+func NewAvatarController(avatarUseCase *usecases.AvatarUseCase) *AvatarController {
+	return &AvatarController{avatarUseCase: avatarUseCase}
+}
+
+// @Summary Upload my avatar
+// @Description Upload a PNG, JPEG or GIF (max 5MB) as the "avatar" field of a multipart form or as the raw body. The type is detected from the content and the image is cropped to a square and resized to 64, 128 and 256 pixels
+// @Tags avatar
+// @Accept multipart/form-data,png,jpeg,gif
+// @Produce json
+// @Param avatar formData file false "Image file (alternatively send it as the raw body)"
+// @Success 200 {object} Response{data=dto.User} "Success"
+// @Failure 400 {object} ErrorResponse "Invalid Image"
+// @Failure 413 {object} ErrorResponse "Image Too Large"
+// @Failure 415 {object} ErrorResponse "Unsupported Image Type"
+// @Router /me/avatar [put]
+func (ctrl *AvatarController) UploadAvatar(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxAvatarSize)
+
+	data, err := readAvatar(c)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(413, ErrorResponse{Error: "Avatar must be at most 5MB"})
+			return
+		}
+		c.JSON(400, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	user, err := ctrl.avatarUseCase.Upload(c.GetString("Email"), data)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecases.ErrUnsupportedAvatarType):
+			c.JSON(415, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, usecases.ErrInvalidAvatar):
+			c.JSON(400, ErrorResponse{Error: err.Error()})
+		case err.Error() == "user not found":
+			c.JSON(404, ErrorResponse{Error: "User not found"})
+		default:
+			c.JSON(500, ErrorResponse{Error: "Failed to store avatar"})
+		}
+		return
+	}
+
+	c.JSON(200, Response{
+		Data: dto.NewUser(user),
+	})
+}
+
+// @Summary Delete my avatar
+// @Tags avatar
+// @Produce json
+// @Success 200 {object} Response{message=string} "Success"
+// @Router /me/avatar [delete]
+func (ctrl *AvatarController) DeleteAvatar(c *gin.Context) {
+	if err := ctrl.avatarUseCase.Delete(c.GetString("Email")); err != nil {
+		if err.Error() == "user not found" {
+			c.JSON(404, ErrorResponse{Error: "User not found"})
+			return
+		}
+		c.JSON(500, ErrorResponse{Error: "Failed to delete avatar"})
+		return
+	}
+
+	c.JSON(200, Response{
+		Message: "Avatar successfully deleted",
+	})
+}
+
+// @Summary Get user avatar
+// @Description Download a user's avatar as PNG
+// @Tags avatar
+// @Produce png
+// @Param id path string true "User ID"
+// @Param size query int false "64, 128 or 256 (default 256)"
+// @Success 200 {file} file "Avatar image"
+// @Failure 400 {object} ErrorResponse "Invalid Size"
+// @Failure 404 {object} ErrorResponse "User or Avatar Not Found"
+// @Router /users/{id}/avatar [get]
+func (ctrl *AvatarController) GetAvatar(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+	size, err := strconv.Atoi(c.DefaultQuery("size", strconv.Itoa(usecases.DefaultAvatarSize)))
+	if err != nil || !usecases.IsAvatarSize(size) {
+		c.JSON(400, ErrorResponse{Error: "Size must be one of 64, 128, 256"})
+		return
+	}
+
+	body, info, err := ctrl.avatarUseCase.Open(userID, size)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecases.ErrAvatarNotFound):
+			c.JSON(404, ErrorResponse{Error: "Avatar not found"})
+		case err.Error() == "user not found":
+			c.JSON(404, ErrorResponse{Error: "User not found"})
+		default:
+			c.JSON(500, ErrorResponse{Error: "Failed to read avatar"})
+		}
+		return
+	}
+	defer body.Close()
+
+	headers := map[string]string{"Cache-Control": "private, max-age=300"}
+	if !info.LastModified.IsZero() {
+		headers["Last-Modified"] = info.LastModified.UTC().Format(http.TimeFormat)
+	}
+	c.DataFromReader(200, info.Size, "image/png", body, headers)
+}
+
+func readAvatar(c *gin.Context) ([]byte, error) {
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		header, err := c.FormFile("avatar")
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				return nil, err
+			}
+			return nil, errors.New("avatar is required")
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, errors.New("failed to read avatar")
+		}
+		defer file.Close()
+		return io.ReadAll(file)
+	}
+
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("avatar is required")
+	}
+	return data, nil
+}
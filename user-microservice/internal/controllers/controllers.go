@@ -1,7 +1,10 @@
 package controllers
 
 import (
+	"errors"
 	middleware "login-api/middleware"
+	models "login-api/models"
+	"shared/gatewayauth"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -10,10 +13,11 @@ import (
 
 // Response representa uma resposta genérica da API
 type Response struct {
-	Data    interface{}       `json:"data,omitempty"`
-	Message string            `json:"message,omitempty"`
-	Error   string            `json:"error,omitempty"`
-	Errors  map[string]string `json:"errors,omitempty"`
+	Data       interface{}       `json:"data,omitempty"`
+	Pagination *Pagination       `json:"pagination,omitempty"`
+	Message    string            `json:"message,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	Errors     map[string]string `json:"errors,omitempty"`
 }
 
 // ErrorResponse representa uma resposta de erro
@@ -24,13 +28,60 @@ type ErrorResponse struct {
 
 var db *gorm.DB
 var auth middleware.Auth
+var trustedGateway *gatewayauth.TrustedGateway
+var tokenChecker TokenChecker
+var loginRecorder LoginRecorder
+
+// TokenChecker recusa tokens com assinatura válida que foram revogados e identidades
+// de usuários removidos ou que não estão ativos.
+type TokenChecker interface {
+	CheckActive(payload *middleware.Payload) error
+	CheckUser(username string) error
+}
+
+// LoginRecorder guarda o histórico de tentativas de login.
+type LoginRecorder interface {
+	RecordLogin(attempt *models.LoginAttempt)
+}
 
 func Initialize(dbConnection *gorm.DB, authService middleware.Auth) {
 	db = dbConnection
 	auth = authService
 }
 
+// UseTrustedGateway faz o Authenticate confiar na identidade enviada pelo Kong
+// em vez de verificar o JWT. Requisições que não vêm do gateway são rejeitadas.
+func UseTrustedGateway(gateway *gatewayauth.TrustedGateway) {
+	trustedGateway = gateway
+}
+
+func UseTokenChecker(checker TokenChecker) {
+	tokenChecker = checker
+}
+
+func UseLoginRecorder(recorder LoginRecorder) {
+	loginRecorder = recorder
+}
+
 func Authenticate(c *gin.Context) {
+	if trustedGateway != nil {
+		identity, err := trustedGateway.Identify(c.Request)
+		if err != nil {
+			c.JSON(401, gin.H{"error": "Request must come through the API gateway"})
+			c.Abort()
+			return
+		}
+		if err := checkGatewayIdentity(c, identity); err != nil {
+			c.JSON(401, gin.H{"error": "Token has been revoked"})
+			c.Abort()
+			return
+		}
+		c.Set("Email", identity.Username)
+		c.Set("Roles", identity.Roles)
+		c.Next()
+		return
+	}
+
 	authHeader := c.GetHeader("Authorization")
 	if authHeader == "" {
 		c.JSON(401, gin.H{"error": "Authorization header is required"})
@@ -46,7 +97,38 @@ func Authenticate(c *gin.Context) {
 		return
 	}
 
+	if tokenChecker != nil {
+		if err := tokenChecker.CheckActive(payload); err != nil {
+			c.JSON(401, gin.H{"error": "Token has been revoked"})
+			c.Abort()
+			return
+		}
+	}
+
 	c.Set("Email", payload.Username)
 	c.Set("Roles", payload.Roles)
 	c.Next()
 }
+
+// checkGatewayIdentity aplica no modo trusted-gateway a mesma verificação do modo token: o Kong
+// valida a assinatura e a expiração, mas não sabe de logout nem de contas suspensas. Quando o
+// Kong repassa o JWT (plugin jwt), o jti é conferido na lista de revogação; para os demais
+// plugins resta verificar o usuário.
+func checkGatewayIdentity(c *gin.Context, identity *gatewayauth.GatewayIdentity) error {
+	if tokenChecker == nil {
+		return nil
+	}
+
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		return tokenChecker.CheckUser(identity.Username)
+	}
+	payload, err := auth.VerifyToken(strings.TrimPrefix(authHeader, "Bearer "))
+	if err != nil {
+		return err
+	}
+	if payload.Username != identity.Username {
+		return errors.New("token does not belong to the gateway consumer")
+	}
+	return tokenChecker.CheckActive(payload)
+}
@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"errors"
+	middleware "login-api/middleware"
+	"net"
+	"net/http/httptest"
+	"shared/gatewayauth"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeTokenChecker recusa os tokens em revoked e os usuários em inactive.
+type fakeTokenChecker struct {
+	revoked  map[string]bool
+	inactive map[string]bool
+}
+
+func (f *fakeTokenChecker) CheckActive(payload *middleware.Payload) error {
+	if f.revoked[payload.ID.String()] {
+		return errors.New("revoked")
+	}
+	return f.CheckUser(payload.Username)
+}
+
+func (f *fakeTokenChecker) CheckUser(username string) error {
+	if f.inactive[username] {
+		return errors.New("inactive")
+	}
+	return nil
+}
+
+func useTrustedGatewayForTest(t *testing.T, checker TokenChecker) middleware.Auth {
+	gin.SetMode(gin.TestMode)
+	jwtAuth, err := middleware.NewJWTTokenMaker("01234567890123456789012345678901")
+	if err != nil {
+		t.Fatalf("NewJWTTokenMaker: %v", err)
+	}
+	_, network, _ := net.ParseCIDR("10.0.0.0/8")
+
+	previousAuth, previousGateway, previousChecker := auth, trustedGateway, tokenChecker
+	auth = jwtAuth
+	trustedGateway = &gatewayauth.TrustedGateway{
+		TrustedNetworks: []*net.IPNet{network},
+		UsernameHeader:  "X-Consumer-Username",
+		RolesHeaders:    []string{"X-Consumer-Groups"},
+	}
+	tokenChecker = checker
+	t.Cleanup(func() {
+		auth, trustedGateway, tokenChecker = previousAuth, previousGateway, previousChecker
+	})
+	return jwtAuth
+}
+
+func authenticateThroughGateway(username, token string) int {
+	recorder := httptest.NewRecorder()
+	c, router := gin.CreateTestContext(recorder)
+	router.GET("/user", Authenticate, func(c *gin.Context) { c.Status(200) })
+
+	c.Request = httptest.NewRequest("GET", "/user", nil)
+	c.Request.RemoteAddr = "10.0.0.2:4000"
+	c.Request.Header.Set("X-Consumer-Username", username)
+	c.Request.Header.Set("X-Consumer-Groups", "Admin")
+	if token != "" {
+		c.Request.Header.Set("Authorization", "Bearer "+token)
+	}
+	router.HandleContext(c)
+	return recorder.Code
+}
+
+func TestTrustedGatewayChecksRevokedTokens(t *testing.T) {
+	checker := &fakeTokenChecker{revoked: make(map[string]bool)}
+	jwtAuth := useTrustedGatewayForTest(t, checker)
+
+	token, err := jwtAuth.CreateTokenWithRoles("ana@example.com", []string{"Admin"}, time.Hour)
+	if err != nil {
+		t.Fatalf("CreateTokenWithRoles: %v", err)
+	}
+	if code := authenticateThroughGateway("ana@example.com", token); code != 200 {
+		t.Fatalf("active token: status %d, want 200", code)
+	}
+
+	payload, _ := jwtAuth.VerifyToken(token)
+	checker.revoked[payload.ID.String()] = true
+	if code := authenticateThroughGateway("ana@example.com", token); code != 401 {
+		t.Errorf("revoked token: status %d, want 401", code)
+	}
+}
+
+func TestTrustedGatewayRejectsTokenOfAnotherConsumer(t *testing.T) {
+	jwtAuth := useTrustedGatewayForTest(t, &fakeTokenChecker{})
+
+	token, _ := jwtAuth.CreateTokenWithRoles("ana@example.com", nil, time.Hour)
+	if code := authenticateThroughGateway("bruno@example.com", token); code != 401 {
+		t.Errorf("status %d, want 401", code)
+	}
+}
+
+func TestTrustedGatewayChecksUserWithoutToken(t *testing.T) {
+	useTrustedGatewayForTest(t, &fakeTokenChecker{inactive: map[string]bool{"suspended@example.com": true}})
+
+	if code := authenticateThroughGateway("ci@example.com", ""); code != 200 {
+		t.Errorf("active user: status %d, want 200", code)
+	}
+	if code := authenticateThroughGateway("suspended@example.com", ""); code != 401 {
+		t.Errorf("suspended user: status %d, want 401", code)
+	}
+}
@@ -0,0 +1,298 @@
+package controllers
+
+import (
+	"errors"
+	"login-api/internal/dto"
+	"login-api/internal/usecases"
+	models "login-api/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GroupController struct {
+	groupUseCase *usecases.GroupUseCase
+}
+
+func NewGroupController(groupUseCase *usecases.GroupUseCase) *GroupController {
+	return &GroupController{groupUseCase: groupUseCase}
+}
+
+// @Summary Get all groups
+// @Description List groups with the roles they grant
+// @Tags groups
+// @Produce json
+// @Success 200 {object} Response{data=[]dto.Group} "Success"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /groups [get]
+func (ctrl *GroupController) GetGroups(c *gin.Context) {
+	groups, err := ctrl.groupUseCase.GetAll()
+	if err != nil {
+		c.JSON(500, ErrorResponse{Error: "Failed to retrieve groups"})
+		return
+	}
+
+	c.JSON(200, Response{
+		Data: dto.NewGroups(groups),
+	})
+}
+
+// @Summary Get group by ID
+// @Tags groups
+// @Produce json
+// @Param id path string true "Group ID"
+// @Success 200 {object} Response{data=dto.Group} "Success"
+// @Failure 404 {object} ErrorResponse "Group Not Found"
+// @Router /groups/{id} [get]
+func (ctrl *GroupController) GetGroup(c *gin.Context) {
+	id, ok := groupID(c)
+	if !ok {
+		return
+	}
+
+	group, err := ctrl.groupUseCase.GetByID(id)
+	if err != nil {
+		c.JSON(404, ErrorResponse{Error: "Group not found"})
+		return
+	}
+
+	c.JSON(200, Response{
+		Data: dto.NewGroup(group),
+	})
+}
+
+// @Summary Create group
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param group body models.Group true "Group name and description"
+// @Success 201 {object} Response{data=dto.Group} "Created"
+// @Failure 400 {object} ErrorResponse "Validation Error"
+// @Failure 409 {object} ErrorResponse "Group Already Exists"
+// @Router /groups [post]
+func (ctrl *GroupController) CreateGroup(c *gin.Context) {
+	var group models.Group
+	if err := c.ShouldBindJSON(&group); err != nil {
+		c.JSON(400, ErrorResponse{Errors: map[string]string{"name": "Name is required"}})
+		return
+	}
+
+	if err := ctrl.groupUseCase.Create(&group); err != nil {
+		respondGroupError(c, err, "Failed to create group")
+		return
+	}
+
+	c.JSON(201, Response{
+		Data: dto.NewGroup(&group),
+	})
+}
+
+// @Summary Update group
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param id path string true "Group ID"
+// @Param group body models.Group true "Group name and description"
+// @Success 200 {object} Response{data=dto.Group} "Success"
+// @Failure 400 {object} ErrorResponse "Validation Error"
+// @Failure 404 {object} ErrorResponse "Group Not Found"
+// @Failure 409 {object} ErrorResponse "Group Already Exists"
+// @Router /groups/{id} [put]
+func (ctrl *GroupController) UpdateGroup(c *gin.Context) {
+	id, ok := groupID(c)
+	if !ok {
+		return
+	}
+
+	var group models.Group
+	if err := c.ShouldBindJSON(&group); err != nil {
+		c.JSON(400, ErrorResponse{Errors: map[string]string{"name": "Name is required"}})
+		return
+	}
+
+	updated, err := ctrl.groupUseCase.Update(id, &group)
+	if err != nil {
+		respondGroupError(c, err, "Failed to update group")
+		return
+	}
+
+	c.JSON(200, Response{
+		Data: dto.NewGroup(updated),
+	})
+}
+
+// @Summary Delete group
+// @Description Delete a group. Its members lose the roles it granted
+// @Tags groups
+// @Produce json
+// @Param id path string true "Group ID"
+// @Success 200 {object} Response{message=string} "Success"
+// @Failure 404 {object} ErrorResponse "Group Not Found"
+// @Router /groups/{id} [delete]
+func (ctrl *GroupController) DeleteGroup(c *gin.Context) {
+	id, ok := groupID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.groupUseCase.Delete(id); err != nil {
+		respondGroupError(c, err, "Failed to delete group")
+		return
+	}
+
+	c.JSON(200, Response{
+		Message: "Group successfully deleted",
+	})
+}
+
+// @Summary Get group members
+// @Tags groups
+// @Produce json
+// @Param id path string true "Group ID"
+// @Param fields query string false "Comma-separated fields to return, e.g. id,name,email"
+// @Param include query string false "Expand related resources: roles"
+// @Success 200 {object} Response{data=[]dto.User} "Success"
+// @Failure 404 {object} ErrorResponse "Group Not Found"
+// @Router /groups/{id}/members [get]
+func (ctrl *GroupController) GetMembers(c *gin.Context) {
+	id, ok := groupID(c)
+	if !ok {
+		return
+	}
+
+	options, ok := userOptions(c)
+	if !ok {
+		return
+	}
+
+	members, err := ctrl.groupUseCase.GetMembers(id)
+	if err != nil {
+		respondGroupError(c, err, "Failed to retrieve group members")
+		return
+	}
+
+	c.JSON(200, Response{
+		Data: dto.UserViews(options, members),
+	})
+}
+
+// @Summary Add member to group
+// @Tags groups
+// @Produce json
+// @Param id path string true "Group ID"
+// @Param userId path string true "User ID"
+// @Success 200 {object} Response{message=string} "Success"
+// @Failure 404 {object} ErrorResponse "Group or User Not Found"
+// @Router /groups/{id}/members/{userId} [post]
+func (ctrl *GroupController) AddMember(c *gin.Context) {
+	id, ok := groupID(c)
+	if !ok {
+		return
+	}
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 64)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	if err := ctrl.groupUseCase.AddMember(id, userID); err != nil {
+		respondGroupError(c, err, "Failed to add member to group")
+		return
+	}
+
+	c.JSON(200, Response{
+		Message: "User successfully added to group",
+	})
+}
+
+// @Summary Remove member from group
+// @Tags groups
+// @Produce json
+// @Param id path string true "Group ID"
+// @Param userId path string true "User ID"
+// @Success 200 {object} Response{message=string} "Success"
+// @Failure 404 {object} ErrorResponse "Group Not Found"
+// @Router /groups/{id}/members/{userId} [delete]
+func (ctrl *GroupController) RemoveMember(c *gin.Context) {
+	id, ok := groupID(c)
+	if !ok {
+		return
+	}
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 64)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	if err := ctrl.groupUseCase.RemoveMember(id, userID); err != nil {
+		respondGroupError(c, err, "Failed to remove member from group")
+		return
+	}
+
+	c.JSON(200, Response{
+		Message: "User successfully removed from group",
+	})
+}
+
+// @Summary Replace group roles
+// @Description Replace the full set of roles granted to every member of the group
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param id path string true "Group ID"
+// @Param roles body models.GroupRolesRequest true "Desired role IDs"
+// @Success 200 {object} Response{data=dto.Group} "Success"
+// @Failure 400 {object} ErrorResponse "Invalid Input"
+// @Failure 404 {object} ErrorResponse "Group or Role Not Found"
+// @Router /groups/{id}/roles [put]
+func (ctrl *GroupController) ReplaceGroupRoles(c *gin.Context) {
+	id, ok := groupID(c)
+	if !ok {
+		return
+	}
+
+	var request models.GroupRolesRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(400, ErrorResponse{Error: "role_ids must be a list of role IDs"})
+		return
+	}
+
+	group, err := ctrl.groupUseCase.ReplaceRoles(id, request.RoleIDs)
+	if err != nil {
+		respondGroupError(c, err, "Failed to replace group roles")
+		return
+	}
+
+	c.JSON(200, Response{
+		Data: dto.NewGroup(group),
+	})
+}
+
+func groupID(c *gin.Context) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: "Invalid group ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+func respondGroupError(c *gin.Context, err error, fallback string) {
+	var unknown *usecases.UnknownRolesError
+	switch {
+	case errors.As(err, &unknown):
+		errorMessages := make(map[string]string)
+		for _, id := range unknown.IDs {
+			errorMessages[strconv.FormatUint(id, 10)] = "Role not found"
+		}
+		c.JSON(404, ErrorResponse{Error: "Role not found", Errors: errorMessages})
+	case err.Error() == "group not found":
+		c.JSON(404, ErrorResponse{Error: "Group not found"})
+	case err.Error() == "user not found":
+		c.JSON(404, ErrorResponse{Error: "User not found"})
+	case err.Error() == "group already exists":
+		c.JSON(409, ErrorResponse{Error: "Group already exists"})
+	default:
+		c.JSON(500, ErrorResponse{Error: fallback})
+	}
+}
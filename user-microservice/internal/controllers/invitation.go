@@ -0,0 +1,197 @@
+package controllers
+
+import (
+	"errors"
+	"login-api/internal/dto"
+	"login-api/internal/repositories"
+	"login-api/internal/usecases"
+	models "login-api/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type InvitationController struct {
+	invitationUseCase *usecases.InvitationUseCase
+}
+
+func NewInvitationController(invitationUseCase *usecases.InvitationUseCase) *InvitationController {
+	return &InvitationController{invitationUseCase: invitationUseCase}
+}
+
+// @Summary List pending invitations
+// @Description List invitations not yet accepted, including expired ones
+// @Tags invitations
+// @Produce json
+// @Success 200 {object} Response{data=[]dto.Invitation} "Success"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /invitations [get]
+func (ctrl *InvitationController) GetInvitations(c *gin.Context) {
+	invitations, err := ctrl.invitationUseCase.GetPending()
+	if err != nil {
+		c.JSON(500, ErrorResponse{Error: "Failed to retrieve invitations"})
+		return
+	}
+
+	c.JSON(200, Response{
+		Data: dto.NewInvitations(invitations),
+	})
+}
+
+// @Summary Invite user
+// @Description Create a pending user with the initial roles and email a signed, expiring link to set the password
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Param invitation body models.InvitationRequest true "Email, name and initial roles"
+// @Success 201 {object} Response{data=dto.Invitation} "Created"
+// @Failure 400 {object} ErrorResponse "Validation Error"
+// @Failure 404 {object} ErrorResponse "Role Not Found"
+// @Failure 409 {object} ErrorResponse "User Already Registered"
+// @Router /invitations [post]
+func (ctrl *InvitationController) CreateInvitation(c *gin.Context) {
+	var request models.InvitationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		errorMessages := make(map[string]string)
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			for _, fieldErr := range validationErrors {
+				switch fieldErr.Field() {
+				case "Name":
+					errorMessages["name"] = "Name is required"
+				case "Email":
+					errorMessages["email"] = "A valid email is required"
+				}
+			}
+		}
+		c.JSON(400, ErrorResponse{Error: "Invalid data provided", Errors: errorMessages})
+		return
+	}
+
+	invitation, err := ctrl.invitationUseCase.Invite(request, c.GetString("Email"))
+	if err != nil {
+		respondInvitationError(c, err, "Failed to create invitation")
+		return
+	}
+
+	c.JSON(201, Response{
+		Data: dto.NewInvitation(invitation),
+	})
+}
+
+// @Summary Resend invitation
+// @Description Email a new link with a fresh expiry; links sent before stop working
+// @Tags invitations
+// @Produce json
+// @Param id path string true "Invitation ID"
+// @Success 200 {object} Response{data=dto.Invitation} "Success"
+// @Failure 404 {object} ErrorResponse "Invitation Not Found"
+// @Failure 409 {object} ErrorResponse "Already Accepted"
+// @Router /invitations/{id}/resend [post]
+func (ctrl *InvitationController) ResendInvitation(c *gin.Context) {
+	id, ok := invitationID(c)
+	if !ok {
+		return
+	}
+
+	invitation, err := ctrl.invitationUseCase.Resend(id)
+	if err != nil {
+		respondInvitationError(c, err, "Failed to resend invitation")
+		return
+	}
+
+	c.JSON(200, Response{
+		Data: dto.NewInvitation(invitation),
+	})
+}
+
+// @Summary Revoke invitation
+// @Description Cancel a pending invitation and remove the pending user
+// @Tags invitations
+// @Produce json
+// @Param id path string true "Invitation ID"
+// @Success 200 {object} Response{message=string} "Success"
+// @Failure 404 {object} ErrorResponse "Invitation Not Found"
+// @Failure 409 {object} ErrorResponse "Already Accepted or User No Longer Pending"
+// @Router /invitations/{id} [delete]
+func (ctrl *InvitationController) RevokeInvitation(c *gin.Context) {
+	id, ok := invitationID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.invitationUseCase.Revoke(id); err != nil {
+		respondInvitationError(c, err, "Failed to revoke invitation")
+		return
+	}
+
+	c.JSON(200, Response{
+		Message: "Invitation successfully revoked",
+	})
+}
+
+// @Summary Accept invitation
+// @Description Set the password with the token from the invitation email. The account is activated only now
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Param token path string true "Token from the invitation link"
+// @Param password body models.InvitationAcceptRequest true "Password (at least 8 characters)"
+// @Success 200 {object} Response{data=dto.User} "Success"
+// @Failure 400 {object} ErrorResponse "Invalid Password"
+// @Failure 404 {object} ErrorResponse "Invalid Invitation"
+// @Failure 409 {object} ErrorResponse "Already Accepted or User No Longer Pending"
+// @Failure 410 {object} ErrorResponse "Invitation Expired"
+// @Router /invitations/{token}/accept [post]
+func (ctrl *InvitationController) AcceptInvitation(c *gin.Context) {
+	var request models.InvitationAcceptRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(400, ErrorResponse{Errors: map[string]string{"password": "Password must have at least 8 characters"}})
+		return
+	}
+
+	user, err := ctrl.invitationUseCase.Accept(c.Param("id"), request.Password)
+	if err != nil {
+		respondInvitationError(c, err, "Failed to accept invitation")
+		return
+	}
+
+	c.JSON(200, Response{
+		Data: dto.NewUser(user),
+	})
+}
+
+func invitationID(c *gin.Context) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: "Invalid invitation ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+func respondInvitationError(c *gin.Context, err error, fallback string) {
+	var unknown *usecases.UnknownRolesError
+	switch {
+	case errors.As(err, &unknown):
+		errorMessages := make(map[string]string)
+		for _, id := range unknown.IDs {
+			errorMessages[strconv.FormatUint(id, 10)] = "Role not found"
+		}
+		c.JSON(404, ErrorResponse{Error: "Role not found", Errors: errorMessages})
+	case errors.Is(err, usecases.ErrInvalidInvitation):
+		c.JSON(404, ErrorResponse{Error: "Invitation not found or no longer valid"})
+	case errors.Is(err, usecases.ErrInvitationExpired):
+		c.JSON(410, ErrorResponse{Error: "Invitation expired"})
+	case err.Error() == "invitation not found":
+		c.JSON(404, ErrorResponse{Error: "Invitation not found"})
+	case err.Error() == "invitation already accepted":
+		c.JSON(409, ErrorResponse{Error: "Invitation already accepted"})
+	case errors.Is(err, repositories.ErrUserNotPending):
+		c.JSON(409, ErrorResponse{Error: "User is no longer pending"})
+	case err.Error() == "user already registered":
+		c.JSON(409, ErrorResponse{Error: "User already registered"})
+	default:
+		c.JSON(500, ErrorResponse{Error: fallback})
+	}
+}
@@ -9,6 +9,8 @@ package controllers
 import (
 	"time"
 
+	"login-api/internal/repositories"
+	"login-api/internal/usecases"
 	models "login-api/models"
 
 	"github.com/gin-gonic/gin"
@@ -22,35 +24,62 @@ import (
 // @Produce json
 // @Param userLogin body models.UserLogin true "User credentials"
 // @Success 200 {object} map[string]interface{} "JWT token and user information"
+// @Failure 400 {object} map[string]string "Missing username or password"
 // @Failure 401 {object} map[string]string "Invalid credentials"
+// @Failure 403 {object} map[string]string "Account not active (code account_pending, account_suspended, account_locked or account_deactivated)"
 // @Failure 500 {object} map[string]string "Failed to create token"
 // @Router /login [post]
 func Login(c *gin.Context) {
 	var userLogin models.UserLogin
-	c.BindJSON(&userLogin)
+	// Sem a senha a consulta abaixo casaria com contas que não têm senha definida.
+	if err := c.ShouldBindJSON(&userLogin); err != nil {
+		c.JSON(400, gin.H{
+			"error": "Username and password are required",
+		})
+		return
+	}
 	var user models.User
-	result := db.Preload("Roles").Where("email = ? AND password = ?", userLogin.Username, userLogin.Password).First(&user)
+	result := db.Where("email = ? AND password = ?", userLogin.Username, userLogin.Password).First(&user)
 
 	if result.Error != nil {
+		recordLogin(c, userLogin.Username, nil, "invalid_credentials")
 		c.JSON(401, gin.H{
 			"error": "Invalid credentials",
 		})
 		return
 	}
 
+	if user.ID != 0 && !user.IsActive() {
+		recordLogin(c, user.Email, &user.ID, "account_"+user.Status)
+		c.JSON(403, gin.H{
+			"error": "Account is " + user.Status,
+			"code":  "account_" + user.Status,
+		})
+		return
+	}
+
 	if user.ID != 0 {
-		roles := make([]string, len(user.Roles))
-		for i, role := range user.Roles {
+		// Só entram no token os papéis vigentes; atribuições vencidas ou futuras são ignoradas.
+		activeRoles, err := repositories.NewGormUserRepository(db).FindActiveRoles(user.ID, time.Now())
+		if err != nil {
+			c.JSON(500, gin.H{
+				"error": "Failed to load user roles",
+			})
+			return
+		}
+		roles := make([]string, len(activeRoles))
+		for i, role := range activeRoles {
 			roles[i] = role.Name
 		}
 
-		token, err := auth.CreateTokenWithRoles(user.Email, roles, time.Hour*12)
+		token, err := auth.CreateTokenWithRoles(user.Email, roles, usecases.TokenDuration)
 		if err != nil {
 			c.JSON(500, gin.H{
 				"error": "Failed to create token",
 			})
 			return
 		}
+		recordLogin(c, user.Email, &user.ID, "")
 		c.JSON(200, gin.H{
 			"token":   token,
 			"user_id": user.ID,
@@ -75,3 +104,18 @@ func VerifyToken(c *gin.Context) {
 		"data": true,
 	})
 }
+
+// recordLogin registra a tentativa no histórico; reason vazio indica sucesso.
+func recordLogin(c *gin.Context, email string, userID *uint64, reason string) {
+	if loginRecorder == nil {
+		return
+	}
+	loginRecorder.RecordLogin(&models.LoginAttempt{
+		UserID:        userID,
+		Email:         email,
+		Success:       reason == "",
+		FailureReason: reason,
+		IP:            c.ClientIP(),
+		UserAgent:     c.Request.UserAgent(),
+	})
+}
@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"login-api/internal/usecases"
+	models "login-api/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LoginHistoryController struct {
+	loginHistoryUseCase *usecases.LoginHistoryUseCase
+}
+
+func NewLoginHistoryController(loginHistoryUseCase *usecases.LoginHistoryUseCase) *LoginHistoryController {
+	return &LoginHistoryController{loginHistoryUseCase: loginHistoryUseCase}
+}
+
+// @Summary Get user login history
+// @Description List a user's successful and failed login attempts, newest first
+// @Tags login-history
+// @Produce json
+// @Param id path string true "User ID"
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} Response{data=[]models.LoginAttempt} "Success"
+// @Failure 404 {object} ErrorResponse "User Not Found"
+// @Router /users/{id}/logins [get]
+func (ctrl *LoginHistoryController) GetUserLogins(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+	errorMessages := make(map[string]string)
+	page, pageSize := parsePageParams(c, errorMessages)
+	if len(errorMessages) > 0 {
+		c.JSON(400, ErrorResponse{Errors: errorMessages})
+		return
+	}
+
+	attempts, err := ctrl.loginHistoryUseCase.GetByUser(userID, page, pageSize)
+	respondLoginHistory(c, attempts, err, page, pageSize)
+}
+
+// @Summary Get my login history
+// @Description List the authenticated user's login attempts, newest first
+// @Tags login-history
+// @Produce json
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} Response{data=[]models.LoginAttempt} "Success"
+// @Router /me/logins [get]
+func (ctrl *LoginHistoryController) GetMyLogins(c *gin.Context) {
+	errorMessages := make(map[string]string)
+	page, pageSize := parsePageParams(c, errorMessages)
+	if len(errorMessages) > 0 {
+		c.JSON(400, ErrorResponse{Errors: errorMessages})
+		return
+	}
+
+	attempts, err := ctrl.loginHistoryUseCase.GetByEmail(c.GetString("Email"), page, pageSize)
+	respondLoginHistory(c, attempts, err, page, pageSize)
+}
+
+func respondLoginHistory(c *gin.Context, attempts *models.LoginAttemptPage, err error, page, pageSize int) {
+	if err != nil {
+		if err.Error() == "user not found" {
+			c.JSON(404, ErrorResponse{Error: "User not found"})
+			return
+		}
+		c.JSON(500, ErrorResponse{Error: "Failed to retrieve login history"})
+		return
+	}
+
+	c.JSON(200, Response{
+		Data:       attempts.Attempts,
+		Pagination: offsetPagination(c, page, pageSize, attempts.Total),
+	})
+}
@@ -0,0 +1,32 @@
+package controllers
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Usuários importados ou convidados ficam sem senha até aceitar o convite; um login com
+// senha vazia não pode chegar à consulta por email e senha.
+func TestLoginRejectsEmptyPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for name, body := range map[string]string{
+		"empty password":   `{"username": "imported@example.com", "password": ""}`,
+		"missing password": `{"username": "imported@example.com"}`,
+		"missing username": `{"password": "secret"}`,
+	} {
+		recorder := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(recorder)
+		c.Request = httptest.NewRequest("POST", "/login", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		Login(c)
+
+		if recorder.Code != 400 {
+			t.Errorf("%s: status %d, want 400", name, recorder.Code)
+		}
+	}
+}
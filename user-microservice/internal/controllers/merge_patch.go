@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var errMergePatchNotObject = errors.New("merge patch must be a JSON object")
+
+var patchValidator = validator.New()
+
+// Campos do usuário que podem ser alterados via PATCH, com a coluna e a validação de cada um.
+var userPatchFields = map[string]func(raw json.RawMessage) (interface{}, string){
+	"name": func(raw json.RawMessage) (interface{}, string) {
+		var name string
+		if err := json.Unmarshal(raw, &name); err != nil || strings.TrimSpace(name) == "" {
+			return nil, "Name must be a non-empty string"
+		}
+		return strings.TrimSpace(name), ""
+	},
+	"email": func(raw json.RawMessage) (interface{}, string) {
+		var email string
+		if err := json.Unmarshal(raw, &email); err != nil || patchValidator.Var(email, "required,email") != nil {
+			return nil, "Invalid email format"
+		}
+		return email, ""
+	},
+	"service_account": func(raw json.RawMessage) (interface{}, string) {
+		var serviceAccount bool
+		if err := json.Unmarshal(raw, &serviceAccount); err != nil {
+			return nil, "Service account must be a boolean"
+		}
+		return serviceAccount, ""
+	},
+}
+
+// parseUserMergePatch interpreta o corpo como JSON Merge Patch (RFC 7396). Somente os
+// membros presentes no documento viram colunas a atualizar; `null` removeria o campo,
+// o que não é permitido para nenhum dos campos editáveis.
+func parseUserMergePatch(body []byte) (map[string]interface{}, map[string]string, error) {
+	var patch map[string]json.RawMessage
+	if err := json.Unmarshal(body, &patch); err != nil || patch == nil {
+		return nil, nil, errMergePatchNotObject
+	}
+
+	fields := make(map[string]interface{})
+	errorMessages := make(map[string]string)
+	for member, raw := range patch {
+		parse, ok := userPatchFields[member]
+		if !ok {
+			errorMessages[member] = "Field cannot be changed"
+			continue
+		}
+		if string(raw) == "null" {
+			errorMessages[member] = "Field cannot be removed"
+			continue
+		}
+		value, message := parse(raw)
+		if message != "" {
+			errorMessages[member] = message
+			continue
+		}
+		fields[member] = value
+	}
+	return fields, errorMessages, nil
+}
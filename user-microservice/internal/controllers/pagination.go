@@ -0,0 +1,167 @@
+package controllers
+
+import (
+	"login-api/internal/repositories"
+	"login-api/internal/usecases"
+	"login-api/models"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// Pagination acompanha as listagens paginadas com o total e os links de navegação.
+type Pagination struct {
+	Total      int64             `json:"total"`
+	Page       int               `json:"page,omitempty"`
+	PageSize   int               `json:"page_size"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+	Links      map[string]string `json:"links"`
+}
+
+// parseUserListQuery lê page, page_size, cursor, name, email, role,
+// status, registered_from, registered_to, include_deleted e sort (ex.: sort=-register_date para ordem decrescente).
+func parseUserListQuery(c *gin.Context) (models.UserListQuery, map[string]string) {
+	query := models.UserListQuery{
+		Cursor:         c.Query("cursor"),
+		Name:           c.Query("name"),
+		Email:          c.Query("email"),
+		Role:           c.Query("role"),
+		Status:         c.Query("status"),
+		Sort:           "id",
+		IncludeDeleted: c.Query("include_deleted") == "true",
+	}
+	errorMessages := make(map[string]string)
+
+	query.Page, query.PageSize = parsePageParams(c, errorMessages)
+
+	if query.Status != "" && !usecases.IsValidUserStatus(query.Status) {
+		errorMessages["status"] = "Status must be one of pending, active, suspended, locked, deactivated"
+	}
+
+	if value := c.Query("sort"); value != "" {
+		query.Desc = strings.HasPrefix(value, "-")
+		query.Sort = strings.TrimPrefix(value, "-")
+		if _, ok := repositories.UserSortFields[query.Sort]; !ok {
+			errorMessages["sort"] = "Sort must be one of id, name, email, register_date"
+		}
+	}
+
+	for param, target := range map[string]**time.Time{
+		"registered_from": &query.RegisteredFrom,
+		"registered_to":   &query.RegisteredTo,
+	} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		date, err := parseDate(value)
+		if err != nil {
+			errorMessages[param] = "Date must be YYYY-MM-DD or RFC 3339"
+			continue
+		}
+		if param == "registered_to" && len(value) == len("2006-01-02") {
+			// Uma data sem hora inclui o dia inteiro.
+			date = date.Add(24*time.Hour - time.Nanosecond)
+		}
+		*target = &date
+	}
+
+	return query, errorMessages
+}
+
+// parsePageParams lê page e page_size, com os padrões quando ausentes.
+func parsePageParams(c *gin.Context, errorMessages map[string]string) (int, int) {
+	page, pageSize := 1, defaultPageSize
+	if value := c.Query("page"); value != "" {
+		var err error
+		page, err = strconv.Atoi(value)
+		if err != nil || page < 1 {
+			errorMessages["page"] = "Page must be a positive integer"
+		}
+	}
+	if value := c.Query("page_size"); value != "" {
+		var err error
+		pageSize, err = strconv.Atoi(value)
+		if err != nil || pageSize < 1 || pageSize > maxPageSize {
+			errorMessages["page_size"] = "Page size must be between 1 and " + strconv.Itoa(maxPageSize)
+		}
+	}
+	return page, pageSize
+}
+
+func parseDate(value string) (time.Time, error) {
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// buildPagination monta a paginação por cursor quando a requisição trouxe um; senão, a por página,
+// que também traz o next_cursor e o link next_cursor para o cliente continuar por cursor.
+func buildPagination(c *gin.Context, query models.UserListQuery, total int64, nextCursor string) *Pagination {
+	if query.Cursor == "" {
+		pagination := offsetPagination(c, query.Page, query.PageSize, total)
+		if nextCursor != "" {
+			pagination.NextCursor = nextCursor
+			pagination.Links["next_cursor"] = pageLink(c, map[string]string{"cursor": nextCursor, "page": ""})
+		}
+		return pagination
+	}
+
+	pagination := &Pagination{
+		Total:      total,
+		PageSize:   query.PageSize,
+		NextCursor: nextCursor,
+		Links:      map[string]string{"self": c.Request.URL.RequestURI()},
+	}
+	if nextCursor != "" {
+		pagination.Links["next"] = pageLink(c, map[string]string{"cursor": nextCursor, "page": ""})
+	}
+	return pagination
+}
+
+// offsetPagination monta a paginação por página com os links first, prev, next e last.
+func offsetPagination(c *gin.Context, page, pageSize int, total int64) *Pagination {
+	pagination := &Pagination{
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+		Links:    map[string]string{"self": c.Request.URL.RequestURI()},
+	}
+
+	lastPage := int(math.Max(1, math.Ceil(float64(total)/float64(pageSize))))
+	pagination.Links["first"] = pageLink(c, map[string]string{"page": "1"})
+	pagination.Links["last"] = pageLink(c, map[string]string{"page": strconv.Itoa(lastPage)})
+	if page > 1 {
+		pagination.Links["prev"] = pageLink(c, map[string]string{"page": strconv.Itoa(page - 1)})
+	}
+	if page < lastPage {
+		pagination.Links["next"] = pageLink(c, map[string]string{"page": strconv.Itoa(page + 1)})
+	}
+	return pagination
+}
+
+// pageLink repete a query atual trocando os parâmetros informados; valor vazio remove o parâmetro.
+func pageLink(c *gin.Context, changes map[string]string) string {
+	values := url.Values{}
+	for key, list := range c.Request.URL.Query() {
+		values[key] = list
+	}
+	for key, value := range changes {
+		if value == "" {
+			values.Del(key)
+		} else {
+			values.Set(key, value)
+		}
+	}
+	return c.Request.URL.Path + "?" + values.Encode()
+}
@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	models "login-api/models"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestFirstPageExposesTheNextCursor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/user?page_size=2&name=ana", nil)
+
+	pagination := buildPagination(c, models.UserListQuery{Page: 1, PageSize: 2}, 5, "abc")
+
+	if pagination.NextCursor != "abc" {
+		t.Errorf("NextCursor = %q, want abc", pagination.NextCursor)
+	}
+	if link := pagination.Links["next_cursor"]; link != "/user?cursor=abc&name=ana&page_size=2" {
+		t.Errorf("next_cursor link = %q", link)
+	}
+	if link := pagination.Links["next"]; link != "/user?name=ana&page=2&page_size=2" {
+		t.Errorf("next link = %q", link)
+	}
+}
+
+func TestLastPageHasNoCursor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/user?page=3&page_size=2", nil)
+
+	pagination := buildPagination(c, models.UserListQuery{Page: 3, PageSize: 2}, 5, "")
+
+	if pagination.NextCursor != "" {
+		t.Errorf("NextCursor = %q, want empty", pagination.NextCursor)
+	}
+	if _, ok := pagination.Links["next_cursor"]; ok {
+		t.Error("last page has a next_cursor link")
+	}
+}
@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"bytes"
+	"log"
+	"login-api/internal/usecases"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PrivacyController struct {
+	privacyUseCase *usecases.PrivacyUseCase
+}
+
+func NewPrivacyController(privacyUseCase *usecases.PrivacyUseCase) *PrivacyController {
+	return &PrivacyController{privacyUseCase: privacyUseCase}
+}
+
+// @Summary Export personal data
+// @Description Download a zip with everything held about the user (subject access request): profile, role assignments, effective roles, groups, sessions, login history and avatar
+// @Tags privacy
+// @Produce application/zip
+// @Param id path string true "User ID"
+// @Success 200 {file} file "Zip archive"
+// @Failure 404 {object} ErrorResponse "User Not Found"
+// @Router /users/{id}/personal-data [get]
+func (ctrl *PrivacyController) ExportPersonalData(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	// Escreve primeiro em memória para ainda poder responder 404/500 com JSON.
+	var archive bytes.Buffer
+	if err := ctrl.privacyUseCase.Export(id, &archive); err != nil {
+		if err.Error() == "user not found" {
+			c.JSON(404, ErrorResponse{Error: "User not found"})
+			return
+		}
+		log.Println("Personal data export failed:", err)
+		c.JSON(500, ErrorResponse{Error: "Failed to export personal data"})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="user-`+c.Param("id")+`-personal-data.zip"`)
+	c.Data(http.StatusOK, "application/zip", archive.Bytes())
+}
+
+// @Summary Erase user
+// @Description Anonymize the user record (right to erasure), remove role and group links, avatar, sessions and login history, revoke gateway access and publish user.erased so other services scrub their data
+// @Tags privacy
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} Response{message=string} "Success"
+// @Failure 404 {object} ErrorResponse "User Not Found"
+// @Failure 409 {object} ErrorResponse "User Already Erased"
+// @Router /users/{id}/erase [post]
+func (ctrl *PrivacyController) EraseUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	if err := ctrl.privacyUseCase.Erase(id, c.GetString("Email")); err != nil {
+		switch err.Error() {
+		case "user not found":
+			c.JSON(404, ErrorResponse{Error: "User not found"})
+		case "user already erased":
+			c.JSON(409, ErrorResponse{Error: "User already erased"})
+		default:
+			c.JSON(500, ErrorResponse{Error: "Failed to erase user"})
+		}
+		return
+	}
+
+	c.JSON(200, Response{
+		Message: "User successfully erased",
+	})
+}
@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"login-api/internal/usecases"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TokenController struct {
+	tokenUseCase *usecases.TokenUseCase
+}
+
+func NewTokenController(tokenUseCase *usecases.TokenUseCase) *TokenController {
+	return &TokenController{tokenUseCase: tokenUseCase}
+}
+
+type IntrospectionRequest struct {
+	Token string `json:"token" form:"token" binding:"required"`
+}
+
+// @Summary Introspect token
+// @Description Returns whether a token is active (RFC 7662), considering signature, expiration and revocation.
+// @Tags Authentication
+// @Accept x-www-form-urlencoded,json
+// @Produce json
+// @Param token formData string true "Token to introspect"
+// @Success 200 {object} models.TokenIntrospection "Introspection result"
+// @Failure 400 {object} ErrorResponse "Token is required"
+// @Router /introspect [post]
+func (ctrl *TokenController) Introspect(c *gin.Context) {
+	var request IntrospectionRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(400, ErrorResponse{Error: "Token is required"})
+		return
+	}
+
+	// A RFC 7662 define a resposta como o próprio objeto, sem o envelope Response.
+	c.JSON(200, ctrl.tokenUseCase.Introspect(request.Token))
+}
+
+// @Summary Logout
+// @Description Revokes the token used in the request.
+// @Tags Authentication
+// @Produce json
+// @Success 200 {object} Response{message=string} "Token revoked"
+// @Failure 401 {object} ErrorResponse "Invalid token"
+// @Router /logout [post]
+func (ctrl *TokenController) Logout(c *gin.Context) {
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if err := ctrl.tokenUseCase.Revoke(token); err != nil {
+		c.JSON(401, ErrorResponse{Error: "Invalid token"})
+		return
+	}
+
+	c.JSON(200, Response{Message: "Token successfully revoked"})
+}
+
+// @Summary Refresh token
+// @Description Issues a new token with the user's currently valid roles and revokes the one used in the request. Expired role assignments are left out.
+// @Tags Authentication
+// @Produce json
+// @Success 200 {object} models.TokenRefresh "New token and user information"
+// @Failure 401 {object} ErrorResponse "Invalid token"
+// @Router /refresh [post]
+func (ctrl *TokenController) Refresh(c *gin.Context) {
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	refreshed, err := ctrl.tokenUseCase.Refresh(token)
+	if err != nil {
+		c.JSON(401, ErrorResponse{Error: "Invalid token"})
+		return
+	}
+
+	c.JSON(200, refreshed)
+}
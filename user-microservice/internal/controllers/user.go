@@ -7,9 +7,18 @@
 package controllers
 
 import (
+	"errors"
+	"io"
+	"log"
+	"login-api/internal/dto"
+	"login-api/internal/export"
+	"login-api/internal/repositories"
 	"login-api/internal/usecases"
 	models "login-api/models"
+	"shared/etag"
+	"shared/representation"
 	"strconv"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 
@@ -25,16 +34,48 @@ func NewUserController(userUseCase *usecases.UserUseCase) *UserController {
 }
 
 // @Summary Get all users
-// @Description Get a list of all users with their roles
+// @Description Get a paginated list of users with their roles. Supports offset (page/page_size) or cursor pagination.
 // @Tags users
 // @Accept json
 // @Produce json
-// @Success 200 {object} Response{data=[]domains.User} "Success"
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor returned as next_cursor; replaces page"
+// @Param name query string false "Filter by name (partial match)"
+// @Param email query string false "Filter by email (partial match)"
+// @Param role query string false "Filter by role name"
+// @Param status query string false "Filter by account status"
+// @Param registered_from query string false "Registered on or after (YYYY-MM-DD or RFC 3339)"
+// @Param registered_to query string false "Registered on or before (YYYY-MM-DD or RFC 3339)"
+// @Param sort query string false "id, name, email or register_date; prefix with - for descending"
+// @Param include_deleted query bool false "Include soft-deleted users"
+// @Param fields query string false "Comma-separated fields to return, e.g. id,name,email"
+// @Param include query string false "Expand related resources: roles"
+// @Success 200 {object} Response{data=[]dto.UserWithRoles,pagination=Pagination} "Success"
+// @Failure 400 {object} ErrorResponse "Invalid query parameters"
 // @Failure 500 {object} ErrorResponse "Internal Server Error"
 // @Router /users [get]
 func (ctrl *UserController) GetUsers(c *gin.Context) {
-	users, err := ctrl.userUseCase.GetAll()
+	query, errorMessages := parseUserListQuery(c)
+	if len(errorMessages) > 0 {
+		c.JSON(400, ErrorResponse{
+			Errors: errorMessages,
+		})
+		return
+	}
+	options, ok := userOptions(c)
+	if !ok {
+		return
+	}
+
+	page, err := ctrl.userUseCase.GetPage(query)
 	if err != nil {
+		if errors.Is(err, repositories.ErrInvalidCursor) {
+			c.JSON(400, ErrorResponse{
+				Error: "Invalid cursor",
+			})
+			return
+		}
 		c.JSON(500, ErrorResponse{
 			Error: "Failed to retrieve users",
 		})
@@ -42,7 +83,8 @@ func (ctrl *UserController) GetUsers(c *gin.Context) {
 	}
 
 	c.JSON(200, Response{
-		Data: users,
+		Data:       dto.UserViews(options, page.Users),
+		Pagination: buildPagination(c, query, page.Total, page.NextCursor),
 	})
 }
 
@@ -52,7 +94,11 @@ func (ctrl *UserController) GetUsers(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "User ID"
-// @Success 200 {object} Response{data=domains.User} "Success"
+// @Param fields query string false "Comma-separated fields to return, e.g. id,name,email"
+// @Param include query string false "Expand related resources: roles"
+// @Param If-None-Match header string false "ETag from a previous response"
+// @Success 200 {object} Response{data=dto.UserWithRoles} "Success"
+// @Success 304 "Not Modified"
 // @Failure 404 {object} ErrorResponse{error=string} "User Not Found"
 // @Router /users/{id} [get]
 func (ctrl *UserController) GetUser(c *gin.Context) {
@@ -64,6 +110,11 @@ func (ctrl *UserController) GetUser(c *gin.Context) {
 		return
 	}
 
+	options, ok := userOptions(c)
+	if !ok {
+		return
+	}
+
 	user, err := ctrl.userUseCase.GetByID(id)
 	if err != nil {
 		c.JSON(404, ErrorResponse{
@@ -71,9 +122,12 @@ func (ctrl *UserController) GetUser(c *gin.Context) {
 		})
 		return
 	}
+	if etag.NotModified(c, user.Version, options.Key()) {
+		return
+	}
 
 	c.JSON(200, Response{
-		Data: user,
+		Data: dto.UserView(options, user),
 	})
 }
 
@@ -82,15 +136,15 @@ func (ctrl *UserController) GetUser(c *gin.Context) {
 // @Tags users
 // @Accept json
 // @Produce json
-// @Param user body domains.User true "User information"
-// @Success 200 {object} Response{data=domains.User} "Success"
+// @Param user body models.UserCreateRequest true "User information"
+// @Success 200 {object} Response{data=dto.User} "Success"
 // @Failure 400 {object} ErrorResponse "Validation Error"
 // @Failure 409 {object} ErrorResponse "User Already Exists"
 // @Failure 500 {object} ErrorResponse "Internal Server Error"
 // @Router /users [post]
 func (ctrl *UserController) CreateUser(c *gin.Context) {
-	var user models.User
-	if err := c.ShouldBindJSON(&user); err != nil {
+	var request models.UserCreateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
 		if validationErrors, ok := err.(validator.ValidationErrors); ok {
 			errorMessages := make(map[string]string)
 			for _, fieldErr := range validationErrors {
@@ -103,6 +157,8 @@ func (ctrl *UserController) CreateUser(c *gin.Context) {
 					} else if fieldErr.Tag() == "email" {
 						errorMessages["email"] = "Invalid email format"
 					}
+				case "Password":
+					errorMessages["password"] = "Password is required"
 				}
 			}
 			c.JSON(400, ErrorResponse{
@@ -110,8 +166,18 @@ func (ctrl *UserController) CreateUser(c *gin.Context) {
 			})
 			return
 		}
+		c.JSON(400, ErrorResponse{
+			Error: "Invalid data provided",
+		})
+		return
 	}
 
+	// Só os campos do request chegam ao usuário; o resto é definido pelo Create.
+	user := models.User{
+		Name:     request.Name,
+		Email:    request.Email,
+		Password: request.Password,
+	}
 	err := ctrl.userUseCase.Create(&user)
 	if err != nil {
 		if err.Error() == "user already registered" {
@@ -126,8 +192,9 @@ func (ctrl *UserController) CreateUser(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", etag.Tag(user.Version))
 	c.JSON(200, Response{
-		Data: user,
+		Data: dto.NewUser(&user),
 	})
 }
 
@@ -137,10 +204,13 @@ func (ctrl *UserController) CreateUser(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "User ID"
+// @Param If-Match header string true "ETag of the version being replaced"
 // @Param user body domains.User true "Updated user information"
-// @Success 200 {object} Response{data=domains.User} "Success"
+// @Success 200 {object} Response{data=dto.User} "Success"
 // @Failure 404 {object} ErrorResponse{error=string} "User Not Found"
 // @Failure 400 {object} ErrorResponse{error=string} "Invalid Data"
+// @Failure 412 {object} ErrorResponse{error=string} "Version Mismatch"
+// @Failure 428 {object} ErrorResponse{error=string} "If-Match Required"
 // @Router /users/{id} [put]
 func (ctrl *UserController) UpdateUser(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
@@ -151,6 +221,11 @@ func (ctrl *UserController) UpdateUser(c *gin.Context) {
 		return
 	}
 
+	version, ok := etag.IfMatch(c)
+	if !ok {
+		return
+	}
+
 	var user models.User
 	if err := c.ShouldBindJSON(&user); err != nil {
 		c.JSON(400, ErrorResponse{
@@ -159,7 +234,7 @@ func (ctrl *UserController) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	err = ctrl.userUseCase.Update(id, &user)
+	updated, err := ctrl.userUseCase.Update(id, &user, version)
 	if err != nil {
 		if err.Error() == "user not found" {
 			c.JSON(404, ErrorResponse{
@@ -167,25 +242,122 @@ func (ctrl *UserController) UpdateUser(c *gin.Context) {
 			})
 			return
 		}
+		if errors.Is(err, repositories.ErrVersionConflict) {
+			etag.PreconditionFailed(c)
+			return
+		}
 		c.JSON(500, ErrorResponse{
 			Error: "Failed to update user",
 		})
 		return
 	}
 
+	c.Header("ETag", etag.Tag(updated.Version))
 	c.JSON(200, Response{
-		Data: user,
+		Data: dto.NewUser(updated),
+	})
+}
+
+// @Summary Partially update user
+// @Description Update a user with a JSON Merge Patch (RFC 7396). Only name, email and service_account can be changed; fields not sent are left untouched
+// @Tags users
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param If-Match header string true "ETag of the version being patched"
+// @Param patch body object true "Merge patch, e.g. {\"name\": \"New name\"}"
+// @Success 200 {object} Response{data=dto.User} "Success"
+// @Failure 400 {object} ErrorResponse "Invalid Patch"
+// @Failure 404 {object} ErrorResponse{error=string} "User Not Found"
+// @Failure 409 {object} ErrorResponse{error=string} "Email Already In Use"
+// @Failure 412 {object} ErrorResponse{error=string} "Version Mismatch"
+// @Failure 415 {object} ErrorResponse{error=string} "Unsupported Media Type"
+// @Failure 428 {object} ErrorResponse{error=string} "If-Match Required"
+// @Router /users/{id} [patch]
+func (ctrl *UserController) PatchUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, ErrorResponse{
+			Error: "Invalid user ID",
+		})
+		return
+	}
+
+	version, ok := etag.IfMatch(c)
+	if !ok {
+		return
+	}
+
+	contentType := c.ContentType()
+	if contentType != "application/merge-patch+json" && contentType != "application/json" {
+		c.JSON(415, ErrorResponse{
+			Error: "Content-Type must be application/merge-patch+json",
+		})
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(400, ErrorResponse{
+			Error: "Invalid data provided",
+		})
+		return
+	}
+
+	fields, errorMessages, err := parseUserMergePatch(body)
+	if err != nil {
+		c.JSON(400, ErrorResponse{
+			Error: "Merge patch must be a JSON object",
+		})
+		return
+	}
+	if len(errorMessages) > 0 {
+		c.JSON(400, ErrorResponse{
+			Errors: errorMessages,
+		})
+		return
+	}
+
+	user, err := ctrl.userUseCase.Patch(id, fields, version)
+	if errors.Is(err, repositories.ErrVersionConflict) {
+		etag.PreconditionFailed(c)
+		return
+	}
+	if err != nil {
+		switch err.Error() {
+		case "user not found":
+			c.JSON(404, ErrorResponse{
+				Error: "User not found",
+			})
+		case "user already registered":
+			c.JSON(409, ErrorResponse{
+				Error: "Another user is registered with this email",
+			})
+		default:
+			c.JSON(500, ErrorResponse{
+				Error: "Failed to update user",
+			})
+		}
+		return
+	}
+
+	c.Header("ETag", etag.Tag(user.Version))
+	c.JSON(200, Response{
+		Data: dto.NewUser(user),
 	})
 }
 
 // @Summary Delete user
-// @Description Delete a user by their ID
+// @Description Soft delete a user by their ID. Access is revoked immediately and the user is purged after the retention period
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param id path string true "User ID"
+// @Param If-Match header string true "ETag of the version being deleted"
 // @Success 200 {object} Response{message=string} "Success"
 // @Failure 404 {object} ErrorResponse{error=string} "User Not Found"
+// @Failure 412 {object} ErrorResponse{error=string} "Version Mismatch"
+// @Failure 428 {object} ErrorResponse{error=string} "If-Match Required"
 // @Failure 500 {object} ErrorResponse{error=string} "Delete Failed"
 // @Router /users/{id} [delete]
 func (ctrl *UserController) DeleteUser(c *gin.Context) {
@@ -197,7 +369,12 @@ func (ctrl *UserController) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	err = ctrl.userUseCase.Delete(id)
+	version, ok := etag.IfMatch(c)
+	if !ok {
+		return
+	}
+
+	err = ctrl.userUseCase.Delete(id, version)
 	if err != nil {
 		if err.Error() == "user not found" {
 			c.JSON(404, ErrorResponse{
@@ -205,6 +382,10 @@ func (ctrl *UserController) DeleteUser(c *gin.Context) {
 			})
 			return
 		}
+		if errors.Is(err, repositories.ErrVersionConflict) {
+			etag.PreconditionFailed(c)
+			return
+		}
 		c.JSON(500, ErrorResponse{
 			Error: "Failed to delete user",
 		})
@@ -216,20 +397,310 @@ func (ctrl *UserController) DeleteUser(c *gin.Context) {
 	})
 }
 
+// @Summary Restore user
+// @Description Restore a soft-deleted user before it is purged
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} Response{data=dto.User} "Success"
+// @Failure 404 {object} ErrorResponse{error=string} "Deleted User Not Found"
+// @Failure 409 {object} ErrorResponse{error=string} "Email Already In Use"
+// @Failure 500 {object} ErrorResponse{error=string} "Restore Failed"
+// @Router /users/{id}/restore [post]
+func (ctrl *UserController) RestoreUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, ErrorResponse{
+			Error: "Invalid user ID",
+		})
+		return
+	}
+
+	user, err := ctrl.userUseCase.Restore(id)
+	if err != nil {
+		switch err.Error() {
+		case "user not found":
+			c.JSON(404, ErrorResponse{
+				Error: "Deleted user not found",
+			})
+		case "user already registered":
+			c.JSON(409, ErrorResponse{
+				Error: "Another user is registered with this email",
+			})
+		default:
+			c.JSON(500, ErrorResponse{
+				Error: "Failed to restore user",
+			})
+		}
+		return
+	}
+
+	c.JSON(200, Response{
+		Data: dto.NewUser(user),
+	})
+}
+
+// @Summary Change user status
+// @Description Move the account to pending, active, suspended, locked or deactivated. A reason is required. Leaving active revokes gateway access and existing tokens
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param status body models.UserStatusRequest true "New status and reason"
+// @Success 200 {object} Response{data=dto.User} "Success"
+// @Failure 400 {object} ErrorResponse "Invalid Status or Missing Reason"
+// @Failure 404 {object} ErrorResponse "User Not Found"
+// @Failure 409 {object} ErrorResponse "Transition Not Allowed"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /users/{id}/status [put]
+func (ctrl *UserController) ChangeUserStatus(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	var request models.UserStatusRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errorMessages := make(map[string]string)
+			for _, fieldErr := range validationErrors {
+				switch fieldErr.Field() {
+				case "Status":
+					errorMessages["status"] = "Status is required"
+				case "Reason":
+					errorMessages["reason"] = "Reason is required"
+				}
+			}
+			c.JSON(400, ErrorResponse{
+				Errors: errorMessages,
+			})
+			return
+		}
+		c.JSON(400, ErrorResponse{
+			Error: "Invalid data provided",
+		})
+		return
+	}
+
+	user, err := ctrl.userUseCase.ChangeStatus(id, request.Status, request.Reason, c.GetString("Email"))
+	if err != nil {
+		switch err.Error() {
+		case "invalid status":
+			c.JSON(400, ErrorResponse{Error: "Status must be one of pending, active, suspended, locked, deactivated"})
+		case "reason is required":
+			c.JSON(400, ErrorResponse{Errors: map[string]string{"reason": "Reason is required"}})
+		case "user not found":
+			c.JSON(404, ErrorResponse{Error: "User not found"})
+		case "invalid status transition":
+			c.JSON(409, ErrorResponse{Error: "Status transition not allowed"})
+		default:
+			c.JSON(500, ErrorResponse{Error: "Failed to change user status"})
+		}
+		return
+	}
+
+	c.JSON(200, Response{
+		Data: dto.NewUser(user),
+	})
+}
+
+// @Summary Export users
+// @Description Stream users with their roles, registration date and status as CSV, JSON Lines or XLSX. Accepts the same filters as the user list; rows are read in batches ordered by ID
+// @Tags users
+// @Produce text/csv,application/x-ndjson,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "csv (default), jsonl or xlsx"
+// @Param name query string false "Filter by name (partial match)"
+// @Param email query string false "Filter by email (partial match)"
+// @Param role query string false "Filter by role name"
+// @Param status query string false "Filter by account status"
+// @Param registered_from query string false "Registered on or after (YYYY-MM-DD or RFC 3339)"
+// @Param registered_to query string false "Registered on or before (YYYY-MM-DD or RFC 3339)"
+// @Param include_deleted query bool false "Include soft-deleted users"
+// @Success 200 {file} file "Export file. The X-Export-Status trailer is complete only when every row was written; a failed export closes the connection before the end of the body"
+// @Failure 400 {object} ErrorResponse "Invalid Filters or Format"
+// @Router /users/export [get]
+func (ctrl *UserController) ExportUsers(c *gin.Context) {
+	query, errorMessages := parseUserListQuery(c)
+	format := c.DefaultQuery("format", "csv")
+	spec, ok := export.Formats[format]
+	if !ok {
+		errorMessages["format"] = "Format must be one of csv, jsonl, xlsx"
+	}
+	if len(errorMessages) > 0 {
+		c.JSON(400, ErrorResponse{
+			Errors: errorMessages,
+		})
+		return
+	}
+
+	filename := "users-" + time.Now().Format("20060102-150405") + "." + spec.Extension
+	c.Header("Content-Type", spec.ContentType)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Header("Trailer", exportStatusTrailer)
+	c.Status(200)
+
+	writer, err := export.NewUserWriter(format, c.Writer)
+	if err == nil {
+		err = ctrl.userUseCase.Export(query, func(users []models.User) error {
+			for _, user := range users {
+				if err := writer.Write(export.NewUserRecord(user)); err != nil {
+					return err
+				}
+			}
+			c.Writer.Flush()
+			return nil
+		})
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		log.Println("Export failed:", err)
+		c.Abort()
+		abortExport(c)
+		return
+	}
+	c.Writer.Header().Set(exportStatusTrailer, "complete")
+}
+
+// exportStatusTrailer só vale "complete" quando o arquivo inteiro foi escrito.
+const exportStatusTrailer = "X-Export-Status"
+
+// abortExport encerra a resposta de uma exportação que falhou depois do status 200. No HTTP/1.1
+// a conexão é fechada sem o chunk final, e o cliente recebe um erro de leitura em vez de um
+// arquivo que parece completo. Sem hijack (HTTP/2), o trailer sai como "failed".
+func abortExport(c *gin.Context) {
+	c.Writer.Header().Set(exportStatusTrailer, "failed")
+	conn, _, err := c.Writer.Hijack()
+	if err != nil {
+		return
+	}
+	conn.Close()
+}
+
+// @Summary Replace user roles
+// @Description Replace the user's full role set with the given role IDs in a single transaction. An empty list removes every role
+// @Tags user-roles
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param roles body models.UserRolesRequest true "Desired role IDs"
+// @Success 200 {object} Response{data=dto.UserWithRoles} "Success"
+// @Failure 400 {object} ErrorResponse "Invalid Input"
+// @Failure 404 {object} ErrorResponse "User or Role Not Found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /users/{id}/roles [put]
+func (ctrl *UserController) ReplaceUserRoles(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	var request models.UserRolesRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(400, ErrorResponse{Error: "role_ids must be a list of role IDs"})
+		return
+	}
+
+	user, err := ctrl.userUseCase.ReplaceRoles(userID, request.RoleIDs, c.GetString("Email"))
+	withRoles := representation.Options{Include: map[string]bool{"roles": true}}
+	if err != nil {
+		var unknown *usecases.UnknownRolesError
+		switch {
+		case errors.As(err, &unknown):
+			errorMessages := make(map[string]string)
+			for _, id := range unknown.IDs {
+				errorMessages[strconv.FormatUint(id, 10)] = "Role not found"
+			}
+			c.JSON(404, ErrorResponse{Error: "Role not found", Errors: errorMessages})
+		case err.Error() == "user not found":
+			c.JSON(404, ErrorResponse{Error: "User not found"})
+		default:
+			c.JSON(500, ErrorResponse{Error: "Failed to replace user roles"})
+		}
+		return
+	}
+
+	c.JSON(200, Response{
+		Data: dto.UserView(withRoles, user),
+	})
+}
+
+// @Summary List expiring role assignments
+// @Description List time-bound role assignments that expire within the given number of days
+// @Tags user-roles
+// @Produce json
+// @Param days query int false "Look-ahead window in days (default 7)"
+// @Success 200 {object} Response{data=[]models.RoleAssignment} "Success"
+// @Failure 400 {object} ErrorResponse "Invalid Days"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /users/roles/expiring [get]
+func (ctrl *UserController) GetExpiringRoles(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "7"))
+	if err != nil || days < 1 {
+		c.JSON(400, ErrorResponse{Error: "Days must be a positive integer"})
+		return
+	}
+
+	assignments, err := ctrl.userUseCase.ExpiringRoles(time.Duration(days) * 24 * time.Hour)
+	if err != nil {
+		c.JSON(500, ErrorResponse{Error: "Failed to retrieve expiring roles"})
+		return
+	}
+
+	c.JSON(200, Response{
+		Data: assignments,
+	})
+}
+
+// @Summary Explain effective roles
+// @Description List the user's effective roles (direct assignments still valid plus group roles) and where each one comes from
+// @Tags user-roles
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} Response{data=[]models.EffectiveRole} "Success"
+// @Failure 404 {object} ErrorResponse "User Not Found"
+// @Router /users/{id}/roles/effective [get]
+func (ctrl *UserController) GetEffectiveRoles(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	roles, err := ctrl.userUseCase.EffectiveRoles(userID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			c.JSON(404, ErrorResponse{Error: "User not found"})
+			return
+		}
+		c.JSON(500, ErrorResponse{Error: "Failed to retrieve effective roles"})
+		return
+	}
+
+	c.JSON(200, Response{
+		Data: roles,
+	})
+}
+
 // @Summary Add role to user
-// @Description Associate a role with a user
+// @Description Associate a role with a user. The optional body limits the assignment to a period (valid_from/valid_until) and records the reason; the admin making the request is stored as granted_by
 // @Tags user-roles
 // @Accept json
 // @Produce json
-// @Param userId path string true "User ID"
+// @Param id path string true "User ID"
 // @Param roleId path string true "Role ID"
+// @Param grant body models.RoleGrant false "Validity period and reason"
 // @Success 200 {object} Response{message=string} "Success"
-// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 400 {object} ErrorResponse "Invalid ID or Validity Period"
 // @Failure 404 {object} ErrorResponse "User or Role Not Found"
 // @Failure 500 {object} ErrorResponse "Internal Server Error"
-// @Router /users/{userId}/roles/{roleId} [post]
+// @Router /users/{id}/roles/{roleId} [post]
 func (ctrl *UserController) AddRoleToUser(c *gin.Context) {
-	userID, err := strconv.ParseUint(c.Param("userId"), 10, 64)
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(400, ErrorResponse{Error: "Invalid user ID"})
 		return
@@ -237,11 +708,27 @@ func (ctrl *UserController) AddRoleToUser(c *gin.Context) {
 
 	roleID := c.Param("roleId")
 
-	err = ctrl.userUseCase.AddRoleToUser(userID, roleID)
+	var grant models.RoleGrant
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&grant); err != nil {
+			c.JSON(400, ErrorResponse{Error: "Invalid request body"})
+			return
+		}
+	}
+	grant.GrantedBy = c.GetString("Email")
+
+	err = ctrl.userUseCase.AddRoleToUser(userID, roleID, grant)
 	if err != nil {
-		if err.Error() == "user not found" {
+		switch err.Error() {
+		case "user not found":
 			c.JSON(404, ErrorResponse{Error: "User not found"})
 			return
+		case "role not found":
+			c.JSON(404, ErrorResponse{Error: "Role not found"})
+			return
+		case "invalid validity period":
+			c.JSON(400, ErrorResponse{Error: "valid_until must be in the future and after valid_from"})
+			return
 		}
 		c.JSON(500, ErrorResponse{Error: "Failed to associate role with user"})
 		return
@@ -287,3 +774,57 @@ func (ctrl *UserController) RemoveRoleFromUser(c *gin.Context) {
 		Message: "Role successfully removed to user",
 	})
 }
+
+// @Summary Change own password
+// @Description Change the password of the authenticated user. The current password must be informed; user.password_changed is published
+// @Tags me
+// @Accept json
+// @Produce json
+// @Param password body models.PasswordChangeRequest true "Current and new password (at least 8 characters)"
+// @Success 200 {object} Response{message=string} "Success"
+// @Failure 400 {object} ErrorResponse "Invalid Password"
+// @Failure 403 {object} ErrorResponse "Wrong Current Password"
+// @Failure 404 {object} ErrorResponse "User Not Found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /me/password [put]
+func (ctrl *UserController) ChangePassword(c *gin.Context) {
+	var request models.PasswordChangeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(400, ErrorResponse{Errors: map[string]string{"new_password": "Current password is required and the new one must have at least 8 characters"}})
+		return
+	}
+
+	err := ctrl.userUseCase.ChangePassword(c.GetString("Email"), request.CurrentPassword, request.NewPassword)
+	if errors.Is(err, usecases.ErrInvalidPassword) {
+		c.JSON(403, ErrorResponse{Errors: map[string]string{"current_password": "Current password is incorrect"}})
+		return
+	}
+	if errors.Is(err, repositories.ErrVersionConflict) {
+		c.JSON(409, ErrorResponse{Error: "User was modified concurrently, try again"})
+		return
+	}
+	if err != nil {
+		if err.Error() == "user not found" {
+			c.JSON(404, ErrorResponse{Error: "User not found"})
+			return
+		}
+		c.JSON(500, ErrorResponse{Error: "Failed to change password"})
+		return
+	}
+
+	c.JSON(200, Response{
+		Message: "Password successfully changed",
+	})
+}
+
+// userOptions lê ?fields= e ?include= e responde 400 quando pedem algo que o usuário não tem.
+func userOptions(c *gin.Context) (representation.Options, bool) {
+	options, errorMessages := representation.ParseOptions(c.Query("fields"), c.Query("include"), dto.UserFields, dto.UserIncludes)
+	if len(errorMessages) > 0 {
+		c.JSON(400, ErrorResponse{
+			Errors: errorMessages,
+		})
+		return options, false
+	}
+	return options, true
+}
@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"login-api/internal/repositories"
+	"login-api/internal/usecases"
+	models "login-api/models"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportUserRepository entrega um lote e depois devolve failAfter, se houver.
+type exportUserRepository struct {
+	repositories.UserRepository
+	failAfter error
+}
+
+func (r *exportUserRepository) FindInBatches(query models.UserListQuery, batchSize int, fn func(users []models.User) error) error {
+	if err := fn([]models.User{{ID: 1, Name: "Ana", Email: "ana@example.com"}}); err != nil {
+		return err
+	}
+	return r.failAfter
+}
+
+func exportThroughServer(t *testing.T, repo repositories.UserRepository) (string, string, error) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.GET("/user/export", NewUserController(usecases.NewUserUseCase(repo, nil)).ExportUsers)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	response, err := server.Client().Get(server.URL + "/user/export?format=csv")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	return string(body), response.Trailer.Get("X-Export-Status"), err
+}
+
+func TestExportUsersCompletes(t *testing.T) {
+	body, status, err := exportThroughServer(t, &exportUserRepository{})
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	if !strings.Contains(body, "ana@example.com") {
+		t.Errorf("body = %q", body)
+	}
+	if status != "complete" {
+		t.Errorf("X-Export-Status = %q, want complete", status)
+	}
+}
+
+func TestExportUsersFailureIsVisibleToClient(t *testing.T) {
+	_, status, err := exportThroughServer(t, &exportUserRepository{failAfter: errors.New("connection reset")})
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("read body: err = %v, want unexpected EOF", err)
+	}
+	if status == "complete" {
+		t.Error("failed export was marked complete")
+	}
+}
@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"login-api/internal/usecases"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxImportSize = 10 << 20
+
+// @Summary Import users
+// @Description Bulk create users from a CSV (columns name, email, roles separated by ";") or JSON array. Every row is validated and the import runs in a single transaction. Users are created as pending and receive an invitation to set their password
+// @Tags users
+// @Accept multipart/form-data,text/csv,json
+// @Produce json
+// @Param file formData file false "CSV or JSON file (alternatively send it as the raw body)"
+// @Param dry_run query bool false "Only validate, do not create users"
+// @Success 200 {object} Response{data=models.UserImportResult} "Dry run report"
+// @Success 201 {object} Response{data=models.UserImportResult} "Users created and invited"
+// @Failure 400 {object} ErrorResponse{error=string} "Unreadable File"
+// @Failure 422 {object} Response{data=models.UserImportResult} "Invalid Rows, Nothing Imported"
+// @Failure 500 {object} ErrorResponse{error=string} "Import Failed"
+// @Router /users/import [post]
+func (ctrl *InvitationController) ImportUsers(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+
+	data, format, err := readImportFile(c)
+	if err != nil {
+		c.JSON(400, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	rows, err := usecases.ParseUserImport(data, format)
+	if err != nil {
+		c.JSON(400, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	dryRun := c.Query("dry_run") == "true"
+	result, err := ctrl.invitationUseCase.Import(rows, dryRun, c.GetString("Email"))
+	if err != nil {
+		if errors.Is(err, usecases.ErrImportHasErrors) {
+			c.JSON(422, Response{
+				Error: "Import has invalid rows, no user was created",
+				Data:  result,
+			})
+			return
+		}
+		c.JSON(500, ErrorResponse{
+			Error: "Failed to import users",
+		})
+		return
+	}
+
+	status := 201
+	if dryRun {
+		status = 200
+	}
+	c.JSON(status, Response{
+		Data: result,
+	})
+}
+
+// readImportFile aceita o arquivo no campo "file" de um multipart ou o corpo cru,
+// deduzindo o formato pela extensão ou pelo Content-Type.
+func readImportFile(c *gin.Context) ([]byte, string, error) {
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, "", errors.New("file is required")
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, "", errors.New("failed to read file")
+		}
+		defer file.Close()
+
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return nil, "", errors.New("failed to read file")
+		}
+		return data, importFormat(strings.TrimPrefix(strings.ToLower(filepath.Ext(header.Filename)), "."), header.Header.Get("Content-Type")), nil
+	}
+
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, "", errors.New("failed to read body, the limit is 10MB")
+	}
+	return data, importFormat(c.Query("format"), c.ContentType()), nil
+}
+
+func importFormat(extension, contentType string) string {
+	switch {
+	case extension == "csv" || strings.Contains(contentType, "csv"):
+		return "csv"
+	case extension == "json" || strings.Contains(contentType, "json"):
+		return "json"
+	}
+	return extension
+}
@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"login-api/internal/repositories"
+	"login-api/internal/usecases"
+	models "login-api/models"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// createUserRepository guarda o usuário recebido por Create.
+type createUserRepository struct {
+	repositories.UserRepository
+	created *models.User
+}
+
+func (r *createUserRepository) FindByEmail(email string) (*models.User, error) {
+	return nil, errors.New("record not found")
+}
+
+func (r *createUserRepository) FindByID(id uint64) (*models.User, error) {
+	return r.created, nil
+}
+
+func (r *createUserRepository) Create(user *models.User) error {
+	user.ID = 1
+	copied := *user
+	r.created = &copied
+	return nil
+}
+
+func (r *createUserRepository) Transaction(fn func(repo repositories.UserRepository) error) error {
+	return fn(r)
+}
+
+func (r *createUserRepository) Outbox() repositories.OutboxRepository {
+	return &discardOutbox{}
+}
+
+type discardOutbox struct {
+	repositories.OutboxRepository
+}
+
+func (o *discardOutbox) Add(event *models.OutboxEvent) error { return nil }
+
+func (o *discardOutbox) CorrelationID() string { return "correlation" }
+
+func postUser(repo repositories.UserRepository, body string) int {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	router := gin.New()
+	router.POST("/users", NewUserController(usecases.NewUserUseCase(repo, nil)).CreateUser)
+	request := httptest.NewRequest("POST", "/users", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(recorder, request)
+	return recorder.Code
+}
+
+func TestCreateUserIgnoresServerManagedFields(t *testing.T) {
+	repo := &createUserRepository{}
+	code := postUser(repo, `{"name": "Ana", "email": "ana@example.com", "password": "s3cret",
+		"status": "active", "status_reason": "x", "version": 99, "erased_at": "2024-01-01T00:00:00Z",
+		"service_account": true, "roles": [{"ID": 1, "name": "Admin"}]}`)
+	if code != 200 {
+		t.Fatalf("status %d, want 200", code)
+	}
+
+	user := repo.created
+	if user.Name != "Ana" || user.Email != "ana@example.com" || user.Password != "s3cret" {
+		t.Errorf("user = %+v", user)
+	}
+	if user.Version != 0 || user.StatusReason != "" || user.ErasedAt != nil || user.ServiceAccount || len(user.Roles) != 0 {
+		t.Errorf("server-managed fields were assigned from the request: %+v", user)
+	}
+}
+
+func TestCreateUserRequiresPassword(t *testing.T) {
+	repo := &createUserRepository{}
+	if code := postUser(repo, `{"name": "Ana", "email": "ana@example.com"}`); code != 400 {
+		t.Errorf("status %d, want 400", code)
+	}
+	if repo.created != nil {
+		t.Error("user was created without a password")
+	}
+}
+
+func TestChangeUserStatusReportsOnlyTheFailingField(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &createUserRepository{created: &models.User{ID: 1, Email: "ana@example.com", Status: models.UserStatusActive}}
+	router := gin.New()
+	router.PUT("/user/:id/status", NewUserController(usecases.NewUserUseCase(repo, nil)).ChangeUserStatus)
+
+	for _, tc := range []struct {
+		body string
+		want map[string]string
+	}{
+		{`{"status": "suspended"}`, map[string]string{"reason": "Reason is required"}},
+		{`{"reason": "fraude"}`, map[string]string{"status": "Status is required"}},
+		{`{"status": "suspended", "reason": "   "}`, map[string]string{"reason": "Reason is required"}},
+	} {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest("PUT", "/user/1/status", strings.NewReader(tc.body))
+		request.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(recorder, request)
+
+		var response ErrorResponse
+		json.Unmarshal(recorder.Body.Bytes(), &response)
+		if recorder.Code != 400 || !reflect.DeepEqual(response.Errors, tc.want) {
+			t.Errorf("%s: status %d, errors %v, want 400 and %v", tc.body, recorder.Code, response.Errors, tc.want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"login-api/models"
+	"time"
+)
+
+type Invitation struct {
+	ID         uint64     `json:"id"`
+	UserID     uint64     `json:"user_id"`
+	Email      string     `json:"email"`
+	Name       string     `json:"name"`
+	Roles      []Role     `json:"roles"`
+	InvitedBy  string     `json:"invited_by"`
+	Status     string     `json:"status"`
+	SentAt     time.Time  `json:"sent_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// NewInvitation devolve o convite com o status calculado: pending, expired ou accepted.
+func NewInvitation(invitation *models.Invitation) Invitation {
+	status := "pending"
+	switch {
+	case invitation.AcceptedAt != nil:
+		status = "accepted"
+	case time.Now().After(invitation.ExpiresAt):
+		status = "expired"
+	}
+
+	return Invitation{
+		ID:         invitation.ID,
+		UserID:     invitation.UserID,
+		Email:      invitation.Email,
+		Name:       invitation.Name,
+		Roles:      NewRoles(invitation.User.Roles),
+		InvitedBy:  invitation.InvitedBy,
+		Status:     status,
+		SentAt:     invitation.SentAt,
+		ExpiresAt:  invitation.ExpiresAt,
+		AcceptedAt: invitation.AcceptedAt,
+		CreatedAt:  invitation.CreatedAt,
+	}
+}
+
+func NewInvitations(invitations []models.Invitation) []Invitation {
+	response := make([]Invitation, len(invitations))
+	for i := range invitations {
+		response[i] = NewInvitation(&invitations[i])
+	}
+	return response
+}
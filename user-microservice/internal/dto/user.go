@@ -0,0 +1,117 @@
+// Package dto define as representações devolvidas pela API, separadas dos modelos do
+// GORM para que campos internos, como a senha, nunca sejam serializados.
+package dto
+
+import (
+	"login-api/models"
+	"shared/representation"
+	"time"
+)
+
+// UserFields são os campos aceitos em ?fields= e UserIncludes as expansões de ?include=.
+var (
+	UserFields   = []string{"id", "name", "email", "register_date", "service_account", "status", "status_reason", "status_changed_at", "last_login_at", "avatar_updated_at", "erased_at", "deleted_at", "version"}
+	UserIncludes = []string{"roles"}
+)
+
+type User struct {
+	ID              uint64     `json:"id"`
+	Name            string     `json:"name"`
+	Email           string     `json:"email"`
+	RegisterDate    time.Time  `json:"register_date"`
+	ServiceAccount  bool       `json:"service_account"`
+	Status          string     `json:"status"`
+	StatusReason    string     `json:"status_reason,omitempty"`
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty"`
+	LastLoginAt     *time.Time `json:"last_login_at,omitempty"`
+	AvatarUpdatedAt *time.Time `json:"avatar_updated_at,omitempty"`
+	ErasedAt        *time.Time `json:"erased_at,omitempty"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
+	Version         uint64     `json:"version"`
+	// APIKey só aparece na resposta que criou a credencial da conta de serviço.
+	APIKey string `json:"api_key,omitempty"`
+}
+
+// UserWithRoles é o usuário com ?include=roles.
+type UserWithRoles struct {
+	User
+	Roles []Role `json:"roles"`
+}
+
+type Role struct {
+	ID   uint64 `json:"id"`
+	Name string `json:"name"`
+}
+
+type Group struct {
+	ID          uint64 `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Roles       []Role `json:"roles"`
+}
+
+func NewUser(user *models.User) User {
+	response := User{
+		ID:              user.ID,
+		Name:            user.Name,
+		Email:           user.Email,
+		RegisterDate:    user.RegisterDate,
+		ServiceAccount:  user.ServiceAccount,
+		Status:          user.Status,
+		StatusReason:    user.StatusReason,
+		StatusChangedAt: user.StatusChangedAt,
+		LastLoginAt:     user.LastLoginAt,
+		AvatarUpdatedAt: user.AvatarUpdatedAt,
+		ErasedAt:        user.ErasedAt,
+		Version:         user.Version,
+		APIKey:          user.APIKey,
+	}
+	if response.Status == "" {
+		response.Status = models.UserStatusActive
+	}
+	if user.DeletedAt.Valid {
+		response.DeletedAt = &user.DeletedAt.Time
+	}
+	return response
+}
+
+// UserView monta a representação do usuário conforme as opções do cliente.
+func UserView(options representation.Options, user *models.User) interface{} {
+	if !options.Include["roles"] {
+		return options.Sparse(NewUser(user))
+	}
+	return options.Sparse(UserWithRoles{User: NewUser(user), Roles: NewRoles(user.Roles)})
+}
+
+func UserViews(options representation.Options, users []models.User) interface{} {
+	views := make([]interface{}, len(users))
+	for i := range users {
+		views[i] = UserView(options, &users[i])
+	}
+	return views
+}
+
+func NewRoles(roles []models.Role) []Role {
+	response := make([]Role, len(roles))
+	for i, role := range roles {
+		response[i] = Role{ID: role.ID, Name: role.Name}
+	}
+	return response
+}
+
+func NewGroup(group *models.Group) Group {
+	return Group{
+		ID:          group.ID,
+		Name:        group.Name,
+		Description: group.Description,
+		Roles:       NewRoles(group.Roles),
+	}
+}
+
+func NewGroups(groups []models.Group) []Group {
+	response := make([]Group, len(groups))
+	for i := range groups {
+		response[i] = NewGroup(&groups[i])
+	}
+	return response
+}
@@ -0,0 +1,278 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeKong é uma Admin API do Kong em memória, com só os endpoints usados pelo provisioner.
+// As listagens são paginadas de dois em dois para exercitar o campo next.
+type fakeKong struct {
+	mu        sync.Mutex
+	nextID    int
+	consumers map[string]*KongConsumer
+	jwt       map[string][]KongJWTCredential
+	keyAuth   map[string][]KongKeyAuthCredential
+	acls      map[string][]KongACLGroup
+	writes    int
+}
+
+func newFakeKong(t *testing.T) (*fakeKong, *KongAdminClient) {
+	kong := &fakeKong{
+		consumers: make(map[string]*KongConsumer),
+		jwt:       make(map[string][]KongJWTCredential),
+		keyAuth:   make(map[string][]KongKeyAuthCredential),
+		acls:      make(map[string][]KongACLGroup),
+	}
+	server := httptest.NewServer(kong)
+	t.Cleanup(server.Close)
+	return kong, NewKongAdminClient(server.URL)
+}
+
+func (k *fakeKong) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if r.Method != http.MethodGet {
+		k.writes++
+	}
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if parts[0] != "consumers" {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		var consumers []KongConsumer
+		for _, consumer := range k.consumers {
+			if customID := r.URL.Query().Get("custom_id"); customID != "" && consumer.CustomID != customID {
+				continue
+			}
+			if tag := r.URL.Query().Get("tags"); tag != "" && !containsString(consumer.Tags, tag) {
+				continue
+			}
+			consumers = append(consumers, *consumer)
+		}
+		sort.Slice(consumers, func(i, j int) bool { return consumers[i].ID < consumers[j].ID })
+		writePage(w, r, consumers)
+	case len(parts) == 1 && r.Method == http.MethodPost:
+		var consumer KongConsumer
+		json.NewDecoder(r.Body).Decode(&consumer)
+		for _, existing := range k.consumers {
+			if existing.Username == consumer.Username || existing.CustomID == consumer.CustomID {
+				http.Error(w, `{"message":"unique constraint violation"}`, http.StatusConflict)
+				return
+			}
+		}
+		consumer.ID = k.newID("consumer")
+		k.consumers[consumer.ID] = &consumer
+		writeJSON(w, http.StatusCreated, consumer)
+	case len(parts) == 2:
+		consumer := k.findConsumer(parts[1])
+		if consumer == nil {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.Method {
+		case http.MethodPatch:
+			var update KongConsumer
+			json.NewDecoder(r.Body).Decode(&update)
+			consumer.Username = update.Username
+			consumer.CustomID = update.CustomID
+			consumer.Tags = update.Tags
+			writeJSON(w, http.StatusOK, consumer)
+		case http.MethodDelete:
+			delete(k.consumers, consumer.ID)
+			delete(k.jwt, consumer.ID)
+			delete(k.keyAuth, consumer.ID)
+			delete(k.acls, consumer.ID)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	case len(parts) >= 3:
+		consumer := k.findConsumer(parts[1])
+		if consumer == nil {
+			http.NotFound(w, r)
+			return
+		}
+		k.serveCredentials(w, r, consumer.ID, parts[2], parts[3:])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (k *fakeKong) serveCredentials(w http.ResponseWriter, r *http.Request, consumerID, kind string, rest []string) {
+	switch {
+	case kind == "jwt" && len(rest) == 0 && r.Method == http.MethodGet:
+		writePage(w, r, k.jwt[consumerID])
+	case kind == "jwt" && len(rest) == 1 && r.Method == http.MethodPut:
+		var credential KongJWTCredential
+		json.NewDecoder(r.Body).Decode(&credential)
+		credential.Key = rest[0]
+		for i, existing := range k.jwt[consumerID] {
+			if existing.Key == credential.Key || existing.ID == credential.Key {
+				credential.ID = existing.ID
+				k.jwt[consumerID][i] = credential
+				writeJSON(w, http.StatusOK, credential)
+				return
+			}
+		}
+		credential.ID = k.newID("jwt")
+		k.jwt[consumerID] = append(k.jwt[consumerID], credential)
+		writeJSON(w, http.StatusOK, credential)
+	case kind == "jwt" && len(rest) == 1 && r.Method == http.MethodDelete:
+		credentials := k.jwt[consumerID]
+		for i, existing := range credentials {
+			if existing.ID == rest[0] || existing.Key == rest[0] {
+				k.jwt[consumerID] = append(credentials[:i:i], credentials[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	case kind == "key-auth" && len(rest) == 0 && r.Method == http.MethodGet:
+		writePage(w, r, k.keyAuth[consumerID])
+	case kind == "key-auth" && len(rest) == 0 && r.Method == http.MethodPost:
+		var credential KongKeyAuthCredential
+		json.NewDecoder(r.Body).Decode(&credential)
+		credential.ID = k.newID("key-auth")
+		if credential.Key == "" {
+			credential.Key = "generated-" + credential.ID
+		}
+		k.keyAuth[consumerID] = append(k.keyAuth[consumerID], credential)
+		writeJSON(w, http.StatusCreated, credential)
+	case kind == "key-auth" && len(rest) == 1 && r.Method == http.MethodDelete:
+		credentials := k.keyAuth[consumerID]
+		for i, existing := range credentials {
+			if existing.ID == rest[0] {
+				k.keyAuth[consumerID] = append(credentials[:i:i], credentials[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	case kind == "acls" && len(rest) == 0 && r.Method == http.MethodGet:
+		writePage(w, r, k.acls[consumerID])
+	case kind == "acls" && len(rest) == 0 && r.Method == http.MethodPost:
+		var group KongACLGroup
+		json.NewDecoder(r.Body).Decode(&group)
+		for _, existing := range k.acls[consumerID] {
+			if existing.Group == group.Group {
+				http.Error(w, `{"message":"unique constraint violation"}`, http.StatusConflict)
+				return
+			}
+		}
+		group.ID = k.newID("acl")
+		k.acls[consumerID] = append(k.acls[consumerID], group)
+		writeJSON(w, http.StatusCreated, group)
+	case kind == "acls" && len(rest) == 1 && r.Method == http.MethodDelete:
+		groups := k.acls[consumerID]
+		for i, existing := range groups {
+			if existing.ID == rest[0] {
+				k.acls[consumerID] = append(groups[:i:i], groups[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (k *fakeKong) findConsumer(idOrUsername string) *KongConsumer {
+	if consumer, ok := k.consumers[idOrUsername]; ok {
+		return consumer
+	}
+	for _, consumer := range k.consumers {
+		if consumer.Username == idOrUsername {
+			return consumer
+		}
+	}
+	return nil
+}
+
+func (k *fakeKong) newID(prefix string) string {
+	k.nextID++
+	return fmt.Sprintf("%s-%04d", prefix, k.nextID)
+}
+
+// consumerByCustomID, groups e jwtKeys leem o estado sem passar pela API.
+func (k *fakeKong) consumerByCustomID(customID string) *KongConsumer {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	for _, consumer := range k.consumers {
+		if consumer.CustomID == customID {
+			copied := *consumer
+			return &copied
+		}
+	}
+	return nil
+}
+
+func (k *fakeKong) groups(consumerID string) []string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	var groups []string
+	for _, group := range k.acls[consumerID] {
+		groups = append(groups, group.Group)
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+func (k *fakeKong) jwtCredentials(consumerID string) []KongJWTCredential {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return append([]KongJWTCredential(nil), k.jwt[consumerID]...)
+}
+
+func (k *fakeKong) writeCount() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.writes
+}
+
+func writePage[T any](w http.ResponseWriter, r *http.Request, items []T) {
+	const pageSize = 2
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	end := offset + pageSize
+	if end > len(items) {
+		end = len(items)
+	}
+	page := kongPage[T]{Data: items[offset:end]}
+	if page.Data == nil {
+		page.Data = []T{}
+	}
+	if end < len(items) {
+		query := r.URL.Query()
+		query.Set("offset", strconv.Itoa(end))
+		next := r.URL.Path + "?" + query.Encode()
+		page.Next = &next
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,192 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var ErrKongNotFound = errors.New("kong entity not found")
+
+// KongAdminClient fala com a Admin API do Kong (por padrão http://localhost:8001).
+// A URL base é configurável para que possa apontar para uma Admin API falsa em testes locais.
+type KongAdminClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+type KongConsumer struct {
+	ID       string   `json:"id,omitempty"`
+	Username string   `json:"username,omitempty"`
+	CustomID string   `json:"custom_id,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+type KongJWTCredential struct {
+	ID        string `json:"id,omitempty"`
+	Key       string `json:"key,omitempty"`
+	Secret    string `json:"secret,omitempty"`
+	Algorithm string `json:"algorithm,omitempty"`
+}
+
+type KongKeyAuthCredential struct {
+	ID  string `json:"id,omitempty"`
+	Key string `json:"key,omitempty"`
+}
+
+type KongACLGroup struct {
+	ID    string `json:"id,omitempty"`
+	Group string `json:"group,omitempty"`
+}
+
+type kongPage[T any] struct {
+	Data []T     `json:"data"`
+	Next *string `json:"next"`
+}
+
+func NewKongAdminClient(baseURL string) *KongAdminClient {
+	return &KongAdminClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (c *KongAdminClient) ListConsumers(tag string) ([]KongConsumer, error) {
+	path := "/consumers"
+	if tag != "" {
+		path += "?tags=" + url.QueryEscape(tag)
+	}
+	return listAll[KongConsumer](c, path)
+}
+
+func (c *KongAdminClient) FindConsumerByCustomID(customID string) (*KongConsumer, error) {
+	consumers, err := listAll[KongConsumer](c, "/consumers?custom_id="+url.QueryEscape(customID))
+	if err != nil {
+		return nil, err
+	}
+	if len(consumers) == 0 {
+		return nil, ErrKongNotFound
+	}
+	return &consumers[0], nil
+}
+
+func (c *KongAdminClient) CreateConsumer(consumer KongConsumer) (*KongConsumer, error) {
+	var created KongConsumer
+	err := c.do(http.MethodPost, "/consumers", consumer, &created)
+	return &created, err
+}
+
+func (c *KongAdminClient) UpdateConsumer(id string, consumer KongConsumer) (*KongConsumer, error) {
+	var updated KongConsumer
+	err := c.do(http.MethodPatch, "/consumers/"+url.PathEscape(id), consumer, &updated)
+	return &updated, err
+}
+
+func (c *KongAdminClient) DeleteConsumer(idOrUsername string) error {
+	err := c.do(http.MethodDelete, "/consumers/"+url.PathEscape(idOrUsername), nil, nil)
+	if errors.Is(err, ErrKongNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (c *KongAdminClient) ListJWTCredentials(consumerID string) ([]KongJWTCredential, error) {
+	return listAll[KongJWTCredential](c, "/consumers/"+url.PathEscape(consumerID)+"/jwt")
+}
+
+func (c *KongAdminClient) UpsertJWTCredential(consumerID string, credential KongJWTCredential) error {
+	path := "/consumers/" + url.PathEscape(consumerID) + "/jwt/" + url.PathEscape(credential.Key)
+	return c.do(http.MethodPut, path, credential, nil)
+}
+
+func (c *KongAdminClient) DeleteJWTCredential(consumerID string, idOrKey string) error {
+	return c.do(http.MethodDelete, "/consumers/"+url.PathEscape(consumerID)+"/jwt/"+url.PathEscape(idOrKey), nil, nil)
+}
+
+func (c *KongAdminClient) ListKeyAuthCredentials(consumerID string) ([]KongKeyAuthCredential, error) {
+	return listAll[KongKeyAuthCredential](c, "/consumers/"+url.PathEscape(consumerID)+"/key-auth")
+}
+
+// CreateKeyAuthCredential deixa o Kong gerar a chave quando credential.Key está vazio.
+func (c *KongAdminClient) CreateKeyAuthCredential(consumerID string, credential KongKeyAuthCredential) (*KongKeyAuthCredential, error) {
+	var created KongKeyAuthCredential
+	err := c.do(http.MethodPost, "/consumers/"+url.PathEscape(consumerID)+"/key-auth", credential, &created)
+	return &created, err
+}
+
+func (c *KongAdminClient) DeleteKeyAuthCredential(consumerID string, id string) error {
+	return c.do(http.MethodDelete, "/consumers/"+url.PathEscape(consumerID)+"/key-auth/"+url.PathEscape(id), nil, nil)
+}
+
+func (c *KongAdminClient) ListACLGroups(consumerID string) ([]KongACLGroup, error) {
+	return listAll[KongACLGroup](c, "/consumers/"+url.PathEscape(consumerID)+"/acls")
+}
+
+func (c *KongAdminClient) AddACLGroup(consumerID string, group string) error {
+	return c.do(http.MethodPost, "/consumers/"+url.PathEscape(consumerID)+"/acls", KongACLGroup{Group: group}, nil)
+}
+
+func (c *KongAdminClient) DeleteACLGroup(consumerID string, id string) error {
+	return c.do(http.MethodDelete, "/consumers/"+url.PathEscape(consumerID)+"/acls/"+url.PathEscape(id), nil, nil)
+}
+
+func listAll[T any](c *KongAdminClient, path string) ([]T, error) {
+	var all []T
+	for path != "" {
+		var page kongPage[T]
+		if err := c.do(http.MethodGet, path, nil, &page); err != nil {
+			return nil, err
+		}
+		all = append(all, page.Data...)
+
+		path = ""
+		if page.Next != nil {
+			path = *page.Next
+		}
+	}
+	return all, nil
+}
+
+func (c *KongAdminClient) do(method, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrKongNotFound
+	}
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("kong admin %s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(message)))
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,329 @@
+package gateway
+
+import (
+	"errors"
+	"login-api/models"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Tag usada para marcar os consumers criados por este serviço, assim a
+// reconciliação nunca remove consumers configurados manualmente no Konga.
+const ManagedConsumerTag = "user-microservice"
+
+// KongProvisioner mantém um consumer do Kong para cada usuário.
+// Usuários comuns recebem uma credencial JWT com key = email (o plugin jwt
+// deve usar key_claim_name=username) e o mesmo segredo usado para assinar os
+// tokens; contas de serviço recebem uma credencial key-auth. Os papéis do usuário
+// viram grupos ACL, que o plugin acl repassa ao serviço em X-Consumer-Groups.
+type KongProvisioner struct {
+	client    *KongAdminClient
+	jwtSecret string
+}
+
+func NewKongProvisioner(client *KongAdminClient, jwtSecret string) *KongProvisioner {
+	return &KongProvisioner{client: client, jwtSecret: jwtSecret}
+}
+
+// Provision cria ou atualiza o consumer do usuário. Quando cria a credencial key-auth de uma conta
+// de serviço, devolve a chave gerada pelo Kong, que deve ser mostrada ao admin uma única vez;
+// nos demais casos devolve "".
+func (p *KongProvisioner) Provision(user *models.User) (string, error) {
+	consumer, err := p.ensureConsumer(user)
+	if err != nil {
+		return "", err
+	}
+
+	if err := p.ensureACLGroups(consumer, user.Roles); err != nil {
+		return "", err
+	}
+
+	// Ao ligar ou desligar service_account a credencial do outro tipo deixa de valer.
+	if user.ServiceAccount {
+		if err := p.removeJWT(consumer); err != nil {
+			return "", err
+		}
+		return p.ensureKeyAuth(consumer)
+	}
+	if err := p.removeKeyAuth(consumer); err != nil {
+		return "", err
+	}
+	return "", p.ensureJWT(consumer, user.Email)
+}
+
+func (p *KongProvisioner) Deprovision(user *models.User) error {
+	consumer, err := p.client.FindConsumerByCustomID(customID(user))
+	if errors.Is(err, ErrKongNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return p.client.DeleteConsumer(consumer.ID)
+}
+
+// Reconcile garante que cada usuário ativo tenha seu consumer e remove os consumers gerenciados
+// de usuários inativos ou que não correspondem mais a nenhum usuário. Os papéis de cada usuário
+// devem vir já resolvidos (ver UserUseCase.GatewayUsers).
+func (p *KongProvisioner) Reconcile(users []models.User, dryRun bool) (*ReconcileReport, error) {
+	consumers, err := p.client.ListConsumers(ManagedConsumerTag)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ReconcileReport{}
+	known := make(map[string]bool, len(users))
+	inactive := make(map[string]bool)
+	for i := range users {
+		user := &users[i]
+		if !user.IsActive() {
+			inactive[customID(user)] = true
+			continue
+		}
+		known[customID(user)] = true
+		if dryRun {
+			// Sem escrever nada, só relata o que o provisionamento mudaria.
+			missing, changes, err := p.drift(user)
+			switch {
+			case err != nil:
+				report.Failed = append(report.Failed, user.Email+": "+err.Error())
+			case missing:
+				report.Missing = append(report.Missing, user.Email)
+			case len(changes) > 0:
+				report.Drift = append(report.Drift, user.Email+": "+strings.Join(changes, ", "))
+			}
+			continue
+		}
+		apiKey, err := p.Provision(user)
+		if err != nil {
+			report.Failed = append(report.Failed, user.Email+": "+err.Error())
+			continue
+		}
+		if apiKey != "" {
+			report.APIKeys = append(report.APIKeys, APIKey{Username: user.Email, Key: apiKey})
+		}
+		report.Provisioned++
+	}
+
+	for _, consumer := range consumers {
+		if known[consumer.CustomID] {
+			continue
+		}
+		if inactive[consumer.CustomID] {
+			report.Deprovisioned = append(report.Deprovisioned, consumer.Username)
+		} else {
+			report.Orphans = append(report.Orphans, consumer.Username)
+		}
+		if dryRun {
+			continue
+		}
+		if err := p.client.DeleteConsumer(consumer.ID); err != nil {
+			report.Failed = append(report.Failed, consumer.Username+": "+err.Error())
+		}
+	}
+
+	return report, nil
+}
+
+type ReconcileReport struct {
+	Provisioned int
+	// Missing e Drift só são preenchidos no dry run: usuários ativos sem consumer e consumers
+	// cujo username, grupos ou credenciais divergem do usuário.
+	Missing []string
+	Drift   []string
+	// Deprovisioned lista os consumers de usuários que não estão ativos.
+	Deprovisioned []string
+	Orphans       []string
+	Failed        []string
+	// APIKeys são as chaves criadas para contas de serviço que estavam sem credencial.
+	APIKeys []APIKey
+}
+
+// APIKey é a chave key-auth criada para uma conta de serviço.
+type APIKey struct {
+	Username string
+	Key      string
+}
+
+func (p *KongProvisioner) ensureConsumer(user *models.User) (*KongConsumer, error) {
+	desired := KongConsumer{
+		Username: user.Email,
+		CustomID: customID(user),
+		Tags:     []string{ManagedConsumerTag},
+	}
+
+	existing, err := p.client.FindConsumerByCustomID(desired.CustomID)
+	if errors.Is(err, ErrKongNotFound) {
+		return p.client.CreateConsumer(desired)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if existing.Username == desired.Username {
+		return existing, nil
+	}
+	return p.client.UpdateConsumer(existing.ID, desired)
+}
+
+func (p *KongProvisioner) ensureJWT(consumer *KongConsumer, key string) error {
+	credentials, err := p.client.ListJWTCredentials(consumer.ID)
+	if err != nil {
+		return err
+	}
+
+	// Remove credenciais antigas, por exemplo depois de uma troca de email.
+	for _, credential := range credentials {
+		if credential.Key != key {
+			if err := p.client.DeleteJWTCredential(consumer.ID, credential.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	return p.client.UpsertJWTCredential(consumer.ID, KongJWTCredential{
+		Key:       key,
+		Secret:    p.jwtSecret,
+		Algorithm: "HS256",
+	})
+}
+
+func (p *KongProvisioner) ensureACLGroups(consumer *KongConsumer, roles []models.Role) error {
+	groups, err := p.client.ListACLGroups(consumer.ID)
+	if err != nil {
+		return err
+	}
+
+	desired := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		desired[role.Name] = true
+	}
+
+	for _, group := range groups {
+		if desired[group.Group] {
+			delete(desired, group.Group)
+			continue
+		}
+		if err := p.client.DeleteACLGroup(consumer.ID, group.ID); err != nil {
+			return err
+		}
+	}
+
+	for group := range desired {
+		if err := p.client.AddACLGroup(consumer.ID, group); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureKeyAuth cria a credencial key-auth se o consumer ainda não tem uma e devolve a chave gerada.
+func (p *KongProvisioner) ensureKeyAuth(consumer *KongConsumer) (string, error) {
+	credentials, err := p.client.ListKeyAuthCredentials(consumer.ID)
+	if err != nil {
+		return "", err
+	}
+	if len(credentials) > 0 {
+		return "", nil
+	}
+
+	created, err := p.client.CreateKeyAuthCredential(consumer.ID, KongKeyAuthCredential{})
+	if err != nil {
+		return "", err
+	}
+	return created.Key, nil
+}
+
+// drift compara o consumer do usuário com o que o Provision gravaria, sem alterar o Kong.
+func (p *KongProvisioner) drift(user *models.User) (bool, []string, error) {
+	consumer, err := p.client.FindConsumerByCustomID(customID(user))
+	if errors.Is(err, ErrKongNotFound) {
+		return true, nil, nil
+	}
+	if err != nil {
+		return false, nil, err
+	}
+
+	var changes []string
+	if consumer.Username != user.Email {
+		changes = append(changes, "username "+consumer.Username+" -> "+user.Email)
+	}
+
+	groups, err := p.client.ListACLGroups(consumer.ID)
+	if err != nil {
+		return false, nil, err
+	}
+	desired := make(map[string]bool, len(user.Roles))
+	for _, role := range user.Roles {
+		desired[role.Name] = true
+	}
+	for _, group := range groups {
+		if desired[group.Group] {
+			delete(desired, group.Group)
+			continue
+		}
+		changes = append(changes, "acl -"+group.Group)
+	}
+	added := make([]string, 0, len(desired))
+	for group := range desired {
+		added = append(added, "acl +"+group)
+	}
+	sort.Strings(added)
+	changes = append(changes, added...)
+
+	jwt, err := p.client.ListJWTCredentials(consumer.ID)
+	if err != nil {
+		return false, nil, err
+	}
+	keyAuth, err := p.client.ListKeyAuthCredentials(consumer.ID)
+	if err != nil {
+		return false, nil, err
+	}
+	if user.ServiceAccount {
+		if len(jwt) > 0 {
+			changes = append(changes, "jwt credential to remove")
+		}
+		if len(keyAuth) == 0 {
+			changes = append(changes, "key-auth credential missing")
+		}
+		return false, changes, nil
+	}
+	if len(keyAuth) > 0 {
+		changes = append(changes, "key-auth credential to remove")
+	}
+	if len(jwt) != 1 || jwt[0].Key != user.Email {
+		changes = append(changes, "jwt credential out of date")
+	}
+	return false, changes, nil
+}
+
+func (p *KongProvisioner) removeJWT(consumer *KongConsumer) error {
+	credentials, err := p.client.ListJWTCredentials(consumer.ID)
+	if err != nil {
+		return err
+	}
+	for _, credential := range credentials {
+		if err := p.client.DeleteJWTCredential(consumer.ID, credential.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *KongProvisioner) removeKeyAuth(consumer *KongConsumer) error {
+	credentials, err := p.client.ListKeyAuthCredentials(consumer.ID)
+	if err != nil {
+		return err
+	}
+	for _, credential := range credentials {
+		if err := p.client.DeleteKeyAuthCredential(consumer.ID, credential.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func customID(user *models.User) string {
+	return strconv.FormatUint(user.ID, 10)
+}
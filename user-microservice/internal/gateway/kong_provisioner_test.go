@@ -0,0 +1,266 @@
+package gateway
+
+import (
+	"login-api/models"
+	"reflect"
+	"testing"
+)
+
+func TestProvisionCreatesConsumerWithJWTAndGroups(t *testing.T) {
+	kong, client := newFakeKong(t)
+	provisioner := NewKongProvisioner(client, "secret")
+
+	user := &models.User{ID: 7, Email: "ana@example.com", Roles: []models.Role{{Name: "Admin"}, {Name: "Watcher"}}}
+	if _, err := provisioner.Provision(user); err != nil {
+		t.Fatalf("Provision: %v", err)
+	}
+
+	consumer := kong.consumerByCustomID("7")
+	if consumer == nil {
+		t.Fatal("consumer was not created")
+	}
+	if consumer.Username != "ana@example.com" || !reflect.DeepEqual(consumer.Tags, []string{ManagedConsumerTag}) {
+		t.Errorf("consumer = %+v", consumer)
+	}
+	if groups := kong.groups(consumer.ID); !reflect.DeepEqual(groups, []string{"Admin", "Watcher"}) {
+		t.Errorf("groups = %v", groups)
+	}
+	credentials := kong.jwtCredentials(consumer.ID)
+	if len(credentials) != 1 || credentials[0].Key != "ana@example.com" || credentials[0].Secret != "secret" || credentials[0].Algorithm != "HS256" {
+		t.Errorf("jwt credentials = %+v", credentials)
+	}
+}
+
+func TestProvisionIsIdempotent(t *testing.T) {
+	kong, client := newFakeKong(t)
+	provisioner := NewKongProvisioner(client, "secret")
+
+	user := &models.User{ID: 7, Email: "ana@example.com", Roles: []models.Role{{Name: "Admin"}, {Name: "Modifier"}, {Name: "Watcher"}}}
+	if _, err := provisioner.Provision(user); err != nil {
+		t.Fatalf("Provision: %v", err)
+	}
+	consumer := kong.consumerByCustomID("7")
+	credentials := kong.jwtCredentials(consumer.ID)
+	writes := kong.writeCount()
+
+	if _, err := provisioner.Provision(user); err != nil {
+		t.Fatalf("second Provision: %v", err)
+	}
+
+	again := kong.consumerByCustomID("7")
+	if again.ID != consumer.ID {
+		t.Errorf("consumer was recreated: %s != %s", again.ID, consumer.ID)
+	}
+	if groups := kong.groups(again.ID); !reflect.DeepEqual(groups, []string{"Admin", "Modifier", "Watcher"}) {
+		t.Errorf("groups = %v", groups)
+	}
+	if got := kong.jwtCredentials(again.ID); !reflect.DeepEqual(got, credentials) {
+		t.Errorf("jwt credentials changed: %+v != %+v", got, credentials)
+	}
+	// Só o upsert da credencial JWT é reenviado.
+	if extra := kong.writeCount() - writes; extra != 1 {
+		t.Errorf("second Provision made %d writes, want 1", extra)
+	}
+}
+
+func TestProvisionSyncsGroupsWithRoles(t *testing.T) {
+	kong, client := newFakeKong(t)
+	provisioner := NewKongProvisioner(client, "secret")
+
+	user := &models.User{ID: 7, Email: "ana@example.com", Roles: []models.Role{{Name: "Admin"}, {Name: "Watcher"}}}
+	if _, err := provisioner.Provision(user); err != nil {
+		t.Fatalf("Provision: %v", err)
+	}
+	user.Roles = []models.Role{{Name: "Watcher"}, {Name: "Modifier"}}
+	if _, err := provisioner.Provision(user); err != nil {
+		t.Fatalf("Provision: %v", err)
+	}
+
+	if groups := kong.groups(kong.consumerByCustomID("7").ID); !reflect.DeepEqual(groups, []string{"Modifier", "Watcher"}) {
+		t.Errorf("groups = %v", groups)
+	}
+}
+
+func TestProvisionRotatesCredentials(t *testing.T) {
+	kong, client := newFakeKong(t)
+
+	user := &models.User{ID: 7, Email: "ana@example.com"}
+	if _, err := NewKongProvisioner(client, "old-secret").Provision(user); err != nil {
+		t.Fatalf("Provision: %v", err)
+	}
+
+	// Troca de email: o consumer é renomeado e a credencial antiga sai.
+	user.Email = "ana.souza@example.com"
+	if _, err := NewKongProvisioner(client, "new-secret").Provision(user); err != nil {
+		t.Fatalf("Provision after email change: %v", err)
+	}
+
+	consumer := kong.consumerByCustomID("7")
+	if consumer.Username != "ana.souza@example.com" {
+		t.Errorf("username = %q", consumer.Username)
+	}
+	credentials := kong.jwtCredentials(consumer.ID)
+	if len(credentials) != 1 || credentials[0].Key != "ana.souza@example.com" || credentials[0].Secret != "new-secret" {
+		t.Errorf("jwt credentials = %+v", credentials)
+	}
+}
+
+func TestProvisionServiceAccountUsesKeyAuth(t *testing.T) {
+	kong, client := newFakeKong(t)
+	provisioner := NewKongProvisioner(client, "secret")
+
+	user := &models.User{ID: 9, Email: "ci@example.com", ServiceAccount: true}
+	var keys []string
+	for i := 0; i < 2; i++ {
+		apiKey, err := provisioner.Provision(user)
+		if err != nil {
+			t.Fatalf("Provision: %v", err)
+		}
+		keys = append(keys, apiKey)
+	}
+
+	consumer := kong.consumerByCustomID("9")
+	credentials, err := client.ListKeyAuthCredentials(consumer.ID)
+	if err != nil {
+		t.Fatalf("ListKeyAuthCredentials: %v", err)
+	}
+	if len(credentials) != 1 {
+		t.Errorf("key-auth credentials = %+v, want exactly one", credentials)
+	}
+	// A chave só é devolvida quando criada.
+	if len(credentials) == 1 && !reflect.DeepEqual(keys, []string{credentials[0].Key, ""}) {
+		t.Errorf("returned keys = %q, want the created key and then none", keys)
+	}
+	if jwt := kong.jwtCredentials(consumer.ID); len(jwt) != 0 {
+		t.Errorf("service account got jwt credentials: %+v", jwt)
+	}
+}
+
+func TestProvisionTogglingServiceAccountSwapsCredentials(t *testing.T) {
+	kong, client := newFakeKong(t)
+	provisioner := NewKongProvisioner(client, "secret")
+
+	user := &models.User{ID: 9, Email: "ci@example.com"}
+	if _, err := provisioner.Provision(user); err != nil {
+		t.Fatalf("Provision: %v", err)
+	}
+	consumer := kong.consumerByCustomID("9")
+
+	user.ServiceAccount = true
+	apiKey, err := provisioner.Provision(user)
+	if err != nil {
+		t.Fatalf("Provision as service account: %v", err)
+	}
+	if jwt := kong.jwtCredentials(consumer.ID); len(jwt) != 0 {
+		t.Errorf("jwt credentials left after becoming a service account: %+v", jwt)
+	}
+	if credentials, _ := client.ListKeyAuthCredentials(consumer.ID); len(credentials) != 1 || credentials[0].Key != apiKey {
+		t.Errorf("key-auth credentials = %+v, want the returned key %q", credentials, apiKey)
+	}
+
+	user.ServiceAccount = false
+	if _, err := provisioner.Provision(user); err != nil {
+		t.Fatalf("Provision as user: %v", err)
+	}
+	if credentials, _ := client.ListKeyAuthCredentials(consumer.ID); len(credentials) != 0 {
+		t.Errorf("key-auth credentials left after leaving service account: %+v", credentials)
+	}
+	if jwt := kong.jwtCredentials(consumer.ID); len(jwt) != 1 {
+		t.Errorf("jwt credentials = %+v, want one", jwt)
+	}
+}
+
+func TestDeprovision(t *testing.T) {
+	kong, client := newFakeKong(t)
+	provisioner := NewKongProvisioner(client, "secret")
+
+	user := &models.User{ID: 7, Email: "ana@example.com"}
+	if _, err := provisioner.Provision(user); err != nil {
+		t.Fatalf("Provision: %v", err)
+	}
+	if err := provisioner.Deprovision(user); err != nil {
+		t.Fatalf("Deprovision: %v", err)
+	}
+	if consumer := kong.consumerByCustomID("7"); consumer != nil {
+		t.Errorf("consumer still exists: %+v", consumer)
+	}
+
+	// Sem consumer não há nada a fazer.
+	if err := provisioner.Deprovision(user); err != nil {
+		t.Errorf("second Deprovision: %v", err)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	kong, client := newFakeKong(t)
+	provisioner := NewKongProvisioner(client, "secret")
+
+	for _, user := range []*models.User{
+		{ID: 1, Email: "ana@example.com"},
+		{ID: 2, Email: "removed@example.com"},
+		{ID: 3, Email: "gone@example.com"},
+		{ID: 5, Email: "suspended@example.com"},
+	} {
+		if _, err := provisioner.Provision(user); err != nil {
+			t.Fatalf("Provision: %v", err)
+		}
+	}
+	if _, err := client.CreateConsumer(KongConsumer{Username: "konga-admin"}); err != nil {
+		t.Fatalf("CreateConsumer: %v", err)
+	}
+
+	users := []models.User{
+		{ID: 1, Email: "ana@example.com", Roles: []models.Role{{Name: "Admin"}}},
+		{ID: 4, Email: "new@example.com"},
+		{ID: 5, Email: "suspended@example.com", Status: models.UserStatusSuspended},
+		{ID: 6, Email: "pending@example.com", Status: models.UserStatusPending},
+	}
+
+	writes := kong.writeCount()
+	report, err := provisioner.Reconcile(users, true)
+	if err != nil {
+		t.Fatalf("Reconcile dry run: %v", err)
+	}
+	if report.Provisioned != 0 || !reflect.DeepEqual(report.Orphans, []string{"removed@example.com", "gone@example.com"}) ||
+		!reflect.DeepEqual(report.Deprovisioned, []string{"suspended@example.com"}) ||
+		!reflect.DeepEqual(report.Missing, []string{"new@example.com"}) ||
+		!reflect.DeepEqual(report.Drift, []string{"ana@example.com: acl +Admin"}) {
+		t.Errorf("dry run report = %+v", report)
+	}
+	if kong.writeCount() != writes {
+		t.Error("dry run changed Kong")
+	}
+
+	report, err = provisioner.Reconcile(users, false)
+	if err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if report.Provisioned != 2 || len(report.Orphans) != 2 || len(report.Deprovisioned) != 1 || len(report.Failed) != 0 {
+		t.Errorf("report = %+v", report)
+	}
+	if kong.consumerByCustomID("2") != nil || kong.consumerByCustomID("3") != nil {
+		t.Error("orphan consumers were not deleted")
+	}
+	if consumer := kong.consumerByCustomID("4"); consumer == nil {
+		t.Error("missing consumer was not created")
+	}
+	// Usuários inativos ficam sem consumer, como no provisionamento.
+	if kong.consumerByCustomID("5") != nil || kong.consumerByCustomID("6") != nil {
+		t.Error("inactive users still have consumers")
+	}
+	if groups := kong.groups(kong.consumerByCustomID("1").ID); !reflect.DeepEqual(groups, []string{"Admin"}) {
+		t.Errorf("groups = %v", groups)
+	}
+	if consumers, _ := client.ListConsumers(""); len(consumers) != 3 {
+		t.Errorf("consumers = %+v, want ana, new and the manual konga-admin", consumers)
+	}
+
+	// Depois de reconciliado, o dry run não encontra mais divergências.
+	report, err = provisioner.Reconcile(users, true)
+	if err != nil {
+		t.Fatalf("Reconcile dry run: %v", err)
+	}
+	if len(report.Missing) != 0 || len(report.Drift) != 0 || len(report.Orphans) != 0 || len(report.Deprovisioned) != 0 {
+		t.Errorf("dry run after reconcile = %+v", report)
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"events"
+	"log"
+	"login-api/internal/usecases"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// GatewaySyncHandler atualiza os grupos de ACL no Kong quando outro serviço altera os papéis
+// de um usuário direto no banco. Os eventos publicados por este serviço são ignorados, porque
+// o consumer já foi atualizado na própria requisição.
+type GatewaySyncHandler struct {
+	userUseCase *usecases.UserUseCase
+}
+
+func NewGatewaySyncHandler(userUseCase *usecases.UserUseCase) *GatewaySyncHandler {
+	return &GatewaySyncHandler{userUseCase: userUseCase}
+}
+
+func (h *GatewaySyncHandler) HandleMessage(msg amqp091.Delivery) {
+	envelope, err := events.Decode(msg.RoutingKey, msg.Body)
+	if err != nil {
+		log.Printf("Error deserializing message: %v", err)
+		msg.Nack(false, false)
+		return
+	}
+	if envelope.Source == usecases.EventSource {
+		msg.Ack(false)
+		return
+	}
+
+	userIDs, err := affectedUsers(envelope)
+	if err != nil {
+		log.Printf("Error deserializing message: %v", err)
+		msg.Nack(false, false)
+		return
+	}
+
+	for _, userID := range userIDs {
+		h.userUseCase.SyncGateway(userID)
+	}
+	msg.Ack(false)
+	log.Printf("Synced Kong consumers of %d users after %s from %s", len(userIDs), envelope.Type, envelope.Source)
+}
+
+// affectedUsers devolve os usuários cujos papéis mudaram: o `id` de user.role_added e
+// user.role_removed ou os `user_ids` de role.deleted.
+func affectedUsers(envelope *events.Envelope) ([]uint64, error) {
+	if envelope.Type == events.RoleDeletedType {
+		var event events.RoleDeleted
+		if err := envelope.DataAs(&event); err != nil {
+			return nil, err
+		}
+		return event.UserIDs, nil
+	}
+
+	var event struct {
+		ID uint64 `json:"id"`
+	}
+	if err := envelope.DataAs(&event); err != nil {
+		return nil, err
+	}
+	return []uint64{event.ID}, nil
+}
@@ -0,0 +1,22 @@
+package jobs
+
+import (
+	"log"
+	"login-api/internal/usecases"
+	"time"
+)
+
+// StartLoginHistoryPrune apaga o histórico de login mais antigo que LOGIN_HISTORY_RETENTION_DAYS
+// (padrão 90), verificando a cada LOGIN_HISTORY_PRUNE_INTERVAL (padrão 24h).
+func StartLoginHistoryPrune(loginHistoryUseCase *usecases.LoginHistoryUseCase) {
+	retention := daysFromEnv("LOGIN_HISTORY_RETENTION_DAYS", 90)
+	interval := durationFromEnv("LOGIN_HISTORY_PRUNE_INTERVAL", 24*time.Hour)
+
+	Every("login-history-prune", interval, func() error {
+		pruned, err := loginHistoryUseCase.Prune(time.Now().Add(-retention))
+		if pruned > 0 {
+			log.Printf("Pruned %d login attempts", pruned)
+		}
+		return err
+	})
+}
@@ -0,0 +1,34 @@
+package jobs
+
+import (
+	"log"
+	"login-api/internal/usecases"
+	"time"
+)
+
+const outboxBatchSize = 100
+
+// StartOutboxRelay entrega os eventos da outbox a cada OUTBOX_RELAY_INTERVAL (padrão 1s)
+// e apaga os já entregues há mais de OUTBOX_RETENTION_DAYS (padrão 7), verificando de hora em hora.
+func StartOutboxRelay(relay *usecases.OutboxRelay) {
+	interval := durationFromEnv("OUTBOX_RELAY_INTERVAL", time.Second)
+	retention := daysFromEnv("OUTBOX_RETENTION_DAYS", 7)
+
+	Every("outbox-relay", interval, func() error {
+		// Lotes cheios indicam fila acumulada: continua sem esperar o próximo tick.
+		for {
+			sent, err := relay.Relay(time.Now(), outboxBatchSize)
+			if err != nil || sent < outboxBatchSize {
+				return err
+			}
+		}
+	})
+
+	Every("outbox-prune", time.Hour, func() error {
+		pruned, err := relay.Prune(time.Now().Add(-retention))
+		if pruned > 0 {
+			log.Printf("Pruned %d delivered outbox events", pruned)
+		}
+		return err
+	})
+}
@@ -0,0 +1,31 @@
+package jobs
+
+import (
+	"log"
+	"login-api/internal/usecases"
+	"time"
+)
+
+// StartRoleExpiry remove as atribuições de papéis vencidas e sincroniza as que começaram
+// a valer, verificando a cada ROLE_EXPIRY_INTERVAL (padrão 1m).
+func StartRoleExpiry(userUseCase *usecases.UserUseCase) {
+	interval := durationFromEnv("ROLE_EXPIRY_INTERVAL", time.Minute)
+	lastRun := time.Now().Add(-interval)
+
+	Every("role-expiry", interval, func() error {
+		now := time.Now()
+		expired, err := userUseCase.ExpireRoles(now)
+		if expired > 0 {
+			log.Printf("Expired %d role assignments", expired)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := userUseCase.ActivateRoles(lastRun, now); err != nil {
+			return err
+		}
+		lastRun = now
+		return nil
+	})
+}
@@ -0,0 +1,39 @@
+package jobs
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Every executa job em uma goroutine a cada intervalo, começando imediatamente.
+// Erros são apenas registrados; a próxima execução tenta de novo.
+func Every(name string, interval time.Duration, job func() error) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := job(); err != nil {
+				log.Printf("Job %s failed: %v", name, err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+func daysFromEnv(key string, fallback int) time.Duration {
+	days, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || days < 0 {
+		days = fallback
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
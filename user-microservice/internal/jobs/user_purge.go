@@ -0,0 +1,22 @@
+package jobs
+
+import (
+	"log"
+	"login-api/internal/usecases"
+	"time"
+)
+
+// StartUserPurge remove de vez os usuários excluídos há mais de USER_RETENTION_DAYS
+// (padrão 30), verificando a cada USER_PURGE_INTERVAL (padrão 1h).
+func StartUserPurge(userUseCase *usecases.UserUseCase) {
+	retention := daysFromEnv("USER_RETENTION_DAYS", 30)
+	interval := durationFromEnv("USER_PURGE_INTERVAL", time.Hour)
+
+	Every("user-purge", interval, func() error {
+		purged, err := userUseCase.PurgeDeleted(time.Now().Add(-retention))
+		if purged > 0 {
+			log.Printf("Purged %d deleted users", purged)
+		}
+		return err
+	})
+}
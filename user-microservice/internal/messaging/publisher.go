@@ -0,0 +1,105 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"events"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const confirmTimeout = 10 * time.Second
+
+var ErrNotConfirmed = errors.New("publish not confirmed by broker")
+
+// Dialer abre a conexão e o canal já com a exchange declarada (ex.: config.SetupRabbitMQ).
+type Dialer func() (*amqp.Connection, *amqp.Channel, error)
+
+// ConfirmingPublisher publica na exchange user_events com publisher confirms: Publish só
+// retorna nil depois do ack do broker. A conexão é aberta sob demanda e refeita após falhas.
+type ConfirmingPublisher struct {
+	dial Dialer
+	mu   sync.Mutex
+	conn *amqp.Connection
+	ch   *amqp.Channel
+}
+
+func NewConfirmingPublisher(dial Dialer) *ConfirmingPublisher {
+	return &ConfirmingPublisher{dial: dial}
+}
+
+func (p *ConfirmingPublisher) Publish(routingKey string, payload []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ch, err := p.channel()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), confirmTimeout)
+	defer cancel()
+
+	confirmation, err := ch.PublishWithDeferredConfirmWithContext(ctx,
+		"user_events",
+		routingKey,
+		false, // mandatory
+		false, // immediate
+		amqp.Publishing{
+			ContentType:  events.ContentType,
+			DeliveryMode: amqp.Persistent,
+			Body:         payload,
+		},
+	)
+	if err != nil {
+		p.reset()
+		return err
+	}
+
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		p.reset()
+		return err
+	}
+	if !acked {
+		return ErrNotConfirmed
+	}
+	return nil
+}
+
+func (p *ConfirmingPublisher) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.reset()
+}
+
+func (p *ConfirmingPublisher) channel() (*amqp.Channel, error) {
+	if p.ch != nil && !p.ch.IsClosed() {
+		return p.ch, nil
+	}
+	p.reset()
+
+	conn, ch, err := p.dial()
+	if err != nil {
+		return nil, err
+	}
+	if err := ch.Confirm(false); err != nil {
+		ch.Close()
+		conn.Close()
+		return nil, err
+	}
+	p.conn, p.ch = conn, ch
+	return ch, nil
+}
+
+func (p *ConfirmingPublisher) reset() {
+	if p.ch != nil {
+		p.ch.Close()
+	}
+	if p.conn != nil {
+		p.conn.Close()
+	}
+	p.conn, p.ch = nil, nil
+}
@@ -0,0 +1,141 @@
+package repositories
+
+import (
+	"login-api/models"
+
+	"gorm.io/gorm"
+)
+
+type GroupRepository interface {
+	FindAll() ([]models.Group, error)
+	FindByID(id uint64) (*models.Group, error)
+	FindByName(name string) (*models.Group, error)
+	Create(group *models.Group) error
+	Update(group *models.Group) error
+	Delete(id uint64) error
+	FindMembers(groupID uint64) ([]models.User, error)
+	FindByMember(userID uint64) ([]models.Group, error)
+	FindMemberIDs(groupID uint64) ([]uint64, error)
+	AddMember(groupID, userID uint64) error
+	RemoveMember(groupID, userID uint64) error
+	ReplaceRoles(groupID uint64, roleIDs []uint64) error
+}
+
+type GormGroupRepository struct {
+	db *gorm.DB
+}
+
+func NewGormGroupRepository(db *gorm.DB) *GormGroupRepository {
+	return &GormGroupRepository{db: db}
+}
+
+func (r *GormGroupRepository) FindAll() ([]models.Group, error) {
+	var groups []models.Group
+	result := r.db.Preload("Roles").Order("name").Find(&groups)
+	return groups, result.Error
+}
+
+func (r *GormGroupRepository) FindByID(id uint64) (*models.Group, error) {
+	var group models.Group
+	result := r.db.Preload("Roles").Where("id = ?", id).First(&group)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &group, nil
+}
+
+func (r *GormGroupRepository) FindByName(name string) (*models.Group, error) {
+	var group models.Group
+	result := r.db.Where("name = ?", name).First(&group)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &group, nil
+}
+
+func (r *GormGroupRepository) Create(group *models.Group) error {
+	return r.db.Omit("Roles", "Members").Create(group).Error
+}
+
+func (r *GormGroupRepository) Update(group *models.Group) error {
+	return r.db.Model(group).Select("Name", "Description").Updates(group).Error
+}
+
+func (r *GormGroupRepository) Delete(id uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := bumpMemberVersions(tx, id); err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM user_groups WHERE group_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM group_roles WHERE group_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Group{}, "id = ?", id).Error
+	})
+}
+
+func (r *GormGroupRepository) FindMembers(groupID uint64) ([]models.User, error) {
+	var users []models.User
+	result := r.db.Preload("Roles").
+		Where("id IN (SELECT user_id FROM user_groups WHERE group_id = ?)", groupID).
+		Order("id").
+		Find(&users)
+	return users, result.Error
+}
+
+func (r *GormGroupRepository) FindByMember(userID uint64) ([]models.Group, error) {
+	var groups []models.Group
+	result := r.db.Preload("Roles").
+		Where("id IN (SELECT group_id FROM user_groups WHERE user_id = ?)", userID).
+		Order("name").
+		Find(&groups)
+	return groups, result.Error
+}
+
+func (r *GormGroupRepository) FindMemberIDs(groupID uint64) ([]uint64, error) {
+	var userIDs []uint64
+	result := r.db.Table("user_groups").Where("group_id = ?", groupID).Pluck("user_id", &userIDs)
+	return userIDs, result.Error
+}
+
+func (r *GormGroupRepository) AddMember(groupID, userID uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec("INSERT INTO user_groups (group_id, user_id) VALUES (?, ?) ON CONFLICT DO NOTHING", groupID, userID)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return bumpVersion(tx, userID)
+	})
+}
+
+func (r *GormGroupRepository) RemoveMember(groupID, userID uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec("DELETE FROM user_groups WHERE group_id = ? AND user_id = ?", groupID, userID)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return bumpVersion(tx, userID)
+	})
+}
+
+// ReplaceRoles troca todos os papéis do grupo em uma transação.
+func (r *GormGroupRepository) ReplaceRoles(groupID uint64, roleIDs []uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM group_roles WHERE group_id = ?", groupID).Error; err != nil {
+			return err
+		}
+		for _, roleID := range roleIDs {
+			if err := tx.Exec("INSERT INTO group_roles (group_id, role_id) VALUES (?, ?)", groupID, roleID).Error; err != nil {
+				return err
+			}
+		}
+		return bumpMemberVersions(tx, groupID)
+	})
+}
+
+// bumpMemberVersions incrementa a versão dos membros do grupo, cujos papéis efetivos mudaram.
+func bumpMemberVersions(db *gorm.DB, groupID uint64) error {
+	return db.Exec("UPDATE users SET version = version + 1 WHERE id IN (SELECT user_id FROM user_groups WHERE group_id = ?)", groupID).Error
+}
@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"errors"
+	"login-api/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrUserNotPending indica que o usuário do convite já foi ativado (ou mudou de status) por outro caminho.
+var ErrUserNotPending = errors.New("user is no longer pending")
+
+type InvitationRepository interface {
+	FindPending() ([]models.Invitation, error)
+	FindByID(id uint64) (*models.Invitation, error)
+	Create(invitation *models.Invitation, user *models.User, roleIDs []uint64) error
+	Update(invitation *models.Invitation) error
+	Accept(invitation *models.Invitation, password string, at time.Time) error
+	Revoke(invitation *models.Invitation) error
+	Transaction(fn func(repo InvitationRepository) error) error
+	Outbox() OutboxRepository
+}
+
+type GormInvitationRepository struct {
+	db     *gorm.DB
+	outbox *GormOutboxRepository
+}
+
+func NewGormInvitationRepository(db *gorm.DB) *GormInvitationRepository {
+	return &GormInvitationRepository{db: db}
+}
+
+// FindPending lista os convites ainda não aceitos, inclusive os vencidos, com os papéis do usuário.
+func (r *GormInvitationRepository) FindPending() ([]models.Invitation, error) {
+	var invitations []models.Invitation
+	result := r.db.Preload("User.Roles").Where("accepted_at IS NULL").Order("created_at DESC").Find(&invitations)
+	return invitations, result.Error
+}
+
+func (r *GormInvitationRepository) FindByID(id uint64) (*models.Invitation, error) {
+	var invitation models.Invitation
+	result := r.db.Preload("User.Roles").Where("id = ?", id).First(&invitation)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &invitation, nil
+}
+
+// Create grava o usuário pending, os papéis iniciais e o convite numa única transação.
+func (r *GormInvitationRepository) Create(invitation *models.Invitation, user *models.User, roleIDs []uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Roles").Create(user).Error; err != nil {
+			return err
+		}
+		for _, roleID := range roleIDs {
+			err := tx.Exec(
+				"INSERT INTO user_roles (user_id, role_id, granted_by, reason) VALUES (?, ?, ?, ?)",
+				user.ID, roleID, invitation.InvitedBy, "invitation",
+			).Error
+			if err != nil {
+				return err
+			}
+		}
+		invitation.UserID = user.ID
+		return tx.Omit("User").Create(invitation).Error
+	})
+}
+
+func (r *GormInvitationRepository) Update(invitation *models.Invitation) error {
+	return r.db.Omit("User").Save(invitation).Error
+}
+
+// Accept define a senha, ativa o usuário e marca o convite como aceito. Só vale enquanto o usuário
+// estiver pending e o convite em aberto; caso contrário nada é alterado e volta ErrUserNotPending.
+func (r *GormInvitationRepository) Accept(invitation *models.Invitation, password string, at time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.User{}).
+			Where("id = ? AND status = ?", invitation.UserID, models.UserStatusPending).
+			Updates(map[string]interface{}{
+				"password":          password,
+				"status":            models.UserStatusActive,
+				"status_reason":     "invitation accepted",
+				"status_changed_at": at,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrUserNotPending
+		}
+		result = tx.Model(&models.Invitation{}).
+			Where("id = ? AND accepted_at IS NULL", invitation.ID).
+			Update("accepted_at", at)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrUserNotPending
+		}
+		return nil
+	})
+}
+
+// Revoke apaga o convite e o usuário pending, que nunca chegou a ser ativado. Se o usuário já não
+// estiver pending nada é apagado e volta ErrUserNotPending.
+func (r *GormInvitationRepository) Revoke(invitation *models.Invitation) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Trava a linha para que um aceite simultâneo espere ou seja visto aqui.
+		var user models.User
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND status = ?", invitation.UserID, models.UserStatusPending).
+			Limit(1).Find(&user)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrUserNotPending
+		}
+		if err := tx.Delete(&models.Invitation{}, "id = ?", invitation.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM user_roles WHERE user_id = ?", invitation.UserID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM user_groups WHERE user_id = ?", invitation.UserID).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&models.User{}, "id = ?", invitation.UserID).Error
+	})
+}
+
+func (r *GormInvitationRepository) Transaction(fn func(repo InvitationRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		repo := NewGormInvitationRepository(tx)
+		repo.outbox = NewGormOutboxRepository(tx)
+		return fn(repo)
+	})
+}
+
+func (r *GormInvitationRepository) Outbox() OutboxRepository {
+	if r.outbox != nil {
+		return r.outbox
+	}
+	return NewGormOutboxRepository(r.db)
+}
@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"login-api/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type LoginAttemptRepository interface {
+	Create(attempt *models.LoginAttempt) error
+	FindByUser(userID uint64, page, pageSize int) (*models.LoginAttemptPage, error)
+	DeleteByUser(userID uint64, email string) error
+	DeleteBefore(before time.Time) (int64, error)
+}
+
+type GormLoginAttemptRepository struct {
+	db *gorm.DB
+}
+
+func NewGormLoginAttemptRepository(db *gorm.DB) *GormLoginAttemptRepository {
+	return &GormLoginAttemptRepository{db: db}
+}
+
+func (r *GormLoginAttemptRepository) Create(attempt *models.LoginAttempt) error {
+	return r.db.Create(attempt).Error
+}
+
+// FindByUser devolve as tentativas mais recentes primeiro; pageSize <= 0 traz todas.
+func (r *GormLoginAttemptRepository) FindByUser(userID uint64, page, pageSize int) (*models.LoginAttemptPage, error) {
+	result := &models.LoginAttemptPage{}
+	db := r.db.Model(&models.LoginAttempt{}).Where("user_id = ?", userID)
+	if err := db.Count(&result.Total).Error; err != nil {
+		return nil, err
+	}
+
+	db = db.Order("created_at DESC, id DESC")
+	if pageSize > 0 {
+		db = db.Offset((page - 1) * pageSize).Limit(pageSize)
+	}
+	if err := db.Find(&result.Attempts).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *GormLoginAttemptRepository) DeleteByUser(userID uint64, email string) error {
+	return r.db.Where("user_id = ? OR email = ?", userID, email).Delete(&models.LoginAttempt{}).Error
+}
+
+func (r *GormLoginAttemptRepository) DeleteBefore(before time.Time) (int64, error) {
+	result := r.db.Where("created_at < ?", before).Delete(&models.LoginAttempt{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"events"
+	"login-api/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OutboxRepository interface {
+	Add(event *models.OutboxEvent) error
+	// CorrelationID identifica os eventos gravados por esta instância, isto é, pela mesma transação.
+	CorrelationID() string
+	// ProcessDue entrega a fn os eventos pendentes cujo horário de tentativa já chegou,
+	// em ordem, travando as linhas para que outras instâncias do relay as pulem.
+	ProcessDue(now time.Time, limit int, fn func(repo OutboxRepository, events []models.OutboxEvent) error) error
+	MarkSent(id uint64, at time.Time) error
+	MarkFailed(id uint64, lastError string, nextAttemptAt time.Time) error
+	DeleteSentBefore(before time.Time) (int64, error)
+	// DeleteByUser remove os eventos, entregues ou não, cujo payload identifica o usuário pelo id
+	// ou pelo email; usado na anonimização para não manter os dados até o fim da retenção.
+	DeleteByUser(userID uint64, email string) (int64, error)
+}
+
+type GormOutboxRepository struct {
+	db            *gorm.DB
+	correlationID string
+}
+
+func NewGormOutboxRepository(db *gorm.DB) *GormOutboxRepository {
+	return &GormOutboxRepository{db: db, correlationID: events.NewID()}
+}
+
+func (r *GormOutboxRepository) CorrelationID() string {
+	return r.correlationID
+}
+
+func (r *GormOutboxRepository) Add(event *models.OutboxEvent) error {
+	if event.NextAttemptAt.IsZero() {
+		event.NextAttemptAt = time.Now()
+	}
+	return r.db.Create(event).Error
+}
+
+func (r *GormOutboxRepository) ProcessDue(now time.Time, limit int, fn func(repo OutboxRepository, events []models.OutboxEvent) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var events []models.OutboxEvent
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("sent_at IS NULL AND next_attempt_at <= ?", now).
+			Order("id").
+			Limit(limit).
+			Find(&events).Error
+		if err != nil || len(events) == 0 {
+			return err
+		}
+		return fn(NewGormOutboxRepository(tx), events)
+	})
+}
+
+func (r *GormOutboxRepository) MarkSent(id uint64, at time.Time) error {
+	return r.db.Model(&models.OutboxEvent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"sent_at":    at,
+		"last_error": "",
+	}).Error
+}
+
+func (r *GormOutboxRepository) MarkFailed(id uint64, lastError string, nextAttemptAt time.Time) error {
+	return r.db.Model(&models.OutboxEvent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"attempts":        gorm.Expr("attempts + 1"),
+		"last_error":      lastError,
+		"next_attempt_at": nextAttemptAt,
+	}).Error
+}
+
+func (r *GormOutboxRepository) DeleteByUser(userID uint64, email string) (int64, error) {
+	id := strconv.FormatUint(userID, 10)
+	result := r.db.
+		Where("routing_key LIKE 'user.%' AND (payload::jsonb -> 'data' ->> 'id' = ? OR payload::jsonb -> 'data' ->> 'user_id' = ?)", id, id).
+		Or("strpos(lower(payload), ?) > 0", strconv.Quote(strings.ToLower(email))).
+		Delete(&models.OutboxEvent{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *GormOutboxRepository) DeleteSentBefore(before time.Time) (int64, error) {
+	result := r.db.Where("sent_at < ?", before).Delete(&models.OutboxEvent{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"login-api/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TokenRepository interface {
+	Revoke(token *models.RevokedToken) error
+	IsRevoked(tokenID string) (bool, error)
+	DeleteExpired(before time.Time) error
+	FindByUsername(username string) ([]models.RevokedToken, error)
+	DeleteByUsername(username string) error
+}
+
+type GormTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewGormTokenRepository(db *gorm.DB) *GormTokenRepository {
+	return &GormTokenRepository{db: db}
+}
+
+func (r *GormTokenRepository) Revoke(token *models.RevokedToken) error {
+	return r.db.Where("token_id = ?", token.TokenID).FirstOrCreate(token).Error
+}
+
+func (r *GormTokenRepository) IsRevoked(tokenID string) (bool, error) {
+	var count int64
+	result := r.db.Model(&models.RevokedToken{}).Where("token_id = ?", tokenID).Count(&count)
+	return count > 0, result.Error
+}
+
+func (r *GormTokenRepository) DeleteExpired(before time.Time) error {
+	return r.db.Where("expires_at < ?", before).Delete(&models.RevokedToken{}).Error
+}
+
+func (r *GormTokenRepository) FindByUsername(username string) ([]models.RevokedToken, error) {
+	var tokens []models.RevokedToken
+	result := r.db.Where("username = ?", username).Order("revoked_at").Find(&tokens)
+	return tokens, result.Error
+}
+
+func (r *GormTokenRepository) DeleteByUsername(username string) error {
+	return r.db.Where("username = ?", username).Delete(&models.RevokedToken{}).Error
+}
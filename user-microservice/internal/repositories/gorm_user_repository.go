@@ -1,25 +1,67 @@
 package repositories
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"login-api/models"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ErrVersionConflict indica que o registro mudou desde a versão lida pelo cliente.
+var ErrVersionConflict = errors.New("version conflict")
+
+// Condição de atribuição vigente em user_roles; recebe o instante duas vezes.
+const activeAssignment = "(valid_from IS NULL OR valid_from <= ?) AND (valid_until IS NULL OR valid_until > ?)"
+
+// Campos aceitos em ?sort=, mapeados para a coluna.
+var UserSortFields = map[string]string{
+	"id":            "id",
+	"name":          "name",
+	"email":         "email",
+	"register_date": "register_date",
+}
+
 type UserRepository interface {
 	FindAll() ([]models.User, error)
+	FindPage(query models.UserListQuery) (*models.UserPage, error)
+	FindInBatches(query models.UserListQuery, batchSize int, fn func(users []models.User) error) error
 	FindByID(id uint64) (*models.User, error)
 	FindByEmail(email string) (*models.User, error)
+	FindByEmailIgnoreCase(email string) (*models.User, error)
 	Create(user *models.User) error
 	Update(user *models.User) error
-	Delete(id uint64) error
-	AddRole(userID uint64, roleID string) error
+	UpdateFields(id uint64, fields map[string]interface{}) error
+	UpdateVersioned(id, version uint64, fields map[string]interface{}) error
+	Delete(id, version uint64) error
+	AddRole(userID uint64, roleID string, grant models.RoleGrant) error
 	RemoveRole(userID uint64, roleID string) error
 	GetUserWithRoles(id uint64) (*models.User, error)
+	GetUserWithRolesForUpdate(id uint64) (*models.User, error)
+	FindActiveRoles(userID uint64, at time.Time) ([]models.Role, error)
+	FindRoleSources(userID uint64, at time.Time) ([]models.RoleSource, error)
+	FindAssignmentsExpiring(from, to time.Time) ([]models.RoleAssignment, error)
+	FindAssignmentsByUser(userID uint64) ([]models.RoleAssignment, error)
+	FindUsersWithRolesStarting(from, to time.Time) ([]uint64, error)
+	FindDeletedByID(id uint64) (*models.User, error)
+	FindDeletedBefore(before time.Time) ([]models.User, error)
+	Restore(id uint64) error
+	Purge(id uint64) error
+	Erase(id uint64, fields map[string]interface{}) error
+	FindRolesByNames(names []string) ([]models.Role, error)
+	FindRolesByIDs(ids []uint64) ([]models.Role, error)
+	Transaction(fn func(repo UserRepository) error) error
+	Outbox() OutboxRepository
 }
 
 type GormUserRepository struct {
-	db *gorm.DB
+	db     *gorm.DB
+	outbox *GormOutboxRepository
 }
 
 func NewGormUserRepository(db *gorm.DB) *GormUserRepository {
@@ -32,6 +74,121 @@ func (r *GormUserRepository) FindAll() ([]models.User, error) {
 	return users, result.Error
 }
 
+func (r *GormUserRepository) FindPage(query models.UserListQuery) (*models.UserPage, error) {
+	page := &models.UserPage{}
+	if err := filterUsers(r.db.Model(&models.User{}), query).Count(&page.Total).Error; err != nil {
+		return nil, err
+	}
+
+	column, ok := UserSortFields[query.Sort]
+	if !ok {
+		column = "id"
+	}
+	direction := " ASC"
+	if query.Desc {
+		direction = " DESC"
+	}
+
+	db := filterUsers(r.db.Preload("Roles"), query).
+		Order(column + direction).
+		Order("id" + direction).
+		Limit(query.PageSize + 1)
+
+	if query.Cursor != "" {
+		cursor, err := decodeCursor(query.Cursor)
+		if err != nil || cursor.Sort != column {
+			return nil, ErrInvalidCursor
+		}
+		comparison := " > ?"
+		if query.Desc {
+			comparison = " < ?"
+		}
+		db = db.Where("("+column+comparison+") OR ("+column+" = ? AND id"+comparison+")", cursor.Value, cursor.Value, cursor.ID)
+	} else if query.Page > 1 {
+		db = db.Offset((query.Page - 1) * query.PageSize)
+	}
+
+	if err := db.Find(&page.Users).Error; err != nil {
+		return nil, err
+	}
+
+	// Busca um registro a mais só para saber se existe próxima página.
+	if len(page.Users) > query.PageSize {
+		page.Users = page.Users[:query.PageSize]
+		last := page.Users[len(page.Users)-1]
+		page.NextCursor = encodeCursor(column, last)
+	}
+	return page, nil
+}
+
+// FindInBatches percorre os usuários filtrados em lotes ordenados por id, sem carregar a tabela inteira.
+func (r *GormUserRepository) FindInBatches(query models.UserListQuery, batchSize int, fn func(users []models.User) error) error {
+	var users []models.User
+	result := filterUsers(r.db.Preload("Roles"), query).FindInBatches(&users, batchSize, func(tx *gorm.DB, batch int) error {
+		return fn(users)
+	})
+	return result.Error
+}
+
+func filterUsers(db *gorm.DB, query models.UserListQuery) *gorm.DB {
+	if query.IncludeDeleted {
+		db = db.Unscoped()
+	}
+	if query.Name != "" {
+		db = db.Where("name ILIKE ?", "%"+query.Name+"%")
+	}
+	if query.Email != "" {
+		db = db.Where("email ILIKE ?", "%"+query.Email+"%")
+	}
+	if query.Role != "" {
+		db = db.Where("id IN (SELECT user_roles.user_id FROM user_roles JOIN roles ON roles.id = user_roles.role_id WHERE roles.name = ?)", query.Role)
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+	if query.RegisteredFrom != nil {
+		db = db.Where("register_date >= ?", *query.RegisteredFrom)
+	}
+	if query.RegisteredTo != nil {
+		db = db.Where("register_date <= ?", *query.RegisteredTo)
+	}
+	return db
+}
+
+type userCursor struct {
+	Sort  string      `json:"s"`
+	Value interface{} `json:"v"`
+	ID    uint64      `json:"id"`
+}
+
+func encodeCursor(column string, user models.User) string {
+	cursor := userCursor{Sort: column, ID: user.ID}
+	switch column {
+	case "name":
+		cursor.Value = user.Name
+	case "email":
+		cursor.Value = user.Email
+	case "register_date":
+		cursor.Value = user.RegisterDate.Format(time.RFC3339Nano)
+	default:
+		cursor.Value = user.ID
+	}
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(value string) (*userCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor userCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil || cursor.Value == nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
 func (r *GormUserRepository) FindByID(id uint64) (*models.User, error) {
 	var user models.User
 	result := r.db.Where("id = ?", id).First(&user)
@@ -50,6 +207,18 @@ func (r *GormUserRepository) GetUserWithRoles(id uint64) (*models.User, error) {
 	return &user, nil
 }
 
+// GetUserWithRolesForUpdate trava a linha do usuário até o fim da transação. AddRole e RemoveRole
+// também atualizam essa linha antes de mexer em user_roles, então os papéis lidos aqui não mudam
+// enquanto a transação estiver aberta.
+func (r *GormUserRepository) GetUserWithRolesForUpdate(id uint64) (*models.User, error) {
+	var user models.User
+	result := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Roles").Where("id = ?", id).First(&user)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &user, nil
+}
+
 func (r *GormUserRepository) FindByEmail(email string) (*models.User, error) {
 	var user models.User
 	result := r.db.Where("email = ?", email).First(&user)
@@ -59,22 +228,269 @@ func (r *GormUserRepository) FindByEmail(email string) (*models.User, error) {
 	return &user, nil
 }
 
+// FindByEmailIgnoreCase compara lower(email) dos dois lados, para a importação tratar
+// Foo@x e foo@x como o mesmo usuário.
+func (r *GormUserRepository) FindByEmailIgnoreCase(email string) (*models.User, error) {
+	var user models.User
+	result := r.db.Where("lower(email) = lower(?)", email).First(&user)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &user, nil
+}
+
 func (r *GormUserRepository) Create(user *models.User) error {
 	return r.db.Create(user).Error
 }
 
+// Update grava apenas os campos editáveis; senha, data de registro e papéis
+// não são sobrescritos pelo corpo do PUT. user.Version é a versão esperada.
 func (r *GormUserRepository) Update(user *models.User) error {
-	return r.db.Save(user).Error
+	err := r.UpdateVersioned(user.ID, user.Version, map[string]interface{}{
+		"name":            user.Name,
+		"email":           user.Email,
+		"service_account": user.ServiceAccount,
+	})
+	if err != nil {
+		return err
+	}
+	user.Version++
+	return nil
+}
+
+// UpdateFields atualiza somente as colunas informadas, sem conferir a versão esperada, mas
+// incrementa a versão para que o ETag anterior deixe de valer.
+func (r *GormUserRepository) UpdateFields(id uint64, fields map[string]interface{}) error {
+	updates := map[string]interface{}{"version": gorm.Expr("version + 1")}
+	for column, value := range fields {
+		updates[column] = value
+	}
+	return r.db.Model(&models.User{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// UpdateVersioned atualiza as colunas informadas se o registro ainda estiver na versão
+// esperada, incrementando-a; caso contrário devolve ErrVersionConflict.
+func (r *GormUserRepository) UpdateVersioned(id, version uint64, fields map[string]interface{}) error {
+	updates := map[string]interface{}{"version": gorm.Expr("version + 1")}
+	for column, value := range fields {
+		updates[column] = value
+	}
+
+	result := r.db.Model(&models.User{}).Where("id = ? AND version = ?", id, version).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// Delete é um soft delete: preenche deleted_at e mantém os vínculos com os papéis
+// até o purge, para que o usuário possa ser restaurado.
+func (r *GormUserRepository) Delete(id, version uint64) error {
+	result := r.db.Where("version = ?", version).Delete(&models.User{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+func (r *GormUserRepository) FindDeletedByID(id uint64) (*models.User, error) {
+	var user models.User
+	result := r.db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&user)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &user, nil
+}
+
+func (r *GormUserRepository) FindDeletedBefore(before time.Time) ([]models.User, error) {
+	var users []models.User
+	result := r.db.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Find(&users)
+	return users, result.Error
+}
+
+func (r *GormUserRepository) Restore(id uint64) error {
+	return r.db.Unscoped().Model(&models.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"deleted_at": nil,
+		"version":    gorm.Expr("version + 1"),
+	}).Error
+}
+
+func (r *GormUserRepository) Purge(id uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM user_roles WHERE user_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM user_groups WHERE user_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM login_attempts WHERE user_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM invitations WHERE user_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&models.User{}, "id = ?", id).Error
+	})
+}
+
+func (r *GormUserRepository) AddRole(userID uint64, roleID string, grant models.RoleGrant) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// A versão é incrementada antes para travar a linha do usuário (ver GetUserWithRolesForUpdate).
+		if err := bumpVersion(tx, userID); err != nil {
+			return err
+		}
+		// Conceder de novo um papel que o usuário já tem (ou cuja atribuição expirou e ainda não foi
+		// removida pelo job) renova a atribuição com o novo prazo, em vez de violar a chave primária.
+		return tx.Exec(`
+			INSERT INTO user_roles (user_id, role_id, valid_from, valid_until, granted_by, reason) VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (user_id, role_id) DO UPDATE SET
+				valid_from = EXCLUDED.valid_from,
+				valid_until = EXCLUDED.valid_until,
+				granted_by = EXCLUDED.granted_by,
+				reason = EXCLUDED.reason`,
+			userID, roleID, grant.ValidFrom, grant.ValidUntil, grant.GrantedBy, grant.Reason,
+		).Error
+	})
+}
+
+// FindActiveRoles retorna os papéis efetivos: atribuições diretas vigentes (ignorando as expiradas ou
+// que ainda não começaram a valer) mais os papéis dos grupos do usuário.
+func (r *GormUserRepository) FindActiveRoles(userID uint64, at time.Time) ([]models.Role, error) {
+	var roles []models.Role
+	result := r.db.
+		Where("id IN (SELECT role_id FROM user_roles WHERE user_id = ? AND "+activeAssignment+")", userID, at, at).
+		Or("id IN (SELECT group_roles.role_id FROM group_roles JOIN user_groups ON user_groups.group_id = group_roles.group_id WHERE user_groups.user_id = ?)", userID).
+		Order("id").
+		Find(&roles)
+	return roles, result.Error
+}
+
+// FindRoleSources lista cada origem dos papéis efetivos do usuário, uma linha por atribuição direta ou grupo.
+func (r *GormUserRepository) FindRoleSources(userID uint64, at time.Time) ([]models.RoleSource, error) {
+	var sources []models.RoleSource
+	result := r.db.Raw(`
+		SELECT roles.id AS role_id, roles.name AS role_name, 'direct' AS type,
+			NULL AS group_id, '' AS group_name, user_roles.valid_until
+		FROM user_roles JOIN roles ON roles.id = user_roles.role_id
+		WHERE user_roles.user_id = ? AND `+activeAssignment+`
+		UNION ALL
+		SELECT roles.id, roles.name, 'group', groups.id, groups.name, NULL
+		FROM user_groups
+		JOIN groups ON groups.id = user_groups.group_id
+		JOIN group_roles ON group_roles.group_id = groups.id
+		JOIN roles ON roles.id = group_roles.role_id
+		WHERE user_groups.user_id = ?
+		ORDER BY role_id, type, group_name`, userID, at, at, userID).
+		Scan(&sources)
+	return sources, result.Error
+}
+
+// FindAssignmentsExpiring lista as atribuições com valid_until em (from, to], da mais próxima para a mais distante.
+func (r *GormUserRepository) FindAssignmentsExpiring(from, to time.Time) ([]models.RoleAssignment, error) {
+	var assignments []models.RoleAssignment
+	result := r.db.Table("user_roles").
+		Select("user_roles.user_id, users.email, user_roles.role_id, roles.name AS role_name, "+
+			"user_roles.valid_from, user_roles.valid_until, user_roles.granted_by, user_roles.reason").
+		Joins("JOIN users ON users.id = user_roles.user_id AND users.deleted_at IS NULL").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("user_roles.valid_until > ? AND user_roles.valid_until <= ?", from, to).
+		Order("user_roles.valid_until").
+		Scan(&assignments)
+	return assignments, result.Error
 }
 
-func (r *GormUserRepository) Delete(id uint64) error {
-	return r.db.Delete(&models.User{}, "id = ?", id).Error
+// FindAssignmentsByUser lista todas as atribuições diretas do usuário, vigentes ou não.
+func (r *GormUserRepository) FindAssignmentsByUser(userID uint64) ([]models.RoleAssignment, error) {
+	var assignments []models.RoleAssignment
+	result := r.db.Table("user_roles").
+		Select("user_roles.user_id, users.email, user_roles.role_id, roles.name AS role_name, "+
+			"user_roles.valid_from, user_roles.valid_until, user_roles.granted_by, user_roles.reason").
+		Joins("JOIN users ON users.id = user_roles.user_id").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("user_roles.user_id = ?", userID).
+		Order("user_roles.role_id").
+		Scan(&assignments)
+	return assignments, result.Error
 }
 
-func (r *GormUserRepository) AddRole(userID uint64, roleID string) error {
-	return r.db.Exec("INSERT INTO user_roles (user_id, role_id) VALUES (?, ?)", userID, roleID).Error
+// FindUsersWithRolesStarting retorna os usuários com alguma atribuição cujo valid_from está em (from, to].
+func (r *GormUserRepository) FindUsersWithRolesStarting(from, to time.Time) ([]uint64, error) {
+	var userIDs []uint64
+	result := r.db.Table("user_roles").
+		Distinct("user_id").
+		Where("valid_from > ? AND valid_from <= ?", from, to).
+		Pluck("user_id", &userIDs)
+	return userIDs, result.Error
 }
 
 func (r *GormUserRepository) RemoveRole(userID uint64, roleID string) error {
-	return r.db.Exec("DELETE FROM user_roles WHERE user_id = ? AND role_id = ?", userID, roleID).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, userID); err != nil {
+			return err
+		}
+		return tx.Exec("DELETE FROM user_roles WHERE user_id = ? AND role_id = ?", userID, roleID).Error
+	})
+}
+
+func (r *GormUserRepository) FindRolesByNames(names []string) ([]models.Role, error) {
+	var roles []models.Role
+	result := r.db.Where("name IN ?", names).Find(&roles)
+	return roles, result.Error
+}
+
+func (r *GormUserRepository) FindRolesByIDs(ids []uint64) ([]models.Role, error) {
+	var roles []models.Role
+	result := r.db.Where("id IN ?", ids).Find(&roles)
+	return roles, result.Error
+}
+
+// Erase anonimiza o registro (mesmo se excluído) e remove os vínculos com papéis e grupos
+// e os convites.
+func (r *GormUserRepository) Erase(id uint64, fields map[string]interface{}) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM user_roles WHERE user_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM user_groups WHERE user_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM invitations WHERE user_id = ?", id).Error; err != nil {
+			return err
+		}
+		updates := map[string]interface{}{"version": gorm.Expr("version + 1")}
+		for column, value := range fields {
+			updates[column] = value
+		}
+		return tx.Unscoped().Model(&models.User{}).Where("id = ?", id).Updates(updates).Error
+	})
+}
+
+// bumpVersion incrementa a versão do usuário quando algo da representação dele muda fora
+// do registro (papéis, grupos), para que o ETag anterior deixe de valer.
+func bumpVersion(db *gorm.DB, userID uint64) error {
+	return db.Exec("UPDATE users SET version = version + 1 WHERE id = ?", userID).Error
+}
+
+// Transaction executa fn com um repositório ligado à mesma transação.
+func (r *GormUserRepository) Transaction(fn func(repo UserRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		repo := NewGormUserRepository(tx)
+		repo.outbox = NewGormOutboxRepository(tx)
+		return fn(repo)
+	})
+}
+
+// Outbox devolve a outbox ligada à mesma conexão (ou transação) do repositório. Dentro de
+// Transaction é sempre a mesma instância, então os eventos da operação compartilham a correlação.
+func (r *GormUserRepository) Outbox() OutboxRepository {
+	if r.outbox != nil {
+		return r.outbox
+	}
+	return NewGormOutboxRepository(r.db)
 }
@@ -15,12 +15,12 @@ const (
 	ROLE_WATCHER  = "Watcher"
 )
 
-func Routers(router *gin.Engine, userController *controllers.UserController) {
+func Routers(router *gin.Engine, userController *controllers.UserController, tokenController *controllers.TokenController, groupController *controllers.GroupController, avatarController *controllers.AvatarController, privacyController *controllers.PrivacyController, loginHistoryController *controllers.LoginHistoryController, invitationController *controllers.InvitationController) {
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
 		AllowHeaders:     []string{"*"},
 		AllowMethods:     []string{"*"},
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    []string{"Content-Length", "ETag"},
 		AllowCredentials: true,
 	}))
 
@@ -28,22 +28,69 @@ func Routers(router *gin.Engine, userController *controllers.UserController) {
 		c.String(http.StatusOK, "Version 1")
 	})
 	router.POST("login", controllers.Login)
+	// O parâmetro é o token do convite; o Gin exige o mesmo nome usado em /invitations/:id.
+	router.POST("invitations/:id/accept", invitationController.AcceptInvitation)
 
 	privateRoute := router.Group("")
 	privateRoute.Use(controllers.Authenticate)
 	{
 		privateRoute.GET("verifyToken", controllers.VerifyToken)
+		privateRoute.POST("introspect", tokenController.Introspect)
+		privateRoute.POST("logout", tokenController.Logout)
+		privateRoute.POST("refresh", tokenController.Refresh)
 
 		userRoutes := privateRoute.Group("user")
 		{
 			userRoutes.GET("", middleware.RequireRoles(ROLE_ADMIN), userController.GetUsers)
+			userRoutes.GET("export", middleware.RequireRoles(ROLE_ADMIN), userController.ExportUsers)
+			userRoutes.GET("roles/expiring", middleware.RequireRoles(ROLE_ADMIN), userController.GetExpiringRoles)
 			userRoutes.GET(":id", middleware.RequireRoles(ROLE_ADMIN), userController.GetUser)
 			userRoutes.POST("", middleware.RequireRoles(ROLE_ADMIN), userController.CreateUser)
+			userRoutes.POST("import", middleware.RequireRoles(ROLE_ADMIN), invitationController.ImportUsers)
 			userRoutes.PUT(":id", middleware.RequireRoles(ROLE_ADMIN), userController.UpdateUser)
+			userRoutes.PATCH(":id", middleware.RequireRoles(ROLE_ADMIN), userController.PatchUser)
 			userRoutes.DELETE(":id", middleware.RequireRoles(ROLE_ADMIN), userController.DeleteUser)
+			userRoutes.PUT(":id/status", middleware.RequireRoles(ROLE_ADMIN), userController.ChangeUserStatus)
+			userRoutes.POST(":id/restore", middleware.RequireRoles(ROLE_ADMIN), userController.RestoreUser)
+			userRoutes.GET(":id/logins", middleware.RequireRoles(ROLE_ADMIN), loginHistoryController.GetUserLogins)
+			userRoutes.GET(":id/personal-data", middleware.RequireRoles(ROLE_ADMIN), privacyController.ExportPersonalData)
+			userRoutes.POST(":id/erase", middleware.RequireRoles(ROLE_ADMIN), privacyController.EraseUser)
 
-			userRoutes.POST(":userId/roles/:roleId", middleware.RequireRoles(ROLE_ADMIN), userController.AddRoleToUser)
+			userRoutes.GET(":id/avatar", avatarController.GetAvatar)
+			userRoutes.GET(":id/roles/effective", middleware.RequireRoles(ROLE_ADMIN), userController.GetEffectiveRoles)
+			userRoutes.PUT(":id/roles", middleware.RequireRoles(ROLE_ADMIN), userController.ReplaceUserRoles)
+			userRoutes.POST(":id/roles/:roleId", middleware.RequireRoles(ROLE_ADMIN), userController.AddRoleToUser)
 			userRoutes.DELETE("remove/:userId/roles/:roleId", middleware.RequireRoles(ROLE_ADMIN), userController.RemoveRoleFromUser)
 		}
+
+		meRoutes := privateRoute.Group("me")
+		{
+			meRoutes.PUT("avatar", avatarController.UploadAvatar)
+			meRoutes.DELETE("avatar", avatarController.DeleteAvatar)
+			meRoutes.GET("logins", loginHistoryController.GetMyLogins)
+			meRoutes.PUT("password", userController.ChangePassword)
+		}
+
+		invitationRoutes := privateRoute.Group("invitations")
+		{
+			invitationRoutes.GET("", middleware.RequireRoles(ROLE_ADMIN), invitationController.GetInvitations)
+			invitationRoutes.POST("", middleware.RequireRoles(ROLE_ADMIN), invitationController.CreateInvitation)
+			invitationRoutes.POST(":id/resend", middleware.RequireRoles(ROLE_ADMIN), invitationController.ResendInvitation)
+			invitationRoutes.DELETE(":id", middleware.RequireRoles(ROLE_ADMIN), invitationController.RevokeInvitation)
+		}
+
+		groupRoutes := privateRoute.Group("group")
+		{
+			groupRoutes.GET("", middleware.RequireRoles(ROLE_ADMIN), groupController.GetGroups)
+			groupRoutes.GET(":id", middleware.RequireRoles(ROLE_ADMIN), groupController.GetGroup)
+			groupRoutes.POST("", middleware.RequireRoles(ROLE_ADMIN), groupController.CreateGroup)
+			groupRoutes.PUT(":id", middleware.RequireRoles(ROLE_ADMIN), groupController.UpdateGroup)
+			groupRoutes.DELETE(":id", middleware.RequireRoles(ROLE_ADMIN), groupController.DeleteGroup)
+
+			groupRoutes.GET(":id/members", middleware.RequireRoles(ROLE_ADMIN), groupController.GetMembers)
+			groupRoutes.POST(":id/members/:userId", middleware.RequireRoles(ROLE_ADMIN), groupController.AddMember)
+			groupRoutes.DELETE(":id/members/:userId", middleware.RequireRoles(ROLE_ADMIN), groupController.RemoveMember)
+			groupRoutes.PUT(":id/roles", middleware.RequireRoles(ROLE_ADMIN), groupController.ReplaceGroupRoles)
+		}
 	}
 }
@@ -0,0 +1,23 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+var ErrNotFound = errors.New("object not found")
+
+// ObjectInfo são os metadados devolvidos junto com o conteúdo do objeto.
+type ObjectInfo struct {
+	ContentType  string
+	Size         int64
+	LastModified time.Time
+}
+
+// Storage guarda arquivos por chave (ex.: avatars/42/256.png).
+type Storage interface {
+	Put(key string, data []byte, contentType string) error
+	Get(key string) (io.ReadCloser, *ObjectInfo, error)
+	Delete(key string) error
+}
@@ -0,0 +1,151 @@
+package usecases
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"login-api/internal/repositories"
+	"login-api/internal/storage"
+	"login-api/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/image/draw"
+)
+
+// Tamanhos gerados a cada upload (quadrados, em pixels); o maior é o padrão do GET.
+var AvatarSizes = []int{64, 128, 256}
+
+const DefaultAvatarSize = 256
+
+var (
+	ErrUnsupportedAvatarType = errors.New("avatar must be a PNG, JPEG or GIF image")
+	ErrInvalidAvatar         = errors.New("avatar image could not be decoded")
+	ErrAvatarNotFound        = errors.New("avatar not found")
+)
+
+// Imagens acima disso são recusadas antes de decodificar, para não alocar memória demais.
+const maxAvatarPixels = 40_000_000
+
+type AvatarUseCase struct {
+	userRepo repositories.UserRepository
+	storage  storage.Storage
+}
+
+func NewAvatarUseCase(userRepo repositories.UserRepository, storage storage.Storage) *AvatarUseCase {
+	return &AvatarUseCase{userRepo: userRepo, storage: storage}
+}
+
+// Upload valida o conteúdo real do arquivo (não o Content-Type enviado), gera as versões
+// redimensionadas em PNG e marca o usuário como tendo avatar.
+func (uc *AvatarUseCase) Upload(email string, data []byte) (*models.User, error) {
+	user, err := uc.userRepo.FindByEmail(email)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	switch http.DetectContentType(data) {
+	case "image/png", "image/jpeg", "image/gif":
+	default:
+		return nil, ErrUnsupportedAvatarType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || config.Width*config.Height > maxAvatarPixels {
+		return nil, ErrInvalidAvatar
+	}
+	source, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidAvatar
+	}
+
+	for _, size := range AvatarSizes {
+		var encoded bytes.Buffer
+		if err := png.Encode(&encoded, resizeSquare(source, size)); err != nil {
+			return nil, err
+		}
+		if err := uc.storage.Put(avatarKey(user.ID, size), encoded.Bytes(), "image/png"); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	if err := uc.userRepo.UpdateFields(user.ID, map[string]interface{}{"avatar_updated_at": now}); err != nil {
+		return nil, err
+	}
+	user.AvatarUpdatedAt = &now
+	return user, nil
+}
+
+// Open devolve o avatar no tamanho pedido; o chamador fecha o reader.
+func (uc *AvatarUseCase) Open(userID uint64, size int) (io.ReadCloser, *storage.ObjectInfo, error) {
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, nil, errors.New("user not found")
+	}
+	if user.AvatarUpdatedAt == nil {
+		return nil, nil, ErrAvatarNotFound
+	}
+
+	body, info, err := uc.storage.Get(avatarKey(user.ID, size))
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, ErrAvatarNotFound
+	}
+	return body, info, err
+}
+
+func (uc *AvatarUseCase) Delete(email string) error {
+	user, err := uc.userRepo.FindByEmail(email)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	if err := uc.deleteFiles(user.ID); err != nil {
+		return err
+	}
+	return uc.userRepo.UpdateFields(user.ID, map[string]interface{}{"avatar_updated_at": nil})
+}
+
+func (uc *AvatarUseCase) deleteFiles(userID uint64) error {
+	for _, size := range AvatarSizes {
+		if err := uc.storage.Delete(avatarKey(userID, size)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func IsAvatarSize(size int) bool {
+	for _, allowed := range AvatarSizes {
+		if allowed == size {
+			return true
+		}
+	}
+	return false
+}
+
+func avatarKey(userID uint64, size int) string {
+	return "avatars/" + strconv.FormatUint(userID, 10) + "/" + strconv.Itoa(size) + ".png"
+}
+
+// resizeSquare recorta o centro da imagem em um quadrado e redimensiona para size x size.
+func resizeSquare(source image.Image, size int) image.Image {
+	bounds := source.Bounds()
+	side := bounds.Dx()
+	if bounds.Dy() < side {
+		side = bounds.Dy()
+	}
+	crop := image.Rect(0, 0, side, side).Add(image.Pt(
+		bounds.Min.X+(bounds.Dx()-side)/2,
+		bounds.Min.Y+(bounds.Dy()-side)/2,
+	))
+
+	target := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.CatmullRom.Scale(target, target.Bounds(), source, crop, draw.Src, nil)
+	return target
+}
@@ -0,0 +1,243 @@
+package usecases
+
+import (
+	"errors"
+	"events"
+	"login-api/internal/repositories"
+	"login-api/models"
+	"strings"
+	"time"
+)
+
+// fakeOutbox guarda os eventos gravados, como a tabela outbox_events.
+type fakeOutbox struct {
+	repositories.OutboxRepository
+	events []models.OutboxEvent
+}
+
+func (o *fakeOutbox) Add(event *models.OutboxEvent) error {
+	o.events = append(o.events, *event)
+	return nil
+}
+
+func (o *fakeOutbox) DeleteByUser(userID uint64, email string) (int64, error) {
+	var kept []models.OutboxEvent
+	for _, event := range o.events {
+		var data struct {
+			ID     uint64 `json:"id"`
+			UserID uint64 `json:"user_id"`
+		}
+		envelope, err := events.Decode(event.RoutingKey, []byte(event.Payload))
+		if err == nil {
+			envelope.DataAs(&data)
+		}
+		ownEvent := strings.HasPrefix(event.RoutingKey, "user.") && (data.ID == userID || data.UserID == userID)
+		if ownEvent || strings.Contains(strings.ToLower(event.Payload), strings.ToLower(email)) {
+			continue
+		}
+		kept = append(kept, event)
+	}
+	deleted := int64(len(o.events) - len(kept))
+	o.events = kept
+	return deleted, nil
+}
+
+func (o *fakeOutbox) CorrelationID() string {
+	return "correlation"
+}
+
+func (o *fakeOutbox) types() []string {
+	var types []string
+	for _, event := range o.events {
+		types = append(types, event.RoutingKey)
+	}
+	return types
+}
+
+func (o *fakeOutbox) data(eventType string, v interface{}) error {
+	for _, event := range o.events {
+		if event.RoutingKey != eventType {
+			continue
+		}
+		envelope, err := events.Decode(event.RoutingKey, []byte(event.Payload))
+		if err != nil {
+			return err
+		}
+		return envelope.DataAs(v)
+	}
+	return errors.New("event not found: " + eventType)
+}
+
+// fakeUserRepository implementa só o que os testes usam; o resto do UserRepository
+// fica no valor nil embutido e entra em pânico se for chamado.
+type fakeUserRepository struct {
+	repositories.UserRepository
+	users  map[uint64]*models.User
+	roles  []models.Role
+	outbox *fakeOutbox
+	// activeRoles substitui user.Roles em FindActiveRoles, como os papéis de grupo e as
+	// atribuições expiradas fazem no banco.
+	activeRoles map[uint64][]models.Role
+}
+
+func newFakeUserRepository(roles ...models.Role) *fakeUserRepository {
+	return &fakeUserRepository{users: make(map[uint64]*models.User), roles: roles, outbox: &fakeOutbox{}}
+}
+
+func (r *fakeUserRepository) FindByID(id uint64) (*models.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *fakeUserRepository) FindAll() ([]models.User, error) {
+	var users []models.User
+	for id := uint64(1); len(users) < len(r.users); id++ {
+		if user, ok := r.users[id]; ok {
+			users = append(users, *user)
+		}
+	}
+	return users, nil
+}
+
+// FindByEmail compara o email exato, como a coluna no banco.
+func (r *fakeUserRepository) FindByEmail(email string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (r *fakeUserRepository) FindByEmailIgnoreCase(email string) (*models.User, error) {
+	for _, user := range r.users {
+		if strings.EqualFold(user.Email, email) {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (r *fakeUserRepository) GetUserWithRoles(id uint64) (*models.User, error) {
+	return r.FindByID(id)
+}
+
+func (r *fakeUserRepository) FindActiveRoles(userID uint64, at time.Time) ([]models.Role, error) {
+	user, err := r.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if roles, ok := r.activeRoles[userID]; ok {
+		return roles, nil
+	}
+	return user.Roles, nil
+}
+
+func (r *fakeUserRepository) FindRolesByNames(names []string) ([]models.Role, error) {
+	var roles []models.Role
+	for _, role := range r.roles {
+		for _, name := range names {
+			if role.Name == name {
+				roles = append(roles, role)
+				break
+			}
+		}
+	}
+	return roles, nil
+}
+
+func (r *fakeUserRepository) Transaction(fn func(repo repositories.UserRepository) error) error {
+	return fn(r)
+}
+
+func (r *fakeUserRepository) Outbox() repositories.OutboxRepository {
+	return r.outbox
+}
+
+// fakeInvitationRepository grava usuários e convites no fakeUserRepository.
+type fakeInvitationRepository struct {
+	users       *fakeUserRepository
+	invitations map[uint64]*models.Invitation
+}
+
+func newFakeInvitationRepository(users *fakeUserRepository) *fakeInvitationRepository {
+	return &fakeInvitationRepository{users: users, invitations: make(map[uint64]*models.Invitation)}
+}
+
+func (r *fakeInvitationRepository) FindPending() ([]models.Invitation, error) {
+	var pending []models.Invitation
+	for _, invitation := range r.invitations {
+		if invitation.AcceptedAt == nil {
+			pending = append(pending, *invitation)
+		}
+	}
+	return pending, nil
+}
+
+func (r *fakeInvitationRepository) FindByID(id uint64) (*models.Invitation, error) {
+	invitation, ok := r.invitations[id]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	copied := *invitation
+	if user, ok := r.users.users[invitation.UserID]; ok {
+		copied.User = *user
+	}
+	return &copied, nil
+}
+
+func (r *fakeInvitationRepository) Create(invitation *models.Invitation, user *models.User, roleIDs []uint64) error {
+	user.ID = uint64(len(r.users.users) + 1)
+	for _, roleID := range roleIDs {
+		user.Roles = append(user.Roles, models.Role{ID: roleID})
+	}
+	stored := *user
+	r.users.users[user.ID] = &stored
+
+	invitation.ID = uint64(len(r.invitations) + 1)
+	invitation.UserID = user.ID
+	copied := *invitation
+	r.invitations[invitation.ID] = &copied
+	return nil
+}
+
+func (r *fakeInvitationRepository) Update(invitation *models.Invitation) error {
+	copied := *invitation
+	r.invitations[invitation.ID] = &copied
+	return nil
+}
+
+// Accept e Revoke só alteram usuários pending, como as condições do UPDATE/DELETE no banco.
+func (r *fakeInvitationRepository) Accept(invitation *models.Invitation, password string, at time.Time) error {
+	user := r.users.users[invitation.UserID]
+	if user.Status != models.UserStatusPending || r.invitations[invitation.ID].AcceptedAt != nil {
+		return repositories.ErrUserNotPending
+	}
+	user.Password = password
+	user.Status = models.UserStatusActive
+	r.invitations[invitation.ID].AcceptedAt = &at
+	return nil
+}
+
+func (r *fakeInvitationRepository) Revoke(invitation *models.Invitation) error {
+	if user := r.users.users[invitation.UserID]; user.Status != models.UserStatusPending {
+		return repositories.ErrUserNotPending
+	}
+	delete(r.invitations, invitation.ID)
+	delete(r.users.users, invitation.UserID)
+	return nil
+}
+
+func (r *fakeInvitationRepository) Transaction(fn func(repo repositories.InvitationRepository) error) error {
+	return fn(r)
+}
+
+func (r *fakeInvitationRepository) Outbox() repositories.OutboxRepository {
+	return r.users.outbox
+}
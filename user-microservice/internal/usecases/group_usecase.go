@@ -0,0 +1,138 @@
+package usecases
+
+import (
+	"errors"
+	"login-api/internal/repositories"
+	"login-api/models"
+)
+
+// GroupUseCase gerencia grupos; toda mudança que altera os papéis efetivos
+// dos membros também atualiza o consumer deles no Kong.
+type GroupUseCase struct {
+	repo        repositories.GroupRepository
+	userRepo    repositories.UserRepository
+	userUseCase *UserUseCase
+}
+
+func NewGroupUseCase(repo repositories.GroupRepository, userRepo repositories.UserRepository, userUseCase *UserUseCase) *GroupUseCase {
+	return &GroupUseCase{
+		repo:        repo,
+		userRepo:    userRepo,
+		userUseCase: userUseCase,
+	}
+}
+
+func (uc *GroupUseCase) GetAll() ([]models.Group, error) {
+	return uc.repo.FindAll()
+}
+
+func (uc *GroupUseCase) GetByID(id uint64) (*models.Group, error) {
+	group, err := uc.repo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("group not found")
+	}
+	return group, nil
+}
+
+func (uc *GroupUseCase) Create(group *models.Group) error {
+	existingGroup, _ := uc.repo.FindByName(group.Name)
+	if existingGroup != nil {
+		return errors.New("group already exists")
+	}
+	return uc.repo.Create(group)
+}
+
+func (uc *GroupUseCase) Update(id uint64, group *models.Group) (*models.Group, error) {
+	if _, err := uc.repo.FindByID(id); err != nil {
+		return nil, errors.New("group not found")
+	}
+
+	existingGroup, _ := uc.repo.FindByName(group.Name)
+	if existingGroup != nil && existingGroup.ID != id {
+		return nil, errors.New("group already exists")
+	}
+
+	group.ID = id
+	if err := uc.repo.Update(group); err != nil {
+		return nil, err
+	}
+	return uc.repo.FindByID(id)
+}
+
+func (uc *GroupUseCase) Delete(id uint64) error {
+	if _, err := uc.repo.FindByID(id); err != nil {
+		return errors.New("group not found")
+	}
+
+	memberIDs, err := uc.repo.FindMemberIDs(id)
+	if err != nil {
+		return err
+	}
+	if err := uc.repo.Delete(id); err != nil {
+		return err
+	}
+	uc.provisionAll(memberIDs)
+	return nil
+}
+
+func (uc *GroupUseCase) GetMembers(id uint64) ([]models.User, error) {
+	if _, err := uc.repo.FindByID(id); err != nil {
+		return nil, errors.New("group not found")
+	}
+	return uc.repo.FindMembers(id)
+}
+
+func (uc *GroupUseCase) AddMember(groupID, userID uint64) error {
+	if _, err := uc.repo.FindByID(groupID); err != nil {
+		return errors.New("group not found")
+	}
+	if _, err := uc.userRepo.FindByID(userID); err != nil {
+		return errors.New("user not found")
+	}
+
+	if err := uc.repo.AddMember(groupID, userID); err != nil {
+		return err
+	}
+	uc.userUseCase.provision(userID)
+	return nil
+}
+
+func (uc *GroupUseCase) RemoveMember(groupID, userID uint64) error {
+	if _, err := uc.repo.FindByID(groupID); err != nil {
+		return errors.New("group not found")
+	}
+
+	if err := uc.repo.RemoveMember(groupID, userID); err != nil {
+		return err
+	}
+	uc.userUseCase.provision(userID)
+	return nil
+}
+
+// ReplaceRoles define o conjunto completo de papéis concedidos pelo grupo.
+func (uc *GroupUseCase) ReplaceRoles(groupID uint64, roleIDs []uint64) (*models.Group, error) {
+	if _, err := uc.repo.FindByID(groupID); err != nil {
+		return nil, errors.New("group not found")
+	}
+
+	unique := uniqueIDs(roleIDs)
+	if err := checkRolesExist(uc.userRepo, unique); err != nil {
+		return nil, err
+	}
+	if err := uc.repo.ReplaceRoles(groupID, unique); err != nil {
+		return nil, err
+	}
+
+	memberIDs, err := uc.repo.FindMemberIDs(groupID)
+	if err != nil {
+		return nil, err
+	}
+	uc.provisionAll(memberIDs)
+	return uc.repo.FindByID(groupID)
+}
+
+func (uc *GroupUseCase) provisionAll(userIDs []uint64) {
+	for _, userID := range userIDs {
+		uc.userUseCase.provision(userID)
+	}
+}
@@ -0,0 +1,243 @@
+package usecases
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"events"
+	"fmt"
+	"login-api/internal/repositories"
+	"login-api/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidInvitation = errors.New("invalid invitation")
+	ErrInvitationExpired = errors.New("invitation expired")
+)
+
+// InvitationConfig assina os links de aceite e define por quanto tempo eles valem.
+// AcceptURL recebe o token no lugar de {token}.
+type InvitationConfig struct {
+	Secret    string
+	AcceptURL string
+	TTL       time.Duration
+}
+
+type InvitationUseCase struct {
+	repo        repositories.InvitationRepository
+	userRepo    repositories.UserRepository
+	userUseCase *UserUseCase
+	config      InvitationConfig
+}
+
+func NewInvitationUseCase(repo repositories.InvitationRepository, userRepo repositories.UserRepository, userUseCase *UserUseCase, config InvitationConfig) *InvitationUseCase {
+	return &InvitationUseCase{
+		repo:        repo,
+		userRepo:    userRepo,
+		userUseCase: userUseCase,
+		config:      config,
+	}
+}
+
+func (uc *InvitationUseCase) GetPending() ([]models.Invitation, error) {
+	return uc.repo.FindPending()
+}
+
+// Invite cria o usuário pending com os papéis iniciais e publica user.invited com o link.
+func (uc *InvitationUseCase) Invite(request models.InvitationRequest, invitedBy string) (*models.Invitation, error) {
+	roleIDs := uniqueIDs(request.RoleIDs)
+	if err := checkRolesExist(uc.userRepo, roleIDs); err != nil {
+		return nil, err
+	}
+	if existingUser, _ := uc.userRepo.FindByEmail(request.Email); existingUser != nil {
+		return nil, errors.New("user already registered")
+	}
+
+	now := time.Now()
+	user := &models.User{
+		Name:            request.Name,
+		Email:           request.Email,
+		RegisterDate:    now,
+		Status:          models.UserStatusPending,
+		StatusReason:    "invited",
+		StatusChangedAt: &now,
+	}
+	invitation := &models.Invitation{
+		Email:     request.Email,
+		Name:      request.Name,
+		InvitedBy: invitedBy,
+	}
+	if err := uc.renew(invitation, now); err != nil {
+		return nil, err
+	}
+	err := uc.repo.Transaction(func(repo repositories.InvitationRepository) error {
+		if err := repo.Create(invitation, user, roleIDs); err != nil {
+			return err
+		}
+		return uc.send(repo.Outbox(), invitation)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return uc.repo.FindByID(invitation.ID)
+}
+
+// Resend gera um novo link (o anterior deixa de valer) com um novo prazo e reenvia o email.
+func (uc *InvitationUseCase) Resend(id uint64) (*models.Invitation, error) {
+	invitation, err := uc.findPending(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.renew(invitation, time.Now()); err != nil {
+		return nil, err
+	}
+	err = uc.repo.Transaction(func(repo repositories.InvitationRepository) error {
+		if err := repo.Update(invitation); err != nil {
+			return err
+		}
+		return uc.send(repo.Outbox(), invitation)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// Revoke cancela o convite e remove o usuário pending.
+func (uc *InvitationUseCase) Revoke(id uint64) error {
+	invitation, err := uc.findPending(id)
+	if err != nil {
+		return err
+	}
+	if invitation.User.Status != models.UserStatusPending {
+		return repositories.ErrUserNotPending
+	}
+	return uc.repo.Revoke(invitation)
+}
+
+// Accept valida o token, define a senha e ativa a conta. A partir daqui o usuário
+// existe para os outros serviços: é provisionado no Kong e user.created é publicado.
+func (uc *InvitationUseCase) Accept(token, password string) (*models.User, error) {
+	id, nonce, expiresAt, err := uc.parseToken(token)
+	if err != nil {
+		return nil, ErrInvalidInvitation
+	}
+
+	invitation, err := uc.repo.FindByID(id)
+	if err != nil || !hmac.Equal([]byte(invitation.Nonce), []byte(nonce)) {
+		return nil, ErrInvalidInvitation
+	}
+	if invitation.AcceptedAt != nil {
+		return nil, errors.New("invitation already accepted")
+	}
+	if invitation.User.Status != models.UserStatusPending {
+		return nil, repositories.ErrUserNotPending
+	}
+	if time.Now().After(expiresAt) {
+		return nil, ErrInvitationExpired
+	}
+
+	origin := events.OriginInvitation
+	if invitation.User.StatusReason == importedStatusReason {
+		origin = events.OriginImport
+	}
+	// Se o usuário deixou de estar pending desde a leitura, Accept falha e a transação é
+	// desfeita sem publicar user.created.
+	err = uc.repo.Transaction(func(repo repositories.InvitationRepository) error {
+		if err := repo.Accept(invitation, password, time.Now()); err != nil {
+			return err
+		}
+		return publish(repo.Outbox(), events.UserCreatedType, events.UserCreated{
+			ID:     invitation.UserID,
+			Name:   invitation.User.Name,
+			Email:  invitation.User.Email,
+			Origin: origin,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	uc.userUseCase.provision(invitation.UserID)
+	return uc.userRepo.GetUserWithRoles(invitation.UserID)
+}
+
+func (uc *InvitationUseCase) findPending(id uint64) (*models.Invitation, error) {
+	invitation, err := uc.repo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("invitation not found")
+	}
+	if invitation.AcceptedAt != nil {
+		return nil, errors.New("invitation already accepted")
+	}
+	return invitation, nil
+}
+
+// renew troca o nonce, o que invalida links enviados antes, e reinicia o prazo.
+func (uc *InvitationUseCase) renew(invitation *models.Invitation, now time.Time) error {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	invitation.Nonce = hex.EncodeToString(nonce)
+	invitation.SentAt = now
+	invitation.ExpiresAt = now.Add(uc.config.TTL)
+	return nil
+}
+
+// send grava user.invited na outbox informada; o email-microservice envia o link.
+func (uc *InvitationUseCase) send(outbox repositories.OutboxRepository, invitation *models.Invitation) error {
+	token := uc.signToken(invitation.ID, invitation.Nonce, invitation.ExpiresAt)
+	return publish(outbox, events.UserInvitedType, events.UserInvited{
+		InvitationID: invitation.ID,
+		UserID:       invitation.UserID,
+		Name:         invitation.Name,
+		Email:        invitation.Email,
+		InvitedBy:    invitation.InvitedBy,
+		AcceptURL:    strings.ReplaceAll(uc.config.AcceptURL, "{token}", token),
+		ExpiresAt:    invitation.ExpiresAt,
+	})
+}
+
+// O token é "<id>.<nonce>.<expiração unix>" em base64url seguido da assinatura HMAC-SHA256.
+func (uc *InvitationUseCase) signToken(id uint64, nonce string, expiresAt time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d.%s.%d", id, nonce, expiresAt.Unix())))
+	return payload + "." + uc.signature(payload)
+}
+
+func (uc *InvitationUseCase) parseToken(token string) (uint64, string, time.Time, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(uc.signature(payload))) {
+		return 0, "", time.Time{}, ErrInvalidInvitation
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return 0, "", time.Time{}, ErrInvalidInvitation
+	}
+	parts := strings.Split(string(data), ".")
+	if len(parts) != 3 {
+		return 0, "", time.Time{}, ErrInvalidInvitation
+	}
+	id, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, "", time.Time{}, ErrInvalidInvitation
+	}
+	expiresAt, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return 0, "", time.Time{}, ErrInvalidInvitation
+	}
+	return id, parts[1], time.Unix(expiresAt, 0), nil
+}
+
+func (uc *InvitationUseCase) signature(payload string) string {
+	mac := hmac.New(sha256.New, []byte(uc.config.Secret))
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,82 @@
+package usecases
+
+import (
+	"errors"
+	"login-api/internal/repositories"
+	"login-api/models"
+	"testing"
+)
+
+// staleInvitationRepository devolve o convite como foi lido antes de outra requisição
+// ativar o usuário, para exercitar a condição do próprio Accept.
+type staleInvitationRepository struct {
+	*fakeInvitationRepository
+	stale *models.Invitation
+}
+
+func (r *staleInvitationRepository) FindByID(id uint64) (*models.Invitation, error) {
+	copied := *r.stale
+	return &copied, nil
+}
+
+func inviteForTest(t *testing.T) (*InvitationUseCase, *fakeUserRepository, *fakeInvitationRepository, *models.Invitation) {
+	uc, userRepo, invitationRepo := newImportUseCase()
+	invitation, err := uc.Invite(models.InvitationRequest{Name: "Ana", Email: "ana@example.com"}, "admin@example.com")
+	if err != nil {
+		t.Fatalf("Invite: %v", err)
+	}
+	userRepo.outbox.events = nil
+	return uc, userRepo, invitationRepo, invitation
+}
+
+func TestAcceptRequiresPendingUser(t *testing.T) {
+	uc, userRepo, _, invitation := inviteForTest(t)
+	token := uc.signToken(invitation.ID, invitation.Nonce, invitation.ExpiresAt)
+
+	// O admin ativou e depois suspendeu o usuário sem passar pelo convite.
+	userRepo.users[invitation.UserID].Status = models.UserStatusSuspended
+
+	if _, err := uc.Accept(token, "a-new-password"); !errors.Is(err, repositories.ErrUserNotPending) {
+		t.Fatalf("Accept: err = %v, want ErrUserNotPending", err)
+	}
+	if user := userRepo.users[invitation.UserID]; user.Status != models.UserStatusSuspended || user.Password != "" {
+		t.Errorf("user = %+v, want untouched", user)
+	}
+	if len(userRepo.outbox.events) != 0 {
+		t.Errorf("events = %v, want none", userRepo.outbox.types())
+	}
+}
+
+func TestAcceptFailsWhenUserChangesConcurrently(t *testing.T) {
+	uc, userRepo, invitationRepo, invitation := inviteForTest(t)
+	token := uc.signToken(invitation.ID, invitation.Nonce, invitation.ExpiresAt)
+
+	stale, _ := invitationRepo.FindByID(invitation.ID)
+	uc.repo = &staleInvitationRepository{fakeInvitationRepository: invitationRepo, stale: stale}
+	userRepo.users[invitation.UserID].Status = models.UserStatusSuspended
+
+	if _, err := uc.Accept(token, "a-new-password"); !errors.Is(err, repositories.ErrUserNotPending) {
+		t.Fatalf("Accept: err = %v, want ErrUserNotPending", err)
+	}
+	if userRepo.users[invitation.UserID].Status != models.UserStatusSuspended {
+		t.Error("suspended user was activated")
+	}
+	if len(userRepo.outbox.events) != 0 {
+		t.Errorf("events = %v, want none", userRepo.outbox.types())
+	}
+}
+
+func TestRevokeRejectsUserNoLongerPending(t *testing.T) {
+	uc, userRepo, invitationRepo, invitation := inviteForTest(t)
+	userRepo.users[invitation.UserID].Status = models.UserStatusActive
+
+	if err := uc.Revoke(invitation.ID); !errors.Is(err, repositories.ErrUserNotPending) || err.Error() != "user is no longer pending" {
+		t.Fatalf("Revoke: err = %v, want ErrUserNotPending", err)
+	}
+	if _, ok := userRepo.users[invitation.UserID]; !ok {
+		t.Error("active user was deleted")
+	}
+	if _, ok := invitationRepo.invitations[invitation.ID]; !ok {
+		t.Error("invitation was deleted")
+	}
+}
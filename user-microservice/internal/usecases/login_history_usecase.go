@@ -0,0 +1,60 @@
+package usecases
+
+import (
+	"errors"
+	"log"
+	"login-api/internal/repositories"
+	"login-api/models"
+	"time"
+)
+
+type LoginHistoryUseCase struct {
+	repo     repositories.LoginAttemptRepository
+	userRepo repositories.UserRepository
+}
+
+func NewLoginHistoryUseCase(repo repositories.LoginAttemptRepository, userRepo repositories.UserRepository) *LoginHistoryUseCase {
+	return &LoginHistoryUseCase{repo: repo, userRepo: userRepo}
+}
+
+// RecordLogin grava a tentativa e, no sucesso, atualiza last_login_at. Falhas aqui não
+// impedem o login, apenas são registradas no log.
+func (uc *LoginHistoryUseCase) RecordLogin(attempt *models.LoginAttempt) {
+	if attempt.UserID == nil {
+		if user, err := uc.userRepo.FindByEmail(attempt.Email); err == nil {
+			attempt.UserID = &user.ID
+		}
+	}
+	attempt.CreatedAt = time.Now()
+
+	if err := uc.repo.Create(attempt); err != nil {
+		log.Printf("Failed to record login attempt for %s: %v", attempt.Email, err)
+		return
+	}
+	if attempt.Success && attempt.UserID != nil {
+		err := uc.userRepo.UpdateFields(*attempt.UserID, map[string]interface{}{"last_login_at": attempt.CreatedAt})
+		if err != nil {
+			log.Printf("Failed to update last login of user %d: %v", *attempt.UserID, err)
+		}
+	}
+}
+
+func (uc *LoginHistoryUseCase) GetByUser(userID uint64, page, pageSize int) (*models.LoginAttemptPage, error) {
+	if _, err := uc.userRepo.FindByID(userID); err != nil {
+		return nil, errors.New("user not found")
+	}
+	return uc.repo.FindByUser(userID, page, pageSize)
+}
+
+func (uc *LoginHistoryUseCase) GetByEmail(email string, page, pageSize int) (*models.LoginAttemptPage, error) {
+	user, err := uc.userRepo.FindByEmail(email)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	return uc.repo.FindByUser(user.ID, page, pageSize)
+}
+
+// Prune remove as tentativas anteriores a `before`.
+func (uc *LoginHistoryUseCase) Prune(before time.Time) (int64, error) {
+	return uc.repo.DeleteBefore(before)
+}
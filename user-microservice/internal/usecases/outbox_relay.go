@@ -0,0 +1,72 @@
+package usecases
+
+import (
+	"log"
+	"login-api/internal/repositories"
+	"login-api/models"
+	"shared/outbox"
+	"time"
+)
+
+const maxOutboxBackoff = 5 * time.Minute
+
+// EventPublisher entrega um evento ao broker e só retorna nil depois da confirmação.
+type EventPublisher interface {
+	Publish(routingKey string, payload []byte) error
+}
+
+// EventSource é o atributo source do CloudEvents dos eventos deste serviço.
+const EventSource = "/user-microservice"
+
+// publish grava o evento, já no envelope CloudEvents, na outbox. Para que ele só exista se a
+// mudança de estado for gravada, use a outbox do repositório da mesma transação; o OutboxRelay
+// faz a entrega. O tipo do evento também é a routing key.
+func publish(writer repositories.OutboxRepository, eventType string, data interface{}) error {
+	return outbox.Publish(writer, EventSource, eventType, data)
+}
+
+type OutboxRelay struct {
+	repo      repositories.OutboxRepository
+	publisher EventPublisher
+}
+
+func NewOutboxRelay(repo repositories.OutboxRepository, publisher EventPublisher) *OutboxRelay {
+	return &OutboxRelay{repo: repo, publisher: publisher}
+}
+
+// Relay publica até `limit` eventos pendentes em ordem e os marca como enviados. No primeiro
+// erro o evento é reagendado com backoff exponencial e o lote para, já que o broker
+// provavelmente está fora; a entrega é at-least-once.
+func (r *OutboxRelay) Relay(now time.Time, limit int) (int, error) {
+	sent := 0
+	err := r.repo.ProcessDue(now, limit, func(repo repositories.OutboxRepository, events []models.OutboxEvent) error {
+		for _, event := range events {
+			if err := r.publisher.Publish(event.RoutingKey, []byte(event.Payload)); err != nil {
+				log.Printf("Failed to publish outbox event %d (%s): %v", event.ID, event.RoutingKey, err)
+				return repo.MarkFailed(event.ID, err.Error(), time.Now().Add(outboxBackoff(event.Attempts)))
+			}
+			if err := repo.MarkSent(event.ID, time.Now()); err != nil {
+				return err
+			}
+			sent++
+		}
+		return nil
+	})
+	return sent, err
+}
+
+// Prune remove os eventos já entregues antes de `before`.
+func (r *OutboxRelay) Prune(before time.Time) (int64, error) {
+	return r.repo.DeleteSentBefore(before)
+}
+
+func outboxBackoff(attempts int) time.Duration {
+	if attempts > 8 {
+		return maxOutboxBackoff
+	}
+	backoff := time.Second << attempts
+	if backoff > maxOutboxBackoff {
+		return maxOutboxBackoff
+	}
+	return backoff
+}
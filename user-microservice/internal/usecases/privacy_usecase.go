@@ -0,0 +1,184 @@
+package usecases
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"events"
+	"io"
+	"login-api/internal/dto"
+	"login-api/internal/repositories"
+	"login-api/models"
+	"strconv"
+	"time"
+)
+
+// PrivacyUseCase atende os pedidos de acesso e de eliminação de dados (LGPD/GDPR).
+type PrivacyUseCase struct {
+	userRepo      repositories.UserRepository
+	groupRepo     repositories.GroupRepository
+	tokens        repositories.TokenRepository
+	logins        repositories.LoginAttemptRepository
+	userUseCase   *UserUseCase
+	avatarUseCase *AvatarUseCase
+}
+
+func NewPrivacyUseCase(userRepo repositories.UserRepository, groupRepo repositories.GroupRepository, tokens repositories.TokenRepository, logins repositories.LoginAttemptRepository, userUseCase *UserUseCase, avatarUseCase *AvatarUseCase) *PrivacyUseCase {
+	return &PrivacyUseCase{
+		userRepo:      userRepo,
+		groupRepo:     groupRepo,
+		tokens:        tokens,
+		logins:        logins,
+		userUseCase:   userUseCase,
+		avatarUseCase: avatarUseCase,
+	}
+}
+
+// Export grava em w um zip com tudo o que o serviço guarda sobre o usuário: perfil,
+// papéis (diretos e efetivos), grupos, sessões encerradas, histórico de login e avatar.
+func (uc *PrivacyUseCase) Export(id uint64, w io.Writer) error {
+	user, err := uc.findUser(id)
+	if err != nil {
+		return err
+	}
+
+	assignments, err := uc.userRepo.FindAssignmentsByUser(id)
+	if err != nil {
+		return err
+	}
+	effectiveRoles, err := uc.userUseCase.EffectiveRoles(id)
+	if err != nil {
+		effectiveRoles = []models.EffectiveRole{}
+	}
+	groups, err := uc.groupRepo.FindByMember(id)
+	if err != nil {
+		return err
+	}
+	sessions, err := uc.tokens.FindByUsername(user.Email)
+	if err != nil {
+		return err
+	}
+	logins, err := uc.logins.FindByUser(id, 0, 0)
+	if err != nil {
+		return err
+	}
+
+	archive := zip.NewWriter(w)
+	files := []struct {
+		name    string
+		content interface{}
+	}{
+		// O perfil sai pelo mesmo DTO da API, que não tem a senha.
+		{"profile.json", dto.UserWithRoles{User: dto.NewUser(user), Roles: dto.NewRoles(user.Roles)}},
+		{"roles.json", map[string]interface{}{"assignments": assignments, "effective": effectiveRoles}},
+		{"groups.json", groups},
+		{"sessions.json", sessions},
+		{"logins.json", logins.Attempts},
+	}
+	for _, file := range files {
+		if err := writeJSONFile(archive, file.name, file.content); err != nil {
+			return err
+		}
+	}
+
+	if user.AvatarUpdatedAt != nil {
+		if err := uc.copyAvatar(archive, user.ID); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// Erase anonimiza o usuário em vez de apagá-lo, para manter as referências por id,
+// e publica user.erased para os outros serviços limparem os próprios dados.
+func (uc *PrivacyUseCase) Erase(id uint64, erasedBy string) error {
+	user, err := uc.findUser(id)
+	if err != nil {
+		return err
+	}
+	if user.ErasedAt != nil {
+		return errors.New("user already erased")
+	}
+
+	now := time.Now()
+	err = uc.userRepo.Transaction(func(repo repositories.UserRepository) error {
+		err := repo.Erase(id, map[string]interface{}{
+			"name":              "Erased user",
+			"email":             "erased-" + strconv.FormatUint(id, 10) + "@erased.invalid",
+			"password":          "",
+			"service_account":   false,
+			"status":            models.UserStatusDeactivated,
+			"status_reason":     "erased",
+			"status_changed_at": now,
+			"avatar_updated_at": nil,
+			"erased_at":         now,
+		})
+		if err != nil {
+			return err
+		}
+		// Os eventos anteriores guardam o email e o nome; saem antes de gravar o user.erased.
+		if _, err := repo.Outbox().DeleteByUser(id, user.Email); err != nil {
+			return err
+		}
+		return publish(repo.Outbox(), events.UserErasedType, events.UserErased{
+			ID:        user.ID,
+			EmailHash: events.HashEmail(user.Email),
+			ErasedBy:  erasedBy,
+			ErasedAt:  now,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	// O consumer no Kong é localizado pelo id, então pode ser removido depois da anonimização.
+	uc.userUseCase.deprovision(user)
+	if err := uc.avatarUseCase.deleteFiles(id); err != nil {
+		return err
+	}
+	// Tokens de um email que não existe mais já são recusados, então as revogações podem sair.
+	if err := uc.tokens.DeleteByUsername(user.Email); err != nil {
+		return err
+	}
+	return uc.logins.DeleteByUser(id, user.Email)
+}
+
+// findUser também encontra usuários em soft delete, que continuam sujeitos aos pedidos.
+func (uc *PrivacyUseCase) findUser(id uint64) (*models.User, error) {
+	user, err := uc.userRepo.GetUserWithRoles(id)
+	if err == nil {
+		return user, nil
+	}
+	user, err = uc.userRepo.FindDeletedByID(id)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	return user, nil
+}
+
+func (uc *PrivacyUseCase) copyAvatar(archive *zip.Writer, userID uint64) error {
+	body, _, err := uc.avatarUseCase.storage.Get(avatarKey(userID, DefaultAvatarSize))
+	if err != nil {
+		// Avatar ausente no storage não impede a exportação do resto.
+		return nil
+	}
+	defer body.Close()
+
+	file, err := archive.Create("avatar.png")
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(file, body)
+	return err
+}
+
+func writeJSONFile(archive *zip.Writer, name string, content interface{}) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(content)
+}
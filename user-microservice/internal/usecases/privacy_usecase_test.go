@@ -0,0 +1,119 @@
+package usecases
+
+import (
+	"archive/zip"
+	"bytes"
+	"events"
+	"io"
+	"login-api/internal/repositories"
+	"login-api/internal/storage"
+	"login-api/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+type privacyUserRepository struct {
+	*fakeUserRepository
+}
+
+func (r *privacyUserRepository) FindAssignmentsByUser(userID uint64) ([]models.RoleAssignment, error) {
+	return nil, nil
+}
+
+func (r *privacyUserRepository) Transaction(fn func(repo repositories.UserRepository) error) error {
+	return fn(r)
+}
+
+func (r *privacyUserRepository) Erase(id uint64, fields map[string]interface{}) error {
+	r.users[id].Email = fields["email"].(string)
+	return nil
+}
+
+func (r *privacyUserRepository) FindRoleSources(userID uint64, at time.Time) ([]models.RoleSource, error) {
+	return nil, nil
+}
+
+type emptyGroupRepository struct{ repositories.GroupRepository }
+
+func (emptyGroupRepository) FindByMember(userID uint64) ([]models.Group, error) { return nil, nil }
+
+type emptyTokenRepository struct{ repositories.TokenRepository }
+
+func (emptyTokenRepository) FindByUsername(username string) ([]models.RevokedToken, error) {
+	return nil, nil
+}
+
+func (emptyTokenRepository) DeleteByUsername(username string) error { return nil }
+
+type emptyLoginAttemptRepository struct {
+	repositories.LoginAttemptRepository
+}
+
+func (emptyLoginAttemptRepository) FindByUser(userID uint64, page, pageSize int) (*models.LoginAttemptPage, error) {
+	return &models.LoginAttemptPage{}, nil
+}
+
+func (emptyLoginAttemptRepository) DeleteByUser(userID uint64, email string) error { return nil }
+
+func TestPrivacyExportOmitsPassword(t *testing.T) {
+	users := newFakeUserRepository()
+	users.users[7] = &models.User{ID: 7, Name: "Ana", Email: "ana@example.com", Password: "hunter2-secret", Roles: []models.Role{{ID: 1, Name: "Admin"}}}
+	repo := &privacyUserRepository{users}
+	privacy := NewPrivacyUseCase(repo, emptyGroupRepository{}, emptyTokenRepository{}, emptyLoginAttemptRepository{}, NewUserUseCase(repo, nil), nil)
+
+	var archive bytes.Buffer
+	if err := privacy.Export(7, &archive); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(archive.Bytes()), int64(archive.Len()))
+	if err != nil {
+		t.Fatalf("zip: %v", err)
+	}
+	for _, file := range reader.File {
+		f, _ := file.Open()
+		content, _ := io.ReadAll(f)
+		f.Close()
+		if strings.Contains(string(content), "hunter2-secret") || strings.Contains(strings.ToLower(string(content)), `"password"`) {
+			t.Errorf("%s leaks the password: %s", file.Name, content)
+		}
+		if file.Name == "profile.json" && !strings.Contains(string(content), "ana@example.com") {
+			t.Errorf("profile.json = %s", content)
+		}
+	}
+}
+
+func TestEraseEventCarriesOnlyTheEmailHash(t *testing.T) {
+	users := newFakeUserRepository()
+	users.users[7] = &models.User{ID: 7, Name: "Ana", Email: "Ana@Example.com"}
+	repo := &privacyUserRepository{users}
+	avatars := NewAvatarUseCase(repo, storage.NewLocalStorage(t.TempDir()))
+	privacy := NewPrivacyUseCase(repo, emptyGroupRepository{}, emptyTokenRepository{}, emptyLoginAttemptRepository{}, NewUserUseCase(repo, nil), avatars)
+
+	// Eventos anteriores, já entregues, ainda na retenção da outbox.
+	publish(users.outbox, events.UserCreatedType, events.UserCreated{ID: 7, Name: "Ana", Email: "Ana@Example.com"})
+	publish(users.outbox, events.UserRoleAddedType, events.UserRoleAdded{ID: 7, Email: "Ana@Example.com", RoleID: 1})
+	publish(users.outbox, events.UserCreatedType, events.UserCreated{ID: 8, Name: "Bia", Email: "bia@example.com"})
+
+	if err := privacy.Erase(7, "dpo@example.com"); err != nil {
+		t.Fatalf("Erase: %v", err)
+	}
+
+	if types := users.outbox.types(); len(types) != 2 || types[0] != events.UserCreatedType || types[1] != events.UserErasedType {
+		t.Fatalf("outbox = %v, want only the other user's event and user.erased", types)
+	}
+	// O payload fica na outbox depois da entrega, então não pode ter o email.
+	for _, event := range users.outbox.events {
+		if payload := strings.ToLower(event.Payload); strings.Contains(payload, "ana@example.com") || strings.Contains(payload, `"ana"`) {
+			t.Errorf("%s payload keeps the user's data: %s", event.RoutingKey, payload)
+		}
+	}
+	var event events.UserErased
+	if err := users.outbox.data(events.UserErasedType, &event); err != nil {
+		t.Fatalf("user.erased: %v", err)
+	}
+	if event.ID != 7 || event.EmailHash != events.HashEmail("ana@example.com") {
+		t.Errorf("event = %+v", event)
+	}
+}
@@ -0,0 +1,62 @@
+package usecases
+
+import (
+	"events"
+	"login-api/internal/repositories"
+	"login-api/models"
+	"strconv"
+	"time"
+)
+
+// ExpiringRoles lista as atribuições que vencem dentro do período informado.
+func (uc *UserUseCase) ExpiringRoles(within time.Duration) ([]models.RoleAssignment, error) {
+	now := time.Now()
+	return uc.repo.FindAssignmentsExpiring(now, now.Add(within))
+}
+
+// ExpireRoles remove as atribuições vencidas até `now` e publica user.role_expired para cada uma.
+func (uc *UserUseCase) ExpireRoles(now time.Time) (int, error) {
+	assignments, err := uc.repo.FindAssignmentsExpiring(time.Time{}, now)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	affected := make(map[uint64]bool)
+	for _, assignment := range assignments {
+		err := uc.repo.Transaction(func(repo repositories.UserRepository) error {
+			if err := repo.RemoveRole(assignment.UserID, strconv.FormatUint(assignment.RoleID, 10)); err != nil {
+				return err
+			}
+			return publish(repo.Outbox(), events.UserRoleExpiredType, events.UserRoleExpired{
+				ID:         assignment.UserID,
+				Email:      assignment.Email,
+				RoleID:     assignment.RoleID,
+				RoleName:   assignment.RoleName,
+				ValidUntil: *assignment.ValidUntil,
+			})
+		})
+		if err != nil {
+			return expired, err
+		}
+		expired++
+		affected[assignment.UserID] = true
+	}
+
+	for userID := range affected {
+		uc.provision(userID)
+	}
+	return expired, nil
+}
+
+// ActivateRoles atualiza o gateway dos usuários com atribuições que começaram a valer em (from, to].
+func (uc *UserUseCase) ActivateRoles(from, to time.Time) error {
+	userIDs, err := uc.repo.FindUsersWithRolesStarting(from, to)
+	if err != nil {
+		return err
+	}
+	for _, userID := range userIDs {
+		uc.provision(userID)
+	}
+	return nil
+}
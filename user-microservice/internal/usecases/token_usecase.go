@@ -0,0 +1,138 @@
+package usecases
+
+import (
+	"errors"
+	"login-api/internal/repositories"
+	middleware "login-api/middleware"
+	"login-api/models"
+	"strconv"
+	"time"
+)
+
+// Os tokens são emitidos pelo próprio user-microservice no /login.
+const TokenClientID = "user-microservice"
+
+// TokenDuration é a validade dos tokens emitidos no login e no refresh.
+const TokenDuration = 12 * time.Hour
+
+var ErrTokenRevoked = errors.New("token has been revoked")
+
+type TokenUseCase struct {
+	auth     middleware.Auth
+	tokens   repositories.TokenRepository
+	userRepo repositories.UserRepository
+}
+
+func NewTokenUseCase(auth middleware.Auth, tokens repositories.TokenRepository, userRepo repositories.UserRepository) *TokenUseCase {
+	return &TokenUseCase{auth: auth, tokens: tokens, userRepo: userRepo}
+}
+
+// Introspect considera a assinatura, a expiração, a lista de revogação e se o usuário ainda existe.
+// Qualquer falha resulta apenas em {"active": false}, como pede a RFC 7662.
+func (uc *TokenUseCase) Introspect(token string) *models.TokenIntrospection {
+	inactive := &models.TokenIntrospection{Active: false}
+
+	payload, err := uc.auth.VerifyToken(token)
+	if err != nil {
+		return inactive
+	}
+	if err := uc.CheckActive(payload); err != nil {
+		return inactive
+	}
+
+	user, err := uc.userRepo.FindByEmail(payload.Username)
+	if err != nil {
+		return inactive
+	}
+
+	return &models.TokenIntrospection{
+		Active:    true,
+		Sub:       strconv.FormatUint(user.ID, 10),
+		Username:  payload.Username,
+		Roles:     payload.Roles,
+		Exp:       payload.ExpiredAt.Unix(),
+		Iat:       payload.IssuedAt.Unix(),
+		Jti:       payload.ID.String(),
+		ClientID:  TokenClientID,
+		TokenType: "Bearer",
+	}
+}
+
+// CheckActive verifica a revogação de um token cuja assinatura já foi validada.
+// Tokens de usuários removidos (soft delete) ou que não estão ativos também deixam de valer imediatamente.
+func (uc *TokenUseCase) CheckActive(payload *middleware.Payload) error {
+	revoked, err := uc.tokens.IsRevoked(payload.ID.String())
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return ErrTokenRevoked
+	}
+	return uc.CheckUser(payload.Username)
+}
+
+// CheckUser recusa identidades de usuários removidos ou que não estão ativos; é o que resta
+// verificar quando o gateway autenticou a requisição sem um JWT (ex.: key-auth).
+func (uc *TokenUseCase) CheckUser(username string) error {
+	user, err := uc.userRepo.FindByEmail(username)
+	if err != nil || !user.IsActive() {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+// Revoke invalida um token antes da expiração (logout).
+func (uc *TokenUseCase) Revoke(token string) error {
+	payload, err := uc.auth.VerifyToken(token)
+	if err != nil {
+		return err
+	}
+
+	err = uc.tokens.Revoke(&models.RevokedToken{
+		TokenID:   payload.ID.String(),
+		Username:  payload.Username,
+		ExpiresAt: payload.ExpiredAt,
+		RevokedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Depois de expirado o token já é recusado pela assinatura, então a entrada pode sair.
+	return uc.tokens.DeleteExpired(time.Now())
+}
+
+// Refresh troca um token ainda válido por um novo, com os papéis vigentes no momento,
+// e revoga o anterior. Atribuições vencidas deixam de aparecer no token novo.
+func (uc *TokenUseCase) Refresh(token string) (*models.TokenRefresh, error) {
+	payload, err := uc.auth.VerifyToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.CheckActive(payload); err != nil {
+		return nil, err
+	}
+
+	user, err := uc.userRepo.FindByEmail(payload.Username)
+	if err != nil {
+		return nil, ErrTokenRevoked
+	}
+	roles, err := uc.userRepo.FindActiveRoles(user.ID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	refreshed := &models.TokenRefresh{UserID: user.ID, Roles: make([]string, len(roles))}
+	for i, role := range roles {
+		refreshed.Roles[i] = role.Name
+	}
+	refreshed.Token, err = uc.auth.CreateTokenWithRoles(user.Email, refreshed.Roles, TokenDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.Revoke(token); err != nil {
+		return nil, err
+	}
+	return refreshed, nil
+}
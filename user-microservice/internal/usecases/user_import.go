@@ -0,0 +1,230 @@
+package usecases
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"login-api/internal/repositories"
+	"login-api/models"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	ErrUnsupportedImportFormat = errors.New("unsupported import format")
+	ErrImportHasErrors         = errors.New("import has invalid rows")
+)
+
+var importValidator = validator.New()
+
+// importedStatusReason marca os usuários pending criados pela importação; ao aceitar o
+// convite o user.created sai com origin import.
+const importedStatusReason = "imported"
+
+// ParseUserImport lê um CSV (cabeçalho com name, email e roles; papéis separados por ";")
+// ou um array JSON de {"name", "email", "roles": []}. Line é a linha no arquivo original.
+func ParseUserImport(data []byte, format string) ([]models.UserImportRow, error) {
+	switch format {
+	case "csv":
+		return parseUserImportCSV(data)
+	case "json":
+		return parseUserImportJSON(data)
+	}
+	return nil, ErrUnsupportedImportFormat
+}
+
+func parseUserImportCSV(data []byte) ([]models.UserImportRow, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %v", err)
+	}
+	columns := make(map[string]int)
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))] = i
+	}
+	for _, required := range []string{"name", "email"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV header must contain a %q column", required)
+		}
+	}
+
+	field := func(record []string, column string) string {
+		index, ok := columns[column]
+		if !ok || index >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[index])
+	}
+
+	var rows []models.UserImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %v", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		row := models.UserImportRow{
+			Line:  line,
+			Name:  field(record, "name"),
+			Email: field(record, "email"),
+		}
+		for _, role := range strings.FieldsFunc(field(record, "roles"), func(r rune) bool { return r == ';' || r == '|' }) {
+			if role = strings.TrimSpace(role); role != "" {
+				row.Roles = append(row.Roles, role)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func parseUserImportJSON(data []byte) ([]models.UserImportRow, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return nil, errors.New("JSON import must be an array of users")
+	}
+
+	var rows []models.UserImportRow
+	for decoder.More() {
+		offset := decoder.InputOffset()
+		var row models.UserImportRow
+		if err := decoder.Decode(&row); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %v", err)
+		}
+		// InputOffset aponta para antes do separador; a linha é a do início do objeto.
+		start := offset + int64(len(data[offset:])-len(bytes.TrimLeft(data[offset:], " \t\r\n,")))
+		row.Line = bytes.Count(data[:start], []byte("\n")) + 1
+		row.Name = strings.TrimSpace(row.Name)
+		row.Email = strings.TrimSpace(row.Email)
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// Import valida todas as linhas e, se nenhuma tiver erro e não for dry run, cria os usuários
+// em uma única transação. O arquivo não traz senhas, então cada usuário é criado como pending
+// com um convite (user.invited) e só passa a existir para os outros serviços, com user.created,
+// quando define a senha pelo link.
+func (uc *InvitationUseCase) Import(rows []models.UserImportRow, dryRun bool, invitedBy string) (*models.UserImportResult, error) {
+	result, users, err := uc.userUseCase.validateImport(rows)
+	if err != nil {
+		return nil, err
+	}
+	result.DryRun = dryRun
+
+	if dryRun {
+		return result, nil
+	}
+	if len(result.Errors) > 0 {
+		return result, ErrImportHasErrors
+	}
+
+	now := time.Now()
+	err = uc.repo.Transaction(func(repo repositories.InvitationRepository) error {
+		for _, user := range users {
+			roleIDs := make([]uint64, len(user.Roles))
+			for i, role := range user.Roles {
+				roleIDs[i] = role.ID
+			}
+			user.Roles = nil
+			user.RegisterDate = now
+			user.Status = models.UserStatusPending
+			user.StatusReason = importedStatusReason
+			user.StatusChangedAt = &now
+
+			invitation := &models.Invitation{
+				Email:     user.Email,
+				Name:      user.Name,
+				InvitedBy: invitedBy,
+			}
+			if err := uc.renew(invitation, now); err != nil {
+				return err
+			}
+			if err := repo.Create(invitation, user, roleIDs); err != nil {
+				return fmt.Errorf("%s: %v", user.Email, err)
+			}
+			if err := uc.send(repo.Outbox(), invitation); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Created = len(users)
+	return result, nil
+}
+
+// validateImport confere as linhas e monta os usuários com os papéis encontrados.
+func (uc *UserUseCase) validateImport(rows []models.UserImportRow) (*models.UserImportResult, []*models.User, error) {
+	result := &models.UserImportResult{Total: len(rows)}
+
+	var roleNames []string
+	for _, row := range rows {
+		roleNames = append(roleNames, row.Roles...)
+	}
+	rolesByName := make(map[string]models.Role)
+	if len(roleNames) > 0 {
+		roles, err := uc.repo.FindRolesByNames(roleNames)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, role := range roles {
+			rolesByName[role.Name] = role
+		}
+	}
+
+	seen := make(map[string]int)
+	var users []*models.User
+	for _, row := range rows {
+		var rowErrors []string
+		if row.Name == "" {
+			rowErrors = append(rowErrors, "name is required")
+		}
+		if row.Email == "" {
+			rowErrors = append(rowErrors, "email is required")
+		} else if importValidator.Var(row.Email, "email") != nil {
+			rowErrors = append(rowErrors, "invalid email format")
+		} else {
+			key := strings.ToLower(row.Email)
+			if line, duplicated := seen[key]; duplicated {
+				rowErrors = append(rowErrors, fmt.Sprintf("email duplicated on line %d", line))
+			} else if existingUser, _ := uc.repo.FindByEmailIgnoreCase(row.Email); existingUser != nil {
+				rowErrors = append(rowErrors, "user already registered")
+			}
+			seen[key] = row.Line
+		}
+
+		user := &models.User{Name: row.Name, Email: row.Email}
+		for _, name := range row.Roles {
+			role, ok := rolesByName[name]
+			if !ok {
+				rowErrors = append(rowErrors, fmt.Sprintf("role %q not found", name))
+				continue
+			}
+			user.Roles = append(user.Roles, role)
+		}
+
+		if len(rowErrors) > 0 {
+			result.Errors = append(result.Errors, models.UserImportError{Line: row.Line, Email: row.Email, Errors: rowErrors})
+			continue
+		}
+		users = append(users, user)
+	}
+	result.Valid = len(users)
+	return result, users, nil
+}
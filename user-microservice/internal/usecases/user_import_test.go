@@ -0,0 +1,112 @@
+package usecases
+
+import (
+	"errors"
+	"events"
+	"login-api/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newImportUseCase() (*InvitationUseCase, *fakeUserRepository, *fakeInvitationRepository) {
+	userRepo := newFakeUserRepository(models.Role{ID: 1, Name: "Admin"}, models.Role{ID: 3, Name: "Watcher"})
+	invitationRepo := newFakeInvitationRepository(userRepo)
+	config := InvitationConfig{Secret: "secret", AcceptURL: "http://localhost/invitations/{token}/accept", TTL: time.Hour}
+	return NewInvitationUseCase(invitationRepo, userRepo, NewUserUseCase(userRepo, nil), config), userRepo, invitationRepo
+}
+
+func TestImportCreatesPendingUsersWithInvitations(t *testing.T) {
+	uc, userRepo, invitationRepo := newImportUseCase()
+
+	rows := []models.UserImportRow{
+		{Line: 2, Name: "Ana", Email: "ana@example.com", Roles: []string{"Admin"}},
+		{Line: 3, Name: "Bruno", Email: "bruno@example.com"},
+	}
+	result, err := uc.Import(rows, false, "admin@example.com")
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if result.Created != 2 || result.Valid != 2 {
+		t.Errorf("result = %+v", result)
+	}
+
+	for _, user := range userRepo.users {
+		if user.Status != models.UserStatusPending || user.Password != "" {
+			t.Errorf("imported user %s: status %q, password %q; want pending without password", user.Email, user.Status, user.Password)
+		}
+	}
+	if len(invitationRepo.invitations) != 2 {
+		t.Errorf("invitations = %d, want 2", len(invitationRepo.invitations))
+	}
+	if types := userRepo.outbox.types(); !reflect.DeepEqual(types, []string{events.UserInvitedType, events.UserInvitedType}) {
+		t.Errorf("events = %v, want only user.invited", types)
+	}
+	admin, _ := userRepo.FindByEmail("ana@example.com")
+	if len(admin.Roles) != 1 || admin.Roles[0].ID != 1 {
+		t.Errorf("roles = %+v", admin.Roles)
+	}
+}
+
+func TestImportedUserAcceptsInvitation(t *testing.T) {
+	uc, userRepo, invitationRepo := newImportUseCase()
+
+	if _, err := uc.Import([]models.UserImportRow{{Line: 2, Name: "Ana", Email: "ana@example.com"}}, false, "admin@example.com"); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	invitation := invitationRepo.invitations[1]
+	token := uc.signToken(invitation.ID, invitation.Nonce, invitation.ExpiresAt)
+
+	user, err := uc.Accept(token, "a-new-password")
+	if err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+	if user.Status != models.UserStatusActive || user.Password != "a-new-password" {
+		t.Errorf("user = %+v", user)
+	}
+
+	var created events.UserCreated
+	if err := userRepo.outbox.data(events.UserCreatedType, &created); err != nil {
+		t.Fatalf("user.created: %v", err)
+	}
+	if created.Origin != events.OriginImport {
+		t.Errorf("origin = %q, want %q", created.Origin, events.OriginImport)
+	}
+}
+
+func TestImportDryRunAndInvalidRows(t *testing.T) {
+	uc, userRepo, _ := newImportUseCase()
+
+	rows := []models.UserImportRow{
+		{Line: 2, Name: "Ana", Email: "ana@example.com"},
+		{Line: 3, Name: "", Email: "not-an-email", Roles: []string{"Root"}},
+	}
+	result, err := uc.Import(rows, false, "admin@example.com")
+	if !errors.Is(err, ErrImportHasErrors) {
+		t.Fatalf("err = %v, want ErrImportHasErrors", err)
+	}
+	if len(result.Errors) != 1 || len(result.Errors[0].Errors) != 3 {
+		t.Errorf("errors = %+v", result.Errors)
+	}
+
+	result, err = uc.Import(rows[:1], true, "admin@example.com")
+	if err != nil || !result.DryRun || result.Valid != 1 || result.Created != 0 {
+		t.Errorf("dry run = %+v, %v", result, err)
+	}
+	if len(userRepo.users) != 0 {
+		t.Errorf("users were created: %d", len(userRepo.users))
+	}
+}
+
+func TestImportRejectsEmailRegisteredWithAnotherCase(t *testing.T) {
+	uc, userRepo, _ := newImportUseCase()
+	userRepo.users[1] = &models.User{ID: 1, Name: "Foo", Email: "foo@example.com"}
+
+	result, err := uc.Import([]models.UserImportRow{{Line: 2, Name: "Foo", Email: "Foo@Example.com"}}, true, "admin@example.com")
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if result.Valid != 0 || len(result.Errors) != 1 || result.Errors[0].Errors[0] != "user already registered" {
+		t.Errorf("errors = %+v", result.Errors)
+	}
+}
@@ -0,0 +1,90 @@
+package usecases
+
+import (
+	"errors"
+	"events"
+	"login-api/internal/repositories"
+	"login-api/models"
+	"strings"
+	"time"
+)
+
+// Transições permitidas entre os estados da conta.
+var userStatusTransitions = map[string][]string{
+	models.UserStatusPending:     {models.UserStatusActive, models.UserStatusDeactivated},
+	models.UserStatusActive:      {models.UserStatusSuspended, models.UserStatusLocked, models.UserStatusDeactivated},
+	models.UserStatusSuspended:   {models.UserStatusActive, models.UserStatusDeactivated},
+	models.UserStatusLocked:      {models.UserStatusActive, models.UserStatusDeactivated},
+	models.UserStatusDeactivated: {models.UserStatusActive},
+}
+
+func IsValidUserStatus(status string) bool {
+	_, ok := userStatusTransitions[status]
+	return ok
+}
+
+// ChangeStatus aplica uma transição de estado e publica user.status_changed.
+// Ao sair de active o consumer no Kong é removido e os tokens deixam de valer; ao voltar, é recriado.
+func (uc *UserUseCase) ChangeStatus(id uint64, status, reason, changedBy string) (*models.User, error) {
+	if !IsValidUserStatus(status) {
+		return nil, errors.New("invalid status")
+	}
+	// O binding só rejeita a string vazia; um motivo só com espaços também não vale.
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.New("reason is required")
+	}
+
+	user, err := uc.repo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	// Um usuário eliminado (LGPD/GDPR) não pode voltar a ser ativado.
+	if user.ErasedAt != nil {
+		return nil, errors.New("invalid status transition")
+	}
+
+	from := user.Status
+	if from == "" {
+		from = models.UserStatusActive
+	}
+	if !canTransition(from, status) {
+		return nil, errors.New("invalid status transition")
+	}
+
+	now := time.Now()
+	err = uc.repo.Transaction(func(repo repositories.UserRepository) error {
+		err := repo.UpdateFields(id, map[string]interface{}{
+			"status":            status,
+			"status_reason":     reason,
+			"status_changed_at": now,
+		})
+		if err != nil {
+			return err
+		}
+		return publish(repo.Outbox(), events.UserStatusChangedType, events.UserStatusChanged{
+			ID:        user.ID,
+			Name:      user.Name,
+			Email:     user.Email,
+			From:      from,
+			To:        status,
+			Reason:    reason,
+			ChangedBy: changedBy,
+			ChangedAt: now,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return uc.provisioned(id)
+}
+
+func canTransition(from, to string) bool {
+	for _, allowed := range userStatusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
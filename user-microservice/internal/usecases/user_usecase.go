@@ -1,24 +1,34 @@
 package usecases
 
 import (
-	"encoding/json"
+	"crypto/subtle"
 	"errors"
+	"events"
+	"log"
 	"login-api/internal/repositories"
 	"login-api/models"
+	"strconv"
 	"time"
-
-	"github.com/rabbitmq/amqp091-go"
 )
 
+var ErrInvalidPassword = errors.New("invalid current password")
+
+// ConsumerProvisioner sincroniza os usuários com o API Gateway.
+type ConsumerProvisioner interface {
+	// Provision devolve a chave de API criada para uma conta de serviço, ou "".
+	Provision(user *models.User) (string, error)
+	Deprovision(user *models.User) error
+}
+
 type UserUseCase struct {
-	repo     repositories.UserRepository
-	amqpChan *amqp091.Channel
+	repo        repositories.UserRepository
+	provisioner ConsumerProvisioner
 }
 
-func NewUserUseCase(repo repositories.UserRepository, amqpChan *amqp091.Channel) *UserUseCase {
+func NewUserUseCase(repo repositories.UserRepository, provisioner ConsumerProvisioner) *UserUseCase {
 	return &UserUseCase{
-		repo:     repo,
-		amqpChan: amqpChan,
+		repo:        repo,
+		provisioner: provisioner,
 	}
 }
 
@@ -26,6 +36,14 @@ func (uc *UserUseCase) GetAll() ([]models.User, error) {
 	return uc.repo.FindAll()
 }
 
+func (uc *UserUseCase) GetPage(query models.UserListQuery) (*models.UserPage, error) {
+	return uc.repo.FindPage(query)
+}
+
+func (uc *UserUseCase) Export(query models.UserListQuery, fn func(users []models.User) error) error {
+	return uc.repo.FindInBatches(query, 500, fn)
+}
+
 func (uc *UserUseCase) GetByID(id uint64) (*models.User, error) {
 	return uc.repo.GetUserWithRoles(id)
 }
@@ -37,69 +55,518 @@ func (uc *UserUseCase) Create(user *models.User) error {
 	}
 
 	user.RegisterDate = time.Now()
-	err := uc.repo.Create(user)
+	user.Status = models.UserStatusActive
+	err := uc.repo.Transaction(func(repo repositories.UserRepository) error {
+		if err := repo.Create(user); err != nil {
+			return err
+		}
+		return publish(repo.Outbox(), events.UserCreatedType, events.UserCreated{
+			ID:     user.ID,
+			Name:   user.Name,
+			Email:  user.Email,
+			Origin: events.OriginAdmin,
+		})
+	})
 	if err != nil {
 		return err
 	}
+	uc.provision(user.ID)
+	return nil
+}
 
-	event := models.UserCreatedEvent{
-		ID:    user.ID,
-		Name:  user.Name,
-		Email: user.Email,
+// Update substitui os campos editáveis se o usuário ainda estiver em `version` e devolve o registro atualizado.
+func (uc *UserUseCase) Update(id uint64, user *models.User, version uint64) (*models.User, error) {
+	existingUser, err := uc.repo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("user not found")
 	}
 
-	eventJSON, err := json.Marshal(event)
+	user.ID = id
+	user.Version = version
+	err = uc.repo.Transaction(func(repo repositories.UserRepository) error {
+		if err := repo.Update(user); err != nil {
+			return err
+		}
+		return publishUpdated(repo, existingUser)
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return uc.provisioned(id)
+}
 
-	err = uc.amqpChan.Publish(
-		"user_events",
-		"user.created",
-		false, // mandatory
-		false, // immediate
-		amqp091.Publishing{
-			ContentType: "application/json",
-			Body:        eventJSON,
-		},
-	)
+// Patch aplica somente as colunas recebidas (JSON Merge Patch já validado).
+func (uc *UserUseCase) Patch(id uint64, fields map[string]interface{}, version uint64) (*models.User, error) {
+	user, err := uc.repo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if version != user.Version {
+		return nil, repositories.ErrVersionConflict
+	}
+
+	if email, ok := fields["email"].(string); ok && email != user.Email {
+		existingUser, _ := uc.repo.FindByEmail(email)
+		if existingUser != nil {
+			return nil, errors.New("user already registered")
+		}
+	}
 
-	return err
+	if len(fields) > 0 {
+		err := uc.repo.Transaction(func(repo repositories.UserRepository) error {
+			if err := repo.UpdateVersioned(id, version, fields); err != nil {
+				return err
+			}
+			return publishUpdated(repo, user)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return uc.provisioned(id)
+	}
+	return uc.repo.GetUserWithRoles(id)
 }
 
-func (uc *UserUseCase) Update(id uint64, user *models.User) error {
-	_, err := uc.repo.FindByID(id)
+func (uc *UserUseCase) Delete(id, version uint64) error {
+	user, err := uc.repo.FindByID(id)
 	if err != nil {
 		return errors.New("user not found")
 	}
 
-	user.ID = id
-	return uc.repo.Update(user)
+	err = uc.repo.Transaction(func(repo repositories.UserRepository) error {
+		if err := repo.Delete(id, version); err != nil {
+			return err
+		}
+		return publish(repo.Outbox(), events.UserDeletedType, events.UserDeleted{
+			ID:        user.ID,
+			Email:     user.Email,
+			DeletedAt: time.Now(),
+		})
+	})
+	if err != nil {
+		return err
+	}
+	// O acesso é revogado na hora: o consumer sai do Kong e o Authenticate/introspecção
+	// recusam tokens de usuários removidos.
+	uc.deprovision(user)
+	return nil
+}
+
+func (uc *UserUseCase) Restore(id uint64) (*models.User, error) {
+	user, err := uc.repo.FindDeletedByID(id)
+	if err != nil || user.ErasedAt != nil {
+		return nil, errors.New("user not found")
+	}
+
+	existingUser, _ := uc.repo.FindByEmail(user.Email)
+	if existingUser != nil {
+		return nil, errors.New("user already registered")
+	}
+
+	err = uc.repo.Transaction(func(repo repositories.UserRepository) error {
+		if err := repo.Restore(id); err != nil {
+			return err
+		}
+		return publish(repo.Outbox(), events.UserRestoredType, events.UserRestored{
+			ID:    user.ID,
+			Email: user.Email,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return uc.provisioned(id)
 }
 
-func (uc *UserUseCase) Delete(id uint64) error {
-	_, err := uc.repo.FindByID(id)
+// PurgeDeleted remove definitivamente os usuários excluídos antes de `before`.
+func (uc *UserUseCase) PurgeDeleted(before time.Time) (int, error) {
+	users, err := uc.repo.FindDeletedBefore(before)
 	if err != nil {
-		return errors.New("user not found")
+		return 0, err
 	}
 
-	return uc.repo.Delete(id)
+	purged := 0
+	for _, user := range users {
+		err := uc.repo.Transaction(func(repo repositories.UserRepository) error {
+			if err := repo.Purge(user.ID); err != nil {
+				return err
+			}
+			return publish(repo.Outbox(), events.UserPurgedType, events.UserPurged{
+				ID:       user.ID,
+				PurgedAt: time.Now(),
+			})
+		})
+		if err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
 }
 
-func (uc *UserUseCase) AddRoleToUser(userID uint64, roleID string) error {
+func (uc *UserUseCase) AddRoleToUser(userID uint64, roleID string, grant models.RoleGrant) error {
 	user, err := uc.repo.FindByID(userID)
 	if err != nil {
 		return errors.New("user not found")
 	}
 
-	return uc.repo.AddRole(user.ID, roleID)
+	if grant.ValidUntil != nil {
+		if !grant.ValidUntil.After(time.Now()) || (grant.ValidFrom != nil && !grant.ValidUntil.After(*grant.ValidFrom)) {
+			return errors.New("invalid validity period")
+		}
+	}
+
+	role, err := uc.findRole(roleID)
+	if err != nil {
+		return err
+	}
+
+	err = uc.repo.Transaction(func(repo repositories.UserRepository) error {
+		if err := repo.AddRole(user.ID, roleID, grant); err != nil {
+			return err
+		}
+		return publish(repo.Outbox(), events.UserRoleAddedType, events.UserRoleAdded{
+			ID:         user.ID,
+			Email:      user.Email,
+			RoleID:     role.ID,
+			RoleName:   role.Name,
+			ValidFrom:  grant.ValidFrom,
+			ValidUntil: grant.ValidUntil,
+			GrantedBy:  grant.GrantedBy,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	uc.provision(user.ID)
+	return nil
 }
 
+// RemoveRoleFromUser remove a atribuição direta; se o usuário não tem o papel não há o que publicar.
 func (uc *UserUseCase) RemoveRoleFromUser(userID uint64, roleID string) error {
-	user, err := uc.repo.FindByID(userID)
+	user, err := uc.repo.GetUserWithRoles(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	var removed *models.Role
+	for i, role := range user.Roles {
+		if strconv.FormatUint(role.ID, 10) == roleID {
+			removed = &user.Roles[i]
+		}
+	}
+	if removed == nil {
+		return nil
+	}
+
+	err = uc.repo.Transaction(func(repo repositories.UserRepository) error {
+		if err := repo.RemoveRole(user.ID, roleID); err != nil {
+			return err
+		}
+		return publish(repo.Outbox(), events.UserRoleRemovedType, events.UserRoleRemoved{
+			ID:       user.ID,
+			Email:    user.Email,
+			RoleID:   removed.ID,
+			RoleName: removed.Name,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	uc.provision(user.ID)
+	return nil
+}
+
+// ChangePassword troca a senha do usuário autenticado depois de conferir a atual.
+func (uc *UserUseCase) ChangePassword(email, currentPassword, newPassword string) error {
+	user, err := uc.repo.FindByEmail(email)
 	if err != nil {
 		return errors.New("user not found")
 	}
+	if subtle.ConstantTimeCompare([]byte(user.Password), []byte(currentPassword)) != 1 {
+		return ErrInvalidPassword
+	}
+
+	return uc.repo.Transaction(func(repo repositories.UserRepository) error {
+		err := repo.UpdateVersioned(user.ID, user.Version, map[string]interface{}{
+			"password": newPassword,
+		})
+		if err != nil {
+			return err
+		}
+		return publish(repo.Outbox(), events.UserPasswordChangedType, events.UserPasswordChanged{
+			ID:        user.ID,
+			Email:     user.Email,
+			ChangedAt: time.Now(),
+		})
+	})
+}
+
+// UnknownRolesError lista os IDs de papéis que não existem.
+type UnknownRolesError struct {
+	IDs []uint64
+}
+
+func (e *UnknownRolesError) Error() string {
+	return "role not found"
+}
+
+func uniqueIDs(ids []uint64) []uint64 {
+	seen := make(map[uint64]bool)
+	var unique []uint64
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// checkRolesExist retorna *UnknownRolesError com os IDs que não existem.
+func checkRolesExist(repo repositories.UserRepository, roleIDs []uint64) error {
+	if len(roleIDs) == 0 {
+		return nil
+	}
+	roles, err := repo.FindRolesByIDs(roleIDs)
+	if err != nil {
+		return err
+	}
+	found := make(map[uint64]bool)
+	for _, role := range roles {
+		found[role.ID] = true
+	}
+	unknown := &UnknownRolesError{}
+	for _, id := range roleIDs {
+		if !found[id] {
+			unknown.IDs = append(unknown.IDs, id)
+		}
+	}
+	if len(unknown.IDs) > 0 {
+		return unknown
+	}
+	return nil
+}
+
+// ReplaceRoles troca o conjunto de papéis do usuário pelo informado, aplicando só a diferença
+// em uma transação, e publica um único user.roles_changed com o conjunto antes e depois.
+func (uc *UserUseCase) ReplaceRoles(userID uint64, roleIDs []uint64, grantedBy string) (*models.User, error) {
+	if _, err := uc.repo.FindByID(userID); err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	unique := uniqueIDs(roleIDs)
+	if err := checkRolesExist(uc.repo, unique); err != nil {
+		return nil, err
+	}
+	desired := make(map[uint64]bool)
+	for _, id := range unique {
+		desired[id] = true
+	}
+
+	var updated *models.User
+	err := uc.repo.Transaction(func(repo repositories.UserRepository) error {
+		// Os papéis atuais são lidos com a linha do usuário travada, para que uma atribuição
+		// concorrente não entre entre a leitura e a gravação da diferença.
+		user, err := repo.GetUserWithRolesForUpdate(userID)
+		if err != nil {
+			return errors.New("user not found")
+		}
+		current := make(map[uint64]bool)
+		for _, role := range user.Roles {
+			current[role.ID] = true
+			if !desired[role.ID] {
+				if err := repo.RemoveRole(user.ID, strconv.FormatUint(role.ID, 10)); err != nil {
+					return err
+				}
+			}
+		}
+		for _, id := range unique {
+			if !current[id] {
+				if err := repo.AddRole(user.ID, strconv.FormatUint(id, 10), models.RoleGrant{GrantedBy: grantedBy}); err != nil {
+					return err
+				}
+			}
+		}
 
-	return uc.repo.RemoveRole(user.ID, roleID)
+		updated, err = repo.GetUserWithRoles(user.ID)
+		if err != nil {
+			return err
+		}
+		return publish(repo.Outbox(), events.UserRolesChangedType, events.UserRolesChanged{
+			ID:     user.ID,
+			Email:  user.Email,
+			Before: eventRoles(user.Roles),
+			After:  eventRoles(updated.Roles),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	uc.provision(userID)
+	return updated, nil
+}
+
+// findRole aceita o id como veio na rota; ids inválidos são tratados como papel inexistente.
+func (uc *UserUseCase) findRole(roleID string) (*models.Role, error) {
+	id, err := strconv.ParseUint(roleID, 10, 64)
+	if err != nil {
+		return nil, errors.New("role not found")
+	}
+	roles, err := uc.repo.FindRolesByIDs([]uint64{id})
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) == 0 {
+		return nil, errors.New("role not found")
+	}
+	return &roles[0], nil
+}
+
+// publishUpdated relê o usuário na transação e publica user.updated com os campos alterados.
+// Uma atualização que não muda nada (ex.: PUT com os mesmos dados) não gera evento.
+func publishUpdated(repo repositories.UserRepository, before *models.User) error {
+	after, err := repo.FindByID(before.ID)
+	if err != nil {
+		return err
+	}
+
+	event := events.UserUpdated{
+		ID:      before.ID,
+		Before:  newUserSnapshot(before),
+		After:   newUserSnapshot(after),
+		Version: after.Version,
+	}
+	if event.Before.Name != event.After.Name {
+		event.Changed = append(event.Changed, "name")
+	}
+	if event.Before.Email != event.After.Email {
+		event.Changed = append(event.Changed, "email")
+	}
+	if event.Before.ServiceAccount != event.After.ServiceAccount {
+		event.Changed = append(event.Changed, "service_account")
+	}
+	if len(event.Changed) == 0 {
+		return nil
+	}
+	return publish(repo.Outbox(), events.UserUpdatedType, event)
+}
+
+func eventRoles(roles []models.Role) []events.Role {
+	result := []events.Role{}
+	for _, role := range roles {
+		result = append(result, events.Role{ID: role.ID, Name: role.Name})
+	}
+	return result
+}
+
+func newUserSnapshot(user *models.User) events.UserSnapshot {
+	return events.UserSnapshot{
+		Name:           user.Name,
+		Email:          user.Email,
+		ServiceAccount: user.ServiceAccount,
+	}
+}
+
+// EffectiveRoles explica os papéis vigentes do usuário, agrupando as origens de cada um.
+func (uc *UserUseCase) EffectiveRoles(userID uint64) ([]models.EffectiveRole, error) {
+	if _, err := uc.repo.FindByID(userID); err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	sources, err := uc.repo.FindRoleSources(userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	effective := []models.EffectiveRole{}
+	for _, source := range sources {
+		if len(effective) == 0 || effective[len(effective)-1].ID != source.RoleID {
+			effective = append(effective, models.EffectiveRole{ID: source.RoleID, Name: source.RoleName})
+		}
+		last := &effective[len(effective)-1]
+		last.Sources = append(last.Sources, source)
+	}
+	return effective, nil
+}
+
+// SyncGateway atualiza o consumer do usuário no Kong depois de mudanças feitas fora deste
+// serviço (ex.: membros alterados pelo role-microservice).
+func (uc *UserUseCase) SyncGateway(userID uint64) {
+	uc.provision(userID)
+}
+
+// Falhas no gateway não desfazem a operação: o comando cmd/kong-sync corrige a divergência.
+// Devolve a chave de API quando uma foi criada para a conta de serviço.
+func (uc *UserUseCase) provision(userID uint64) string {
+	if uc.provisioner == nil {
+		return ""
+	}
+	user, err := uc.repo.FindByID(userID)
+	if err == nil && !user.IsActive() {
+		// Contas que não estão ativas não têm consumer no Kong.
+		uc.deprovision(user)
+		return ""
+	}
+	if err == nil {
+		err = uc.loadGatewayRoles(user)
+	}
+	var apiKey string
+	if err == nil {
+		apiKey, err = uc.provisioner.Provision(user)
+	}
+	if err != nil {
+		log.Printf("Failed to provision Kong consumer for user %d: %v", userID, err)
+	}
+	return apiKey
+}
+
+// provisioned provisiona o usuário e o devolve com os papéis. A chave de API criada para uma
+// conta de serviço vai em APIKey: a resposta ao admin é a única vez em que ela aparece.
+func (uc *UserUseCase) provisioned(userID uint64) (*models.User, error) {
+	apiKey := uc.provision(userID)
+	user, err := uc.repo.GetUserWithRoles(userID)
+	if err != nil {
+		return nil, err
+	}
+	user.APIKey = apiKey
+	return user, nil
+}
+
+// GatewayUsers carrega os usuários como o provisionamento os vê, para o kong-sync: os ativos vêm com
+// os papéis vigentes (diretos e de grupos); os demais vêm sem papéis e devem ficar sem consumer.
+func (uc *UserUseCase) GatewayUsers() ([]models.User, error) {
+	users, err := uc.repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	for i := range users {
+		if !users[i].IsActive() {
+			users[i].Roles = nil
+			continue
+		}
+		if err := uc.loadGatewayRoles(&users[i]); err != nil {
+			return nil, err
+		}
+	}
+	return users, nil
+}
+
+// Os grupos de ACL no Kong seguem só os papéis vigentes.
+func (uc *UserUseCase) loadGatewayRoles(user *models.User) error {
+	roles, err := uc.repo.FindActiveRoles(user.ID, time.Now())
+	if err != nil {
+		return err
+	}
+	user.Roles = roles
+	return nil
+}
+
+func (uc *UserUseCase) deprovision(user *models.User) {
+	if uc.provisioner == nil {
+		return
+	}
+	if err := uc.provisioner.Deprovision(user); err != nil {
+		log.Printf("Failed to deprovision Kong consumer for user %d: %v", user.ID, err)
+	}
 }
@@ -0,0 +1,179 @@
+package usecases
+
+import (
+	"errors"
+	"events"
+	"login-api/internal/repositories"
+	"login-api/models"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestGatewayUsersUsesActiveRolesAndStatus(t *testing.T) {
+	repo := newFakeUserRepository()
+	repo.users[1] = &models.User{ID: 1, Email: "ana@example.com", Roles: []models.Role{{ID: 1, Name: "Admin"}, {ID: 2, Name: "Expired"}}}
+	repo.users[2] = &models.User{ID: 2, Email: "suspended@example.com", Status: models.UserStatusSuspended, Roles: []models.Role{{ID: 1, Name: "Admin"}}}
+	// Admin direto e Watcher herdado de um grupo; Expired já venceu.
+	repo.activeRoles = map[uint64][]models.Role{1: {{ID: 1, Name: "Admin"}, {ID: 3, Name: "Watcher"}}}
+
+	users, err := NewUserUseCase(repo, nil).GatewayUsers()
+	if err != nil {
+		t.Fatalf("GatewayUsers: %v", err)
+	}
+	if len(users) != 2 {
+		t.Fatalf("users = %+v", users)
+	}
+	if !reflect.DeepEqual(users[0].Roles, repo.activeRoles[1]) {
+		t.Errorf("active user roles = %+v", users[0].Roles)
+	}
+	if users[1].IsActive() || len(users[1].Roles) != 0 {
+		t.Errorf("suspended user = %+v, want no roles", users[1])
+	}
+}
+
+// fakeProvisioner cria a chave de API na primeira vez que provisiona cada conta de serviço.
+type fakeProvisioner struct {
+	keys map[uint64]string
+}
+
+func (p *fakeProvisioner) Provision(user *models.User) (string, error) {
+	if !user.ServiceAccount || p.keys[user.ID] != "" {
+		return "", nil
+	}
+	p.keys[user.ID] = "key-" + strconv.Itoa(len(p.keys)+1)
+	return p.keys[user.ID], nil
+}
+
+func (p *fakeProvisioner) Deprovision(user *models.User) error {
+	delete(p.keys, user.ID)
+	return nil
+}
+
+// statusUserRepository grava o status alterado por ChangeStatus.
+type statusUserRepository struct {
+	*fakeUserRepository
+}
+
+func (r statusUserRepository) UpdateFields(id uint64, fields map[string]interface{}) error {
+	r.users[id].Status = fields["status"].(string)
+	return nil
+}
+
+func (r statusUserRepository) Transaction(fn func(repo repositories.UserRepository) error) error {
+	return fn(r)
+}
+
+func TestChangeStatusReturnsTheNewAPIKeyOnce(t *testing.T) {
+	repo := newFakeUserRepository()
+	repo.users[9] = &models.User{ID: 9, Email: "ci@example.com", ServiceAccount: true, Status: models.UserStatusActive}
+	provisioner := &fakeProvisioner{keys: map[uint64]string{9: "key-1"}}
+	uc := NewUserUseCase(statusUserRepository{repo}, provisioner)
+
+	user, err := uc.ChangeStatus(9, models.UserStatusSuspended, "rotação", "admin@example.com")
+	if err != nil {
+		t.Fatalf("suspend: %v", err)
+	}
+	if user.APIKey != "" {
+		t.Errorf("suspended user came with API key %q", user.APIKey)
+	}
+
+	// Reativada, a conta ganha um consumer novo e a chave criada vem na resposta.
+	user, err = uc.ChangeStatus(9, models.UserStatusActive, "rotação concluída", "admin@example.com")
+	if err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	if user.APIKey == "" || user.APIKey != provisioner.keys[9] {
+		t.Errorf("APIKey = %q, want the created key %q", user.APIKey, provisioner.keys[9])
+	}
+	if user, _ := uc.GetByID(9); user.APIKey != "" {
+		t.Errorf("API key shown again: %q", user.APIKey)
+	}
+}
+
+// roleUserRepository grava as atribuições diretas em user.Roles e recusa uma atribuição
+// repetida, como a chave primária de user_roles.
+type roleUserRepository struct {
+	*fakeUserRepository
+	// beforeLock simula uma atribuição concorrente confirmada antes de a transação travar o usuário.
+	beforeLock func()
+}
+
+func (r roleUserRepository) FindRolesByIDs(ids []uint64) ([]models.Role, error) {
+	var roles []models.Role
+	for _, role := range r.roles {
+		for _, id := range ids {
+			if role.ID == id {
+				roles = append(roles, role)
+			}
+		}
+	}
+	return roles, nil
+}
+
+func (r roleUserRepository) GetUserWithRolesForUpdate(id uint64) (*models.User, error) {
+	if r.beforeLock != nil {
+		r.beforeLock()
+	}
+	return r.FindByID(id)
+}
+
+func (r roleUserRepository) AddRole(userID uint64, roleID string, grant models.RoleGrant) error {
+	user := r.users[userID]
+	for _, role := range user.Roles {
+		if strconv.FormatUint(role.ID, 10) == roleID {
+			return errors.New("duplicate key value violates unique constraint")
+		}
+	}
+	roles, _ := r.FindRolesByIDs([]uint64{mustParse(roleID)})
+	user.Roles = append(user.Roles, roles...)
+	return nil
+}
+
+func (r roleUserRepository) RemoveRole(userID uint64, roleID string) error {
+	user := r.users[userID]
+	var kept []models.Role
+	for _, role := range user.Roles {
+		if strconv.FormatUint(role.ID, 10) != roleID {
+			kept = append(kept, role)
+		}
+	}
+	user.Roles = kept
+	return nil
+}
+
+func (r roleUserRepository) Transaction(fn func(repo repositories.UserRepository) error) error {
+	return fn(r)
+}
+
+func mustParse(id string) uint64 {
+	parsed, _ := strconv.ParseUint(id, 10, 64)
+	return parsed
+}
+
+func TestReplaceRolesPublishesOnlyRolesChanged(t *testing.T) {
+	admin, modifier, watcher := models.Role{ID: 1, Name: "Admin"}, models.Role{ID: 2, Name: "Modifier"}, models.Role{ID: 3, Name: "Watcher"}
+	repo := newFakeUserRepository(admin, modifier, watcher)
+	repo.users[1] = &models.User{ID: 1, Email: "ana@example.com", Roles: []models.Role{admin, modifier}}
+	locking := roleUserRepository{fakeUserRepository: repo, beforeLock: func() {
+		repo.users[1].Roles = append(repo.users[1].Roles, watcher)
+	}}
+
+	user, err := NewUserUseCase(locking, nil).ReplaceRoles(1, []uint64{1, 3}, "admin@example.com")
+	if err != nil {
+		t.Fatalf("ReplaceRoles: %v", err)
+	}
+	if !reflect.DeepEqual(user.Roles, []models.Role{admin, watcher}) {
+		t.Errorf("roles = %+v", user.Roles)
+	}
+	if types := repo.outbox.types(); !reflect.DeepEqual(types, []string{events.UserRolesChangedType}) {
+		t.Errorf("events = %v, want only %s", types, events.UserRolesChangedType)
+	}
+	var changed events.UserRolesChanged
+	if err := repo.outbox.data(events.UserRolesChangedType, &changed); err != nil {
+		t.Fatal(err)
+	}
+	if len(changed.Before) != 3 || len(changed.After) != 2 {
+		t.Errorf("roles_changed = %+v", changed)
+	}
+}
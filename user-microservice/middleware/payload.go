@@ -19,6 +19,8 @@ type Payload struct {
 	Roles     []string  `json:"roles"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
+	// Exp é a claim registrada "exp" (RFC 7519), em segundos, verificada pelo plugin jwt do Kong.
+	Exp int64 `json:"exp"`
 }
 
 func NewPayload(username string, roles []string, duration time.Duration) (*Payload, error) {
@@ -27,12 +29,14 @@ func NewPayload(username string, roles []string, duration time.Duration) (*Paylo
 		return nil, err
 	}
 
+	now := time.Now()
 	payload := &Payload{
 		ID:        tokenID,
 		Username:  username,
 		Roles:     roles,
-		IssuedAt:  time.Now(),
-		ExpiredAt: time.Now().Add(duration),
+		IssuedAt:  now,
+		ExpiredAt: now.Add(duration),
+		Exp:       now.Add(duration).Unix(),
 	}
 	return payload, nil
 }
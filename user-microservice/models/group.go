@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Group concede seus papéis a todos os membros (ex.: um departamento).
+type Group struct {
+	ID          uint64 `gorm:"primaryKey;autoIncrement;type:integer"`
+	Name        string `json:"name" binding:"required" gorm:"uniqueIndex"`
+	Description string `json:"description"`
+	Roles       []Role `json:"roles" gorm:"many2many:group_roles;"`
+	Members     []User `json:"-" gorm:"many2many:user_groups;"`
+}
+
+type GroupRolesRequest struct {
+	RoleIDs []uint64 `json:"role_ids" binding:"required"`
+}
+
+// RoleSource indica de onde vem um papel efetivo: atribuição direta ou grupo.
+type RoleSource struct {
+	RoleID     uint64     `json:"-"`
+	RoleName   string     `json:"-"`
+	Type       string     `json:"type"`
+	GroupID    *uint64    `json:"group_id,omitempty"`
+	GroupName  string     `json:"group_name,omitempty"`
+	ValidUntil *time.Time `json:"valid_until,omitempty"`
+}
+
+// EffectiveRole é um papel vigente do usuário com todas as suas origens.
+type EffectiveRole struct {
+	ID      uint64       `json:"id"`
+	Name    string       `json:"name"`
+	Sources []RoleSource `json:"sources"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Invitation é o convite enviado por um admin. O usuário é criado como pending com os
+// papéis iniciais e só é ativado quando o convidado define a senha.
+type Invitation struct {
+	ID         uint64     `json:"id" gorm:"primaryKey;autoIncrement;type:integer"`
+	UserID     uint64     `json:"user_id" gorm:"index"`
+	User       User       `json:"-"`
+	Email      string     `json:"email" gorm:"index"`
+	Name       string     `json:"name"`
+	InvitedBy  string     `json:"invited_by"`
+	Nonce      string     `json:"-"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	SentAt     time.Time  `json:"sent_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type InvitationRequest struct {
+	Email   string   `json:"email" binding:"required,email"`
+	Name    string   `json:"name" binding:"required"`
+	RoleIDs []uint64 `json:"role_ids"`
+}
+
+type InvitationAcceptRequest struct {
+	Password string `json:"password" binding:"required,min=8"`
+}
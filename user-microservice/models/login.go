@@ -1,6 +1,6 @@
 package models
 
 type UserLogin struct {
-	Username string
-	Password string
+	Username string `binding:"required"`
+	Password string `binding:"required"`
 }
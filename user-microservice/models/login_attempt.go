@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// LoginAttempt registra cada tentativa de login, com sucesso ou não.
+// UserID fica vazio quando o email não pertence a nenhum usuário.
+type LoginAttempt struct {
+	ID            uint64    `json:"id" gorm:"primaryKey;autoIncrement;type:integer"`
+	UserID        *uint64   `json:"user_id,omitempty" gorm:"index"`
+	Email         string    `json:"email" gorm:"index"`
+	Success       bool      `json:"success"`
+	FailureReason string    `json:"failure_reason,omitempty"`
+	IP            string    `json:"ip"`
+	UserAgent     string    `json:"user_agent"`
+	CreatedAt     time.Time `json:"created_at" gorm:"index"`
+}
+
+type LoginAttemptPage struct {
+	Attempts []LoginAttempt
+	Total    int64
+}
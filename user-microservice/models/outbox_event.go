@@ -0,0 +1,7 @@
+package models
+
+import "shared/outbox"
+
+// OutboxEvent é um evento gravado na mesma transação da mudança de estado e entregue
+// ao RabbitMQ depois pelo relay. SentAt nulo indica que ainda não foi confirmado pelo broker.
+type OutboxEvent = outbox.Event
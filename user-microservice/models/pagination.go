@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// UserListQuery são os filtros, a ordenação e a paginação aceitos na listagem de usuários.
+// Quando Cursor está preenchido a paginação é por cursor e Page é ignorado.
+type UserListQuery struct {
+	Page           int
+	PageSize       int
+	Cursor         string
+	Name           string
+	Email          string
+	Role           string
+	Status         string
+	RegisteredFrom *time.Time
+	RegisteredTo   *time.Time
+	Sort           string
+	Desc           bool
+	IncludeDeleted bool
+}
+
+type UserPage struct {
+	Users      []User
+	Total      int64
+	NextCursor string
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// RoleGrant são os dados opcionais de uma atribuição de papel com prazo.
+type RoleGrant struct {
+	ValidFrom  *time.Time `json:"valid_from"`
+	ValidUntil *time.Time `json:"valid_until"`
+	Reason     string     `json:"reason"`
+	GrantedBy  string     `json:"-"`
+}
+
+// RoleAssignment é uma linha de user_roles com os nomes do usuário e do papel.
+type RoleAssignment struct {
+	UserID     uint64     `json:"user_id"`
+	Email      string     `json:"email"`
+	RoleID     uint64     `json:"role_id"`
+	RoleName   string     `json:"role_name"`
+	ValidFrom  *time.Time `json:"valid_from"`
+	ValidUntil *time.Time `json:"valid_until"`
+	GrantedBy  string     `json:"granted_by"`
+	Reason     string     `json:"reason"`
+}
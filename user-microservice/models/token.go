@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// RevokedToken guarda o jti de tokens revogados antes de expirarem (ex.: logout).
+type RevokedToken struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement;type:integer"`
+	TokenID   string    `json:"token_id" gorm:"uniqueIndex;not null"`
+	Username  string    `json:"username"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"index"`
+	RevokedAt time.Time `json:"revoked_at"`
+}
+
+// TokenIntrospection é a resposta do endpoint de introspecção (RFC 7662).
+type TokenIntrospection struct {
+	Active    bool     `json:"active"`
+	Sub       string   `json:"sub,omitempty"`
+	Username  string   `json:"username,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+	Exp       int64    `json:"exp,omitempty"`
+	Iat       int64    `json:"iat,omitempty"`
+	Jti       string   `json:"jti,omitempty"`
+	ClientID  string   `json:"client_id,omitempty"`
+	TokenType string   `json:"token_type,omitempty"`
+}
+
+// TokenRefresh tem o mesmo formato da resposta do /login.
+type TokenRefresh struct {
+	Token  string   `json:"token"`
+	UserID uint64   `json:"user_id"`
+	Roles  []string `json:"roles"`
+}
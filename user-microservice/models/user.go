@@ -2,15 +2,28 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type User struct {
-	ID           uint64 `gorm:"primaryKey;autoIncrement;type:integer"`
-	Name         string `json:"name" binding:"required"`
-	Email        string `json:"email" binding:"required,email"`
-	Roles        []Role `json:"roles" gorm:"many2many:user_roles;"`
-	Password     string
-	RegisterDate time.Time
+	ID              uint64 `gorm:"primaryKey;autoIncrement;type:integer"`
+	Name            string `json:"name" binding:"required"`
+	Email           string `json:"email" binding:"required,email"`
+	Roles           []Role `json:"roles" gorm:"many2many:user_roles;"`
+	Password        string `json:"-"`
+	RegisterDate    time.Time
+	ServiceAccount  bool           `json:"service_account"`
+	Status          string         `json:"status" gorm:"default:active;index"`
+	StatusReason    string         `json:"status_reason,omitempty"`
+	StatusChangedAt *time.Time     `json:"status_changed_at,omitempty"`
+	LastLoginAt     *time.Time     `json:"last_login_at,omitempty"`
+	AvatarUpdatedAt *time.Time     `json:"avatar_updated_at,omitempty"`
+	ErasedAt        *time.Time     `json:"erased_at,omitempty"`
+	Version         uint64         `json:"version" gorm:"not null;default:1"`
+	DeletedAt       gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+	// APIKey é a chave key-auth recém-criada no Kong para a conta de serviço; não é gravada.
+	APIKey string `json:"-" gorm:"-"`
 }
 
 type UserWithoutPassword struct {
@@ -21,20 +34,36 @@ type UserWithoutPassword struct {
 	RegisterDate time.Time
 }
 
+// UserRole é o vínculo usuário-papel. Sem valid_from/valid_until o papel vale por tempo indeterminado.
 type UserRole struct {
-	ID     uint64 `gorm:"primaryKey;autoIncrement;type:integer"`
-	UserID int    `json:"user_id" binding:"required"`
-	RoleID int    `json:"role_id" binding:"required"`
+	ID         uint64     `gorm:"primaryKey;autoIncrement;type:integer"`
+	UserID     int        `json:"user_id" binding:"required"`
+	RoleID     int        `json:"role_id" binding:"required"`
+	ValidFrom  *time.Time `json:"valid_from"`
+	ValidUntil *time.Time `json:"valid_until" gorm:"index"`
+	GrantedBy  string     `json:"granted_by"`
+	Reason     string     `json:"reason"`
 }
 
-func (UserWithoutPassword) TableName() string {
-	return "users"
+// UserCreateRequest é o corpo de POST /users. Status, versão, papéis e datas ficam a cargo do
+// serviço e não podem ser enviados pelo cliente.
+type UserCreateRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+// PasswordChangeRequest troca a senha do próprio usuário, confirmando a atual.
+type PasswordChangeRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=8"`
 }
 
-//Evento para o Broker
+// UserRolesRequest é o conjunto completo de papéis desejado; uma lista vazia remove todos.
+type UserRolesRequest struct {
+	RoleIDs []uint64 `json:"role_ids" binding:"required"`
+}
 
-type UserCreatedEvent struct {
-	ID    uint64 `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
+func (UserWithoutPassword) TableName() string {
+	return "users"
 }
@@ -0,0 +1,23 @@
+package models
+
+// UserImportRow é uma linha do arquivo de importação (CSV ou JSON).
+type UserImportRow struct {
+	Line  int      `json:"line"`
+	Name  string   `json:"name"`
+	Email string   `json:"email"`
+	Roles []string `json:"roles"`
+}
+
+type UserImportError struct {
+	Line   int      `json:"line"`
+	Email  string   `json:"email,omitempty"`
+	Errors []string `json:"errors"`
+}
+
+type UserImportResult struct {
+	DryRun  bool              `json:"dry_run"`
+	Total   int               `json:"total"`
+	Valid   int               `json:"valid"`
+	Created int               `json:"created"`
+	Errors  []UserImportError `json:"errors,omitempty"`
+}
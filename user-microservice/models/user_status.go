@@ -0,0 +1,20 @@
+package models
+
+// Estados da conta. Só contas ativas conseguem fazer login.
+const (
+	UserStatusPending     = "pending"
+	UserStatusActive      = "active"
+	UserStatusSuspended   = "suspended"
+	UserStatusLocked      = "locked"
+	UserStatusDeactivated = "deactivated"
+)
+
+// IsActive considera ativas as contas sem status, gravadas antes da coluna existir.
+func (u *User) IsActive() bool {
+	return u.Status == "" || u.Status == UserStatusActive
+}
+
+type UserStatusRequest struct {
+	Status string `json:"status" binding:"required"`
+	Reason string `json:"reason" binding:"required"`
+}